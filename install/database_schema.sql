@@ -41,7 +41,11 @@ CREATE TABLE users (
     old_password_hash    TEXT         NULL, /* A SHA-256 hash */
     last_ip              TEXT         NOT NULL,
     datetime_created     TIMESTAMPTZ  NOT NULL  DEFAULT NOW(),
-    datetime_last_login  TIMESTAMPTZ  NOT NULL  DEFAULT NOW()
+    datetime_last_login  TIMESTAMPTZ  NOT NULL  DEFAULT NOW(),
+    /* Moderators can use chat commands to moderate the lobby and the tables */
+    moderator            BOOLEAN      NOT NULL  DEFAULT FALSE,
+    /* Whether the user has been sent the welcome message for new users */
+    welcomed             BOOLEAN      NOT NULL  DEFAULT FALSE
 );
 
 /* Any default settings must also be applied to the "userSettings.go" file */
@@ -51,6 +55,8 @@ CREATE TABLE user_settings (
     desktop_notification                 BOOLEAN   NOT NULL  DEFAULT FALSE,
     sound_move                           BOOLEAN   NOT NULL  DEFAULT TRUE,
     sound_timer                          BOOLEAN   NOT NULL  DEFAULT TRUE,
+    sound_mention                        BOOLEAN   NOT NULL  DEFAULT TRUE,
+    sound_pm                             BOOLEAN   NOT NULL  DEFAULT TRUE,
     keldon_mode                          BOOLEAN   NOT NULL  DEFAULT FALSE,
     colorblind_mode                      BOOLEAN   NOT NULL  DEFAULT FALSE,
     real_life_mode                       BOOLEAN   NOT NULL  DEFAULT FALSE,
@@ -60,6 +66,13 @@ CREATE TABLE user_settings (
     speedrun_preplay                     BOOLEAN   NOT NULL  DEFAULT FALSE,
     speedrun_mode                        BOOLEAN   NOT NULL  DEFAULT FALSE,
     hyphenated_conventions               BOOLEAN   NOT NULL  DEFAULT FALSE,
+    hide_last_seen                       BOOLEAN   NOT NULL  DEFAULT FALSE,
+    disable_read_receipts                BOOLEAN   NOT NULL  DEFAULT FALSE,
+    disable_offline_mentions             BOOLEAN   NOT NULL  DEFAULT FALSE,
+    appear_offline                       BOOLEAN   NOT NULL  DEFAULT FALSE,
+    hide_stats                           BOOLEAN   NOT NULL  DEFAULT FALSE,
+    chat_highlight_words                 TEXT      NOT NULL  DEFAULT '',
+    timezone                             TEXT      NOT NULL  DEFAULT '', /* Blank for UTC */
     volume                               SMALLINT  NOT NULL  DEFAULT 50,
     create_table_variant                 TEXT      NOT NULL  DEFAULT 'No Variant',
     create_table_timed                   BOOLEAN   NOT NULL  DEFAULT FALSE,
@@ -119,6 +132,15 @@ CREATE TABLE user_reverse_friends (
     PRIMARY KEY (user_id, friend_id)
 );
 
+DROP TABLE IF EXISTS user_ignores CASCADE;
+CREATE TABLE user_ignores (
+    user_id     INTEGER  NOT NULL,
+    ignored_id  INTEGER  NOT NULL,
+    FOREIGN KEY (user_id)    REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (ignored_id) REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, ignored_id)
+);
+
 DROP TABLE IF EXISTS games CASCADE;
 CREATE TABLE games (
     id                      SERIAL       PRIMARY KEY,
@@ -148,7 +170,15 @@ CREATE TABLE games (
     /* See the "endCondition" values in "constants.go" */
     end_condition           SMALLINT     NOT NULL,
     datetime_started        TIMESTAMPTZ  NOT NULL,
-    datetime_finished       TIMESTAMPTZ  NOT NULL
+    datetime_finished       TIMESTAMPTZ  NOT NULL,
+    /**
+     * The chat of the game is stored in the room of the table that it was played at
+     * (e.g. "table123"), but table IDs are reused after a restart, so the time that the table was
+     * created is needed to tell the chat of this game apart from the chat of other tables
+     * These are NULL for games from before they were recorded
+     */
+    table_id                INTEGER      NULL,
+    datetime_table_created  TIMESTAMPTZ  NULL
 );
 CREATE INDEX games_index_num_players ON games (num_players);
 CREATE INDEX games_index_variant_id  ON games (variant_id);
@@ -236,10 +266,16 @@ CREATE TABLE variant_stats (
 DROP TABLE IF EXISTS chat_log CASCADE;
 CREATE TABLE chat_log (
     id             SERIAL       PRIMARY KEY,
+    /**
+     * A UUID generated by the server when the message is created, so that clients can refer to
+     * specific messages (this is NULL for messages that were sent before message IDs existed)
+     */
+    message_id     TEXT         NULL      UNIQUE,
     user_id        INTEGER      NOT NULL, /* 0 is a Discord message */
     discord_name   TEXT         NULL,     /* Only used if it is a Discord message */
     message        TEXT         NOT NULL,
     room           TEXT         NOT NULL, /* Either "lobby" or "table####" */
+    reply_to       TEXT         NULL,     /* The "message_id" of the message being replied to */
     datetime_sent  TIMESTAMPTZ  NOT NULL  DEFAULT NOW()
     /**
      * There is no foreign key for "user_id" because it would not exist for Discord messages or
@@ -249,6 +285,21 @@ CREATE TABLE chat_log (
 CREATE INDEX chat_log_index_user_id       ON chat_log (user_id);
 CREATE INDEX chat_log_index_room          ON chat_log (room);
 CREATE INDEX chat_log_index_datetime_sent ON chat_log (datetime_sent);
+/**
+ * Messages are looked up by "COALESCE(message_id, id::TEXT)" (so that the messages from before
+ * message IDs existed can still be referred to), which can only use an index on the same expression
+ */
+CREATE INDEX chat_log_index_message_id    ON chat_log (COALESCE(message_id, id::TEXT));
+
+DROP TABLE IF EXISTS chat_log_reactions CASCADE;
+CREATE TABLE chat_log_reactions (
+    /* This corresponds to the "message_id" column (or the "id" column) of the "chat_log" table */
+    message_id  TEXT     NOT NULL,
+    user_id     INTEGER  NOT NULL,
+    emoji       TEXT     NOT NULL, /* The shortcode of the emoji, e.g. "joy" */
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (message_id, user_id, emoji)
+);
 
 DROP TABLE IF EXISTS chat_log_pm CASCADE;
 CREATE TABLE chat_log_pm (
@@ -257,11 +308,54 @@ CREATE TABLE chat_log_pm (
     message        TEXT         NOT NULL,
     recipient_id   INTEGER      NOT NULL,
     datetime_sent  TIMESTAMPTZ  NOT NULL  DEFAULT NOW(),
+    /* Messages to offline users are delivered when they next log in */
+    delivered      BOOLEAN      NOT NULL  DEFAULT TRUE,
+    /*
+     * Users who are mentioned while they are offline are notified when they next log in;
+     * the notification is stored as an undelivered message with the original chat message and
+     * the name of the room that it was sent in (this is NULL for normal private messages)
+     */
+    mention_room   TEXT         NULL      DEFAULT NULL,
     FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
 );
 CREATE INDEX chat_log_pm_index_user_id       ON chat_log_pm (user_id);
 CREATE INDEX chat_log_pm_index_recipient_id  ON chat_log_pm (recipient_id);
 CREATE INDEX chat_log_pm_index_datetime_sent ON chat_log_pm (datetime_sent);
+CREATE INDEX chat_log_pm_index_undelivered   ON chat_log_pm (recipient_id) WHERE NOT delivered;
+
+DROP TABLE IF EXISTS chat_reports CASCADE;
+CREATE TABLE chat_reports (
+    id                 SERIAL       PRIMARY KEY,
+    reporter_id        INTEGER      NOT NULL,
+    /* This corresponds to the "message_id" column (or the "id" column) of the "chat_log" table */
+    message_id         TEXT         NOT NULL,
+    room               TEXT         NOT NULL,
+    /*
+     * The author and the text of the message are copied at the time of the report so that they
+     * are kept even if the message is later edited or deleted
+     * (the user ID is 0 for messages from the server and from Discord)
+     */
+    user_id            INTEGER      NOT NULL,
+    name               TEXT         NOT NULL,
+    message            TEXT         NOT NULL,
+    reason             TEXT         NULL      DEFAULT NULL,
+    datetime_reported  TIMESTAMPTZ  NOT NULL  DEFAULT NOW(),
+    FOREIGN KEY (reporter_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX chat_reports_index_reporter_id ON chat_reports (reporter_id);
+CREATE INDEX chat_reports_index_message_id  ON chat_reports (message_id);
+
+/* Feedback from the "/feedback" command (e.g. bug reports), which the moderators can list */
+DROP TABLE IF EXISTS chat_feedback CASCADE;
+CREATE TABLE chat_feedback (
+    id             SERIAL       PRIMARY KEY,
+    user_id        INTEGER      NOT NULL,
+    room           TEXT         NOT NULL,
+    message        TEXT         NOT NULL,
+    datetime_sent  TIMESTAMPTZ  NOT NULL  DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX chat_feedback_index_datetime_sent ON chat_feedback (datetime_sent);
 
 DROP TABLE IF EXISTS banned_ips CASCADE;
 CREATE TABLE banned_ips (
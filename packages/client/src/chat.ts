@@ -5,11 +5,17 @@ import { emojis, emotes } from "@hanabi/data";
 import * as KeyCode from "keycode-js";
 import linkifyHtml from "linkify-html";
 import chatCommands from "./chatCommands";
-import { FADE_TIME, TYPED_HISTORY_MAX_LENGTH } from "./constants";
+import {
+  CHAT_MAX_SEND_ATTEMPTS,
+  CHAT_RESEND_TIME,
+  FADE_TIME,
+  TYPED_HISTORY_MAX_LENGTH,
+} from "./constants";
 import globals from "./globals";
 import Screen from "./lobby/types/Screen";
 import { parseIntSafe } from "./misc";
 import * as modals from "./modals";
+import * as sounds from "./sounds";
 import ChatMessage from "./types/ChatMessage";
 
 // Constants
@@ -46,6 +52,41 @@ const serverSideOnlyCommands = [
   "random",
   "uptime",
   "timeleft",
+  "me",
+  "edit",
+  "deletemsg",
+  "afk",
+  "motd",
+  "history",
+  "bridge",
+  "lockroom",
+  "feedbacklist",
+  "ignore",
+  "unignore",
+  "ignorelist",
+  "lastseen",
+  "stats",
+  "f",
+  "friends",
+  "friendlist",
+  "friendslist",
+  "search",
+  "report",
+  "feedback",
+  "translate",
+  "topic",
+  "poll",
+  "publicpoll",
+  "closepoll",
+  "vote",
+  "who",
+  "variantinfo",
+  "unread",
+  "everyone",
+  "highlight",
+  "unhighlight",
+  "highlights",
+  "tz",
 
   // Pre-game commands
   "s",
@@ -57,6 +98,7 @@ const serverSideOnlyCommands = [
   "startin",
   "kick",
   "impostor",
+  "invite",
 
   // Pre-game or game commands
   "missing",
@@ -69,9 +111,20 @@ const serverSideOnlyCommands = [
   "randomvariant",
   "random-variant",
 
+  // Pre-game, game, and replay commands
+  "pin",
+  "clear",
+  "slowmode",
+  "mutespectator",
+  "unmutespectator",
+  "gameevents",
+  "spectatornotices",
+  "roll",
+
   // Game commands
   "pause",
   "unpause",
+  "spectators",
 
   // Replay commands
   "suggest",
@@ -93,6 +146,15 @@ let tabCompleteWordListIndex: number | null = null;
 let tabCompleteWordList: string[] = [];
 let tabCompleteOriginalText = "";
 
+// The chat messages that we sent, but that the server has not echoed back yet
+interface PendingMessage {
+  room: string;
+  msg: string;
+  attempts: number;
+  timeout: ReturnType<typeof setTimeout> | null;
+}
+const pendingMessages = new Map<string, PendingMessage>(); // Indexed by nonce
+
 export function init(): void {
   $("#lobby-chat-input").on("input", input);
   $("#lobby-chat-input").on("keypress", keypress("lobby"));
@@ -296,7 +358,16 @@ function sendText(room: string, msgRaw: string) {
     command = command.substring(1); // Remove the forward slash
     command = command.toLowerCase();
 
-    if (!serverSideOnlyCommands.includes(command)) {
+    // Some commands have extra names that are configured by the server (in "chat_alias.go")
+    const aliasedCommand = globals.chatAliases[command];
+    if (aliasedCommand !== undefined) {
+      command = aliasedCommand;
+    }
+
+    if (
+      !serverSideOnlyCommands.includes(command) &&
+      !globals.chatMacros.includes(command)
+    ) {
       const chatCommandFunction = chatCommands.get(command);
       if (chatCommandFunction === undefined) {
         modals.showWarning(`The chat command of "${command}" is not valid.`);
@@ -308,10 +379,60 @@ function sendText(room: string, msgRaw: string) {
   }
 
   // This is not a command, so send a the chat message to the server
-  globals.conn!.send("chat", {
+  sendWithNonce(roomID, msg);
+}
+
+// Chat messages are sent with a random nonce that the server echoes back to us,
+// so that we can send them again if they do not arrive (e.g. if the connection dropped while we
+// were sending it)
+// The server ignores the copies that it has already received (in "chat_nonce.go")
+function sendWithNonce(room: string, msg: string) {
+  const random = Math.random().toString(36).substring(2);
+  const nonce = `${Date.now().toString(36)}${random}`;
+  pendingMessages.set(nonce, {
+    room,
     msg,
-    room: roomID,
+    attempts: 0,
+    timeout: null,
+  });
+  sendPending(nonce);
+}
+
+function sendPending(nonce: string) {
+  const pendingMessage = pendingMessages.get(nonce);
+  if (pendingMessage === undefined) {
+    return;
+  }
+
+  // Give up if the server never responds
+  // (the message is still in the typed history, so it can be sent again with the up arrow)
+  if (pendingMessage.attempts >= CHAT_MAX_SEND_ATTEMPTS) {
+    pendingMessages.delete(nonce);
+    return;
+  }
+  pendingMessage.attempts += 1;
+
+  globals.conn!.send("chat", {
+    msg: pendingMessage.msg,
+    room: pendingMessage.room,
+    nonce,
   });
+  pendingMessage.timeout = setTimeout(() => {
+    sendPending(nonce);
+  }, CHAT_RESEND_TIME);
+}
+
+// acknowledge is used when the server lets us know that it received one of our messages,
+// either by echoing back the nonce or with a "chatAck" message
+export function acknowledge(nonce: string): void {
+  const pendingMessage = pendingMessages.get(nonce);
+  if (pendingMessage === undefined) {
+    return;
+  }
+  if (pendingMessage.timeout !== null) {
+    clearTimeout(pendingMessage.timeout);
+  }
+  pendingMessages.delete(nonce);
 }
 
 function keydown(this: HTMLElement, event: JQuery.Event) {
@@ -506,6 +627,15 @@ function fixCustomEmotePriority(usersAndEmotesList: string[]) {
 }
 
 export function add(data: ChatMessage, fast: boolean): void {
+  // The server echoes back the nonce of the messages that we send
+  if (
+    data.nonce !== undefined &&
+    data.nonce !== "" &&
+    data.who === globals.username
+  ) {
+    acknowledge(data.nonce);
+  }
+
   // Find out which chat box we should add the new chat message to
   let chat: JQuery<HTMLElement> | undefined;
   if (data.room === "lobby") {
@@ -559,30 +689,75 @@ export function add(data: ChatMessage, fast: boolean): void {
   data.msg = fillDiscordEmotes(data.msg);
   data.msg = fillTwitchEmotes(data.msg);
 
+  // Multi-line messages are allowed (the server limits the number of lines)
+  data.msg = data.msg.replace(/\n/g, "<br />");
+
   // Typescript hasn't implemented the required DateTimeFormat option (hourCycle: h23)
   // So we format the hours manually
   const datetime = `${`0${new Date(data.datetime).getHours()}`.slice(
     -2,
   )}:${`0${new Date(data.datetime).getMinutes()}`.slice(-2)}`;
 
+  // Private messages that we sent can be marked as read later on (in the "markRead()" function)
+  const messageIDAttribute =
+    data.recipient !== "" &&
+    data.recipient !== globals.username &&
+    data.id !== undefined
+      ? ` data-message-id="${data.id}"`
+      : "";
+  // Every other message can have images added to it later on (in the "addImages()" function)
+  const chatIDAttribute =
+    data.id !== undefined && data.id !== ""
+      ? ` data-chat-id="${data.id}"`
+      : "";
+  // Moderators (and other roles) have their names styled differently
+  const roleAttribute =
+    data.role !== undefined && data.role !== ""
+      ? ` class="chat-role-${data.role}"`
+      : "";
+  // Warn about names that look like the name of a moderator
+  // (this is always determined by the server)
+  let lookalikeWarning = "";
+  if (data.lookalike !== undefined && data.lookalike !== "") {
+    const escapedLookalike = $("<span>").text(data.lookalike).html();
+    lookalikeWarning = ` <span class="chat-lookalike" title="This name looks like ${escapedLookalike}, but it is someone else">⚠</span>`;
+  }
   let line = `<span id="chat-line-${chatLineNum}" class="${
     fast ? "" : "hidden"
-  }">`;
+  }"${messageIDAttribute}${chatIDAttribute}>`;
   line += `[${datetime}]&nbsp; `;
   if (data.recipient !== "") {
     if (data.recipient === globals.username) {
-      line += `<span class="red">[PM from <strong>${data.who}</strong>]</span>&nbsp; `;
+      line += `<span class="red">[PM from <strong>${data.who}</strong>${lookalikeWarning}]</span>&nbsp; `;
     } else {
       line += `<span class="red">[PM to <strong>${data.recipient}</strong>]</span>&nbsp; `;
     }
   }
+  // Messages from the side channel for spectators are marked so that they are not confused with
+  // messages that the players can see
+  if (data.room.endsWith("-spectators")) {
+    line += '<span class="chat-spectators">[Spectators]</span>&nbsp; ';
+  }
+  // Review comments in a replay are marked so that they are not confused with the chat from the
+  // original game
+  if (data.review === true) {
+    line += '<span class="chat-review">[Review]</span>&nbsp; ';
+  }
+  // Messages from the "/everyone" command are highlighted
+  // (the server only sets this for the players, not the spectators)
+  const msg =
+    data.highlightAll === true
+      ? `<span class="chat-highlight-all">${data.msg}</span>`
+      : data.msg;
   if (data.server || (data.recipient !== undefined && data.recipient !== "")) {
-    line += data.msg;
+    line += msg;
+  } else if (data.action === true && data.who !== "") {
+    line += `<em>* <strong${roleAttribute}>${data.who}</strong>${lookalikeWarning} ${msg}</em>`;
   } else if (data.who !== "") {
-    line += `&lt;<strong>${data.who}</strong>&gt;&nbsp; `;
-    line += data.msg;
+    line += `&lt;<strong${roleAttribute}>${data.who}</strong>${lookalikeWarning}&gt;&nbsp; `;
+    line += msg;
   } else {
-    line += data.msg;
+    line += msg;
   }
   if (data.server && line.includes("[Server Notice]")) {
     line = line.replace(
@@ -622,8 +797,26 @@ export function add(data: ChatMessage, fast: boolean): void {
       chatInput.trigger("focus");
     });
   });
+  $(`#chat-line-${chatLineNum} a.chat-invite`).each((_, el) => {
+    const tableID = parseIntSafe($(el).attr("data-table-id") ?? "");
+    $(el).on("click", (event) => {
+      event.preventDefault();
+      acceptInvite(tableID);
+    });
+  });
   chatLineNum += 1;
 
+  // Being highlighted is treated the same as being mentioned
+  // (but only for new messages, not for the chat history)
+  if (
+    data.highlightAll === true &&
+    !fast &&
+    data.who !== globals.username &&
+    globals.settings.soundMention
+  ) {
+    sounds.play("tone");
+  }
+
   // Automatically scroll down
   if (autoScroll) {
     // From: https://stackoverflow.com/questions/270612/scroll-to-bottom-of-div?rq=1
@@ -675,6 +868,83 @@ export function addSelf(msg: string, room: string): void {
   );
 }
 
+// addInvite is used when someone invites us to their table with the "/invite" command
+export function addInvite(
+  tableID: number,
+  tableName: string,
+  who: string,
+): void {
+  const escapedName = $("<span>").text(tableName).html();
+  const escapedWho = $("<span>").text(who).html();
+  const msg = `${escapedWho} invited you to join the table of "${escapedName}". <a href="#" class="chat-invite" data-table-id="${tableID}">Click here to join it.</a>`;
+  addSelf(msg, "lobby");
+}
+
+function acceptInvite(tableID: number) {
+  if (Number.isNaN(tableID)) {
+    return;
+  }
+
+  if (globals.currentScreen !== Screen.Lobby) {
+    modals.showWarning(
+      "You must return to the lobby before you can accept an invitation.",
+    );
+    return;
+  }
+
+  // The server will send us a warning if the table has filled up or started in the meantime
+  globals.conn!.send("tableJoin", {
+    tableID,
+  });
+  // (we will get a "joined" response back from the server)
+}
+
+// addImages is used when the server tells us that a chat message contains links to images from
+// hosts that are safe to show (the server has already escaped the URLs)
+export function addImages(messageID: string, urls: string[]): void {
+  const lines = $(`span[data-chat-id="${messageID}"]`);
+  if (lines.find(".chat-image").length > 0) {
+    return;
+  }
+  for (const url of urls) {
+    lines.append(
+      `<br /><a href="${url}" target="_blank" rel="noopener noreferrer"><img src="${url}" class="chat-image" alt="" loading="lazy" referrerpolicy="no-referrer" /></a>`,
+    );
+  }
+}
+
+// addTurnLink is used when the server tells us that a chat message references a turn of the
+// current game or replay (with "!turn [turn]")
+export function addTurnLink(messageID: string, turn: number): void {
+  const lines = $(`span[data-chat-id="${messageID}"]`);
+  if (lines.find(`.chat-turn-link[data-turn="${turn}"]`).length > 0) {
+    return;
+  }
+  lines.append(
+    ` <button type="button" class="chat-turn-link" data-turn="${turn}">Go to turn ${turn}</button>`,
+  );
+  lines.find(`.chat-turn-link[data-turn="${turn}"]`).on("click", (event) => {
+    event.preventDefault();
+    if (globals.currentScreen === Screen.Game && globals.ui !== null) {
+      globals.ui.goToTurn(turn);
+    }
+  });
+}
+
+// markHighlighted is used when a chat message contains one of our highlight words
+export function markHighlighted(messageID: string): void {
+  $(`span[data-chat-id="${messageID}"]`).addClass("chat-highlighted");
+}
+
+// markRead is used when the recipient of a private message that we sent has read it
+export function markRead(messageID: string): void {
+  const lines = $(`span[data-message-id="${messageID}"]`);
+  if (lines.find(".chat-read").length > 0) {
+    return;
+  }
+  lines.append('&nbsp; <span class="chat-read" title="Read">✓</span>');
+}
+
 // Discord emotes are in the form of:
 // <:PogChamp:254683883033853954>
 function fillDiscordEmotes(message: string) {
@@ -34,21 +34,6 @@ function friend(_room: string, args: string[]) {
 chatCommands.set("friend", friend);
 chatCommands.set("addfriend", friend);
 
-// /friends
-function friends(room: string) {
-  let msg;
-  if (globals.friends.length === 0) {
-    msg = "Currently, you do not have any friends on your friends list.";
-  } else {
-    msg = `Current friends: ${globals.friends.join(", ")}`;
-  }
-  chat.addSelf(msg, room);
-}
-chatCommands.set("f", friends);
-chatCommands.set("friends", friends);
-chatCommands.set("friendlist", friends);
-chatCommands.set("friendslist", friends);
-
 // /pm [username] [msg]
 function pm(room: string, args: string[]) {
   // Validate that the format of the command is correct
@@ -93,11 +78,25 @@ function pm(room: string, args: string[]) {
 }
 chatCommands.set("pm", pm);
 chatCommands.set("w", pm);
-chatCommands.set("whisper", pm);
 chatCommands.set("msg", pm);
 chatCommands.set("tell", pm);
 chatCommands.set("t", pm);
 
+// /whisper [username] [msg]
+function whisper(room: string, args: string[]) {
+  // At a table, whispers are handled by the server so that they only go to someone at the table
+  if (room.startsWith("table")) {
+    globals.conn!.send("chat", {
+      msg: `/whisper ${args.join(" ")}`,
+      room,
+    });
+    return;
+  }
+
+  pm(room, args);
+}
+chatCommands.set("whisper", whisper);
+
 // /setleader [username]
 function setLeader(_room: string, args: string[]) {
   if (globals.tableID === -1) {
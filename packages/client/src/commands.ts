@@ -6,6 +6,7 @@ import globals from "./globals";
 import * as pregame from "./lobby/pregame";
 import Screen from "./lobby/types/Screen";
 import * as modals from "./modals";
+import * as sounds from "./sounds";
 import ChatMessage from "./types/ChatMessage";
 
 // Define a command handler map
@@ -48,6 +49,18 @@ commands.set("error", (data: ErrorData) => {
 commands.set("chat", (data: ChatMessage) => {
   chat.add(data, false); // The second argument is "fast"
 
+  // Let the sender of a private message know that we have read it
+  if (
+    data.recipient === globals.username &&
+    !data.server &&
+    data.id !== undefined &&
+    !globals.settings.disableReadReceipts
+  ) {
+    globals.conn!.send("chatRead", {
+      messageID: data.id,
+    });
+  }
+
   if (!data.room.startsWith("table")) {
     return;
   }
@@ -87,6 +100,88 @@ commands.set("chat", (data: ChatMessage) => {
   }
 });
 
+// Received by the client when we send a chat message again that the server had already received
+// (the server echoes back the nonce in the "chat" message the first time)
+interface ChatAckMessage {
+  nonce: string;
+  room: string;
+}
+commands.set("chatAck", (data: ChatAckMessage) => {
+  chat.acknowledge(data.nonce);
+});
+
+// Received by the client when the recipient of a private message that we sent has read it
+interface ChatReceiptMessage {
+  id: string;
+  recipient: string;
+  datetime: string;
+}
+commands.set("chatReceipt", (data: ChatReceiptMessage) => {
+  chat.markRead(data.id);
+});
+
+// Received by the client when someone mentions us in a chat message
+// (in either the lobby or a table)
+// (or says one of our highlight words)
+interface ChatMentionMessage {
+  id: string;
+  room: string;
+  who: string;
+  sound: boolean; // The server checks our "soundMention" setting
+}
+commands.set("chatMention", (data: ChatMentionMessage) => {
+  if (data.sound) {
+    sounds.play("tone");
+  }
+});
+
+// Received by the client when someone says one of our highlight words in a chat message
+// (from the "/highlight" command)
+interface ChatHighlightMessage {
+  id: string;
+  room: string;
+  who: string;
+  word: string;
+  sound: boolean; // The server checks our "soundMention" setting
+}
+commands.set("chatHighlight", (data: ChatHighlightMessage) => {
+  chat.markHighlighted(data.id);
+  if (data.sound) {
+    sounds.play("tone");
+  }
+});
+
+// Received by the client when someone invites us to their table
+interface ChatInviteMessage {
+  tableID: number;
+  tableName: string;
+  who: string;
+}
+commands.set("chatInvite", (data: ChatInviteMessage) => {
+  chat.addInvite(data.tableID, data.tableName, data.who);
+});
+
+// Received by the client when a chat message contains links to images from an allowed host
+interface ChatImageMessage {
+  id: string;
+  room: string;
+  urls: string[];
+}
+commands.set("chatImage", (data: ChatImageMessage) => {
+  chat.addImages(data.id, data.urls);
+});
+
+// Received by the client when a chat message at a game or a replay contains a "!turn [turn]" token
+// (the server has already validated that the turn is in the game)
+interface ChatTurnLinkMessage {
+  id: string;
+  room: string;
+  turn: number;
+}
+commands.set("chatTurnLink", (data: ChatTurnLinkMessage) => {
+  chat.addTurnLink(data.id, data.turn);
+});
+
 // Received by the client when someone either starts or stops typing
 interface ChatTypingMessage {
   name: string;
@@ -1,5 +1,10 @@
 // Time constants
 export const FADE_TIME = 350; // In milliseconds
 export const SHUTDOWN_TIMEOUT = 30; // In minutes
+// Chat messages that the server has not echoed back after this long are sent again
+// (this must be short enough that every attempt is made within the "ChatNonceExpiration" on the
+// server)
+export const CHAT_RESEND_TIME = 5000; // In milliseconds
+export const CHAT_MAX_SEND_ATTEMPTS = 3;
 
 export const TYPED_HISTORY_MAX_LENGTH = 250;
@@ -83,6 +83,18 @@ export default class HanabiUI {
     }
   }
 
+  // eslint-disable-next-line class-methods-use-this
+  goToTurn(turn: number): void {
+    // The UI is not drawn until the "init" message arrives from the server
+    if (globals.store === null) {
+      return;
+    }
+
+    // We minus one to account for the fact that turns are presented to the user starting from 1
+    // (a turn is an approximation for a segment)
+    replay.goToSegment(turn - 1, true);
+  }
+
   // eslint-disable-next-line class-methods-use-this
   focusLost(): void {
     setGlobalEmpathy(false);
@@ -26,6 +26,10 @@ export class Globals {
   /** Contains the settings for the "Settings" tooltip and the "Create Game" tooltip. */
   settings: Settings = new Settings();
   friends: string[] = [];
+  /** The names of the text macros defined by the server (e.g. "shrug"). */
+  chatMacros: string[] = [];
+  /** The extra names for chat commands defined by the server, indexed by the alias. */
+  chatAliases: Record<string, string> = {};
   shuttingDown = false;
   datetimeShutdownInit = new Date();
   maintenanceMode = false;
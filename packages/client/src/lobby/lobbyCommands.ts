@@ -54,6 +54,14 @@ commands.set("friends", (data: FriendsData) => {
   }
 });
 
+interface ChatMacrosData {
+  chatMacros: string[];
+}
+commands.set("chatMacros", (data: ChatMacrosData) => {
+  // The server has reloaded the text macros
+  globals.chatMacros = data.chatMacros;
+});
+
 commands.set("game", (data: Game) => {
   const previousPlayers = globals.game?.players;
   globals.game = data;
@@ -210,6 +218,26 @@ commands.set("tableProgress", (data: TableProgressData) => {
   }
 });
 
+// Received by the client when a new chat message arrives at a table that we are playing at
+// (but that we are not currently looking at)
+interface ChatUnreadData {
+  tableID: number;
+  unread: number;
+  chatPreview: string;
+}
+commands.set("chatUnread", (data: ChatUnreadData) => {
+  const table = globals.tableMap.get(data.tableID);
+  if (table === undefined) {
+    return;
+  }
+  table.unread = data.unread;
+  table.chatPreview = data.chatPreview;
+
+  if (globals.currentScreen === Screen.Lobby) {
+    tablesDraw();
+  }
+});
+
 interface TableStartData {
   tableID: number;
   replay: boolean;
@@ -289,6 +317,8 @@ commands.set("welcome", (data: WelcomeData) => {
   globals.muted = data.muted;
   globals.settings = data.settings;
   globals.friends = data.friends;
+  globals.chatMacros = data.chatMacros;
+  globals.chatAliases = data.chatAliases;
   globals.randomTableName = data.randomTableName;
   globals.shuttingDown = data.shuttingDown;
   globals.datetimeShutdownInit = new Date(data.datetimeShutdownInit);
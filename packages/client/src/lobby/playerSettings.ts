@@ -45,6 +45,22 @@ export function init(): void {
       console.error("Failed to play the test sound:", err);
     });
   });
+
+  $("#chatHighlightWords").change(function chatHighlightWordsChange(
+    this: HTMLElement,
+  ) {
+    const words = $(this).val();
+    if (typeof words !== "string") {
+      throw new Error(
+        'The value of the "chatHighlightWords" element is not a string.',
+      );
+    }
+    globals.settings.chatHighlightWords = words;
+    globals.conn!.send("setting", {
+      name: "chatHighlightWords",
+      setting: words,
+    });
+  });
 }
 
 export function setPlayerSettings(): void {
@@ -60,6 +76,16 @@ export function setPlayerSettings(): void {
       }
       $("#settings-volume-slider").val(value);
       $("#settings-volume-slider-value").html(`${value}%`);
+    } else if (setting === "chatHighlightWords") {
+      if (typeof value !== "string") {
+        throw new Error(
+          "The highlight words setting is not stored as a string.",
+        );
+      }
+      $("#chatHighlightWords").val(value);
+    } else if (setting === "timezone") {
+      // The timezone is set with the "/tz" command
+      continue;
     } else {
       const element = $(`#${setting}`);
       if (element === undefined) {
@@ -109,6 +109,12 @@ export default function tablesDraw(): void {
     if (table.passwordProtected && !table.running && !table.sharedReplay) {
       name = `<i class="fas fa-key fa-sm"></i> &nbsp; ${name}`;
     }
+    if (table.unread > 0) {
+      // The preview is already escaped by the server,
+      // but server messages can still contain quotes
+      const title = table.chatPreview.replace(/"/g, "&quot;");
+      name += ` &nbsp; <i class="fas fa-comment fa-sm" title="${title}"></i> ${table.unread}`;
+    }
     $("<td>").html(name).appendTo(row);
 
     // Column 2 - # of Players
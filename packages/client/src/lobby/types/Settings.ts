@@ -4,6 +4,8 @@ export default class Settings {
   desktopNotification = false;
   soundMove = true;
   soundTimer = true;
+  soundMention = true;
+  soundPM = true;
   keldonMode = false;
   colorblindMode = false;
   realLifeMode = false;
@@ -14,6 +16,13 @@ export default class Settings {
   speedrunPreplay = false;
   speedrunMode = false;
   hyphenatedConventions = false;
+  hideLastSeen = false;
+  disableReadReceipts = false;
+  disableOfflineMentions = false;
+  appearOffline = false;
+  hideStats = false;
+  chatHighlightWords = "";
+  timezone = "";
   createTableVariant = "No Variant";
   createTableTimed = false;
   createTableTimeBaseMinutes = 2;
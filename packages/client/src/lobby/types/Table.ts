@@ -16,4 +16,6 @@ export default interface Table {
   players: string[]; // e.g. ['Alice', 'Bob']
   spectators: string;
   maxPlayers: number;
+  unread: number; // The number of unread chat messages (if we are playing at this table)
+  chatPreview: string; // A plain-text snippet of the last unread chat message
 }
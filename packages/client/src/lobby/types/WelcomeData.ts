@@ -8,6 +8,8 @@ export default interface WelcomeData {
   firstTimeUser: boolean;
   settings: Settings;
   friends: string[];
+  chatMacros: string[];
+  chatAliases: Record<string, string>;
 
   playingAtTables: number[];
   disconSpectatingTable: number;
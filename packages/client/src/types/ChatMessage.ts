@@ -1,4 +1,5 @@
 export default interface ChatMessage {
+  id?: string;
   msg: string;
   who: string;
   discord: boolean;
@@ -6,4 +7,11 @@ export default interface ChatMessage {
   datetime: string; // Converted to a date in the "chat.add()" function
   room: string;
   recipient: string;
+  action?: boolean; // True for messages from the "/me" command
+  role?: string; // e.g. "moderator" (this is always set by the server)
+  highlightAll?: boolean; // True for messages from the "/everyone" command
+  lookalike?: string; // The protected name that the sender's name looks like, if any
+  review?: boolean; // True for the comments in a replay (which are kept for the next viewing)
+  nonce?: string; // From the sender (in the "sendWithNonce()" function in "chat.ts")
+  presence?: string; // "inGame", "spectating", or "idle" (omitted when it is not known)
 }
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// By default, the chat endpoint returns 50 messages at a time
+	APIChatDefaultLimit = 50
+	APIChatMaxLimit     = 1000
+)
+
+var (
+	// The token that external tools must send in order to read the chat history
+	// If blank, the chat endpoint is disabled
+	apiToken string
+
+	apiChatRoomRegExp = regexp.MustCompile(`^(?:lobby|table\d+)$`)
+)
+
+// Returns the chat history for a room (in the same format as the "chatList" WebSocket message)
+//   URL: /api/v1/chat/:room
+//
+//   Headers
+//   Authorization: Bearer [API_TOKEN]
+//
+//   Query parameters (the timestamps are in Unix milliseconds)
+//   before  int (only return messages sent before this time)
+//   after   int (only return messages sent after this time)
+//   limit   int (min 1, max 1000, default 50)
+//
+//   The newest messages matching the query are returned (from oldest to newest),
+//   so the next page can be retrieved by using the time of the first message as "before"
+func apiChat(c *gin.Context) {
+	// Local variables
+	w := c.Writer
+
+	if !apiCheckToken(c) {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	room := c.Param("room")
+	if !apiChatRoomRegExp.MatchString(room) {
+		http.Error(w, "That is not a valid room.", http.StatusBadRequest)
+		return
+	}
+
+	var before time.Time
+	if v, ok := apiGetTimestamp(c, "before"); !ok {
+		http.Error(w, "The \"before\" parameter must be a Unix timestamp in milliseconds.",
+			http.StatusBadRequest)
+		return
+	} else {
+		before = v
+	}
+
+	var after time.Time
+	if v, ok := apiGetTimestamp(c, "after"); !ok {
+		http.Error(w, "The \"after\" parameter must be a Unix timestamp in milliseconds.",
+			http.StatusBadRequest)
+		return
+	} else {
+		after = v
+	}
+
+	limit := APIChatDefaultLimit
+	if v, err := strconv.Atoi(c.Query("limit")); err == nil {
+		limit = between(v, 1, APIChatMaxLimit, APIChatDefaultLimit)
+	}
+
+	var msgs []*ChatMessage
+	if v, err := chatGetPastFromDatabase(room, limit, after, before); err != nil {
+		logger.Error("Failed to get the chat history for room \"" + room + "\": " + err.Error())
+		http.Error(
+			w,
+			http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError,
+		)
+		return
+	} else {
+		msgs = v
+	}
+
+	c.JSON(http.StatusOK, &ChatListMessage{
+		List:     msgs,
+		Unread:   0,
+		PinnedID: "",
+		Topic:    "",
+	})
+}
+
+// apiCheckToken validates the "Authorization" header against the "API_TOKEN" environment variable
+func apiCheckToken(c *gin.Context) bool {
+	if apiToken == "" {
+		return false
+	}
+
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(apiToken)) == 1
+}
+
+// apiGetTimestamp parses an optional query parameter in Unix milliseconds
+// It returns the zero time if the parameter was not specified
+func apiGetTimestamp(c *gin.Context, name string) (time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, true
+	}
+
+	milliseconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, milliseconds*int64(time.Millisecond)), true
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"net"
 	"net/http"
+	"os"
 	"strconv"
 
 	"github.com/Hanabi-Live/hanabi-live/logger"
@@ -183,6 +184,10 @@ func apiSetRoutes(httpRouter *gin.Engine) {
 
 	// List of games played by seed (full data)
 	httpRouter.GET(api+"/seed-full/:seed", apiFullDataSeed)
+
+	// Chat history for a room (requires an API token)
+	apiToken = os.Getenv("API_TOKEN")
+	httpRouter.GET(api+"/chat/:room", apiChat)
 }
 
 // Checks if a string contains a numeric value
@@ -4,18 +4,49 @@ package main
 
 import (
 	"context"
+	"html"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/Hanabi-Live/hanabi-live/logger"
+	uuid "github.com/satori/go.uuid"
 )
 
 const (
-	// When sending the in-game chat history,
+	// When sending the chat history,
 	// only send the last X messages to prevent clients from becoming overloaded
 	// (in case someone maliciously spams a lot of messages)
-	ChatLimit = 1000
+	// Table chat is sent every time that someone joins or reconnects to a game,
+	// so it has a lower limit by default
+	DefaultChatLimitLobby = 1000
+	DefaultChatLimitTable = 200
+
+	// Placeholders for Discord mentions that do not resolve to anything
+	// (e.g. from a user that has deleted their Discord account)
+	UnknownDiscordUser    = "unknown-user"
+	UnknownDiscordRole    = "unknown-role"
+	UnknownDiscordChannel = "unknown-channel"
+	// Placeholders for Discord mentions that have not been looked up yet
+	// (they are only shown until the lookup finishes, since messages are filled in when read)
+	PendingDiscordUser    = "discord-user"
+	PendingDiscordRole    = "discord-role"
+	PendingDiscordChannel = "discord-channel"
+
+	// The maximum number of tables that "chatServerSendAll()" will send to at the same time
+	ChatServerSendAllWorkers = 8
+
+	// When a message is a reply, only this many characters of the original message are quoted
+	ChatQuoteLength = 100
+
+	// The lobby table list shows this many characters of the last unread message at a table
+	ChatTablePreviewLength = 60
+
+	// Numbers above this are not linked, since they are unlikely to be real game IDs
+	ChatGameLinkMaxID = 100000000
 )
 
 var (
@@ -23,10 +54,51 @@ var (
 	mentionRegExp = regexp.MustCompile(`&lt;@!?(\d{17,19})&gt;`)
 	roleRegExp    = regexp.MustCompile(`&lt;@&amp;(\d{17,19})&gt;`)
 	channelRegExp = regexp.MustCompile(`&lt;#(\d{17,19})&gt;`)
-	spoilerRegExp = regexp.MustCompile(`(?:^| )\|\|(.+?)\|\|(?: |$)`)
+	// The surrounding whitespace is captured so that it can be put back
+	spoilerRegExp = regexp.MustCompile(`(?m)(^|\s)\|\|(.+?)\|\|(\s|$)`)
+	boldRegExp    = regexp.MustCompile(`\*\*([^\s*](?:[^*]*[^\s*])?)\*\*`)
+	// Single asterisks must not be next to a letter or a number so that e.g. "2*3*4" is left alone
+	italicRegExp = regexp.MustCompile(`(^|[^*\w])\*([^\s*](?:[^*]*[^\s*])?)\*($|[^*\w])`)
+	// Text inside of backticks, URLs, and links should never be formatted
+	// (lobby messages that were stored in the database by older versions of the server were
+	// already filled in, so their links must be left alone when they are filled in again)
+	noFormatRegExp = regexp.MustCompile("`[^`]*`|https?://\\S+|<a [^>]*>.*?</a>")
+	htmlTagRegExp  = regexp.MustCompile(`<[^>]*>`)
+	// e.g. "/replay/12345" or "game #12345"
+	gameLinkRegExp = regexp.MustCompile(`(?i)/replay/(\d+)|\bgame #?(\d+)`)
+	// e.g. "!seed Rainbow (6 Suits):abc123"
+	// (variant names can contain spaces, but never a colon)
+	seedReferenceRegExp = regexp.MustCompile(`(?i)(?:^|\s)!seed ([^:\n]+):([a-zA-Z0-9\-]+)`)
+	// Code blocks ("```text```") can span multiple lines, but code spans ("`text`") cannot
+	// Lobby messages that were stored in the database by older versions of the server were already
+	// converted when they were sent, so the converted HTML is matched too
+	// (users cannot type it, since their input is escaped)
+	codeRegExp = regexp.MustCompile("(?s)```(.+?)```|`([^`\n]+)`|" +
+		`<pre class="chat-code-block"><code>.*?</code></pre>|<code class="chat-code">.*?</code>`)
+
+	chatLimitLobby int
+	chatLimitTable int
 )
 
+func chatLimitInit() {
+	chatLimitLobby = getEnvInt("CHAT_HISTORY_LIMIT_LOBBY", DefaultChatLimitLobby)
+	chatLimitTable = getEnvInt("CHAT_HISTORY_LIMIT_TABLE", DefaultChatLimitTable)
+	if chatLimitLobby < 1 || chatLimitTable < 1 {
+		logger.Fatal("The \"CHAT_HISTORY_LIMIT_LOBBY\" and \"CHAT_HISTORY_LIMIT_TABLE\" " +
+			"environment variables must be at least 1.")
+	}
+}
+
+// chatGetLimit returns the maximum number of past messages that should be sent for a room
+func chatGetLimit(room string) int {
+	if room == "lobby" {
+		return chatLimitLobby
+	}
+	return chatLimitTable
+}
+
 type ChatMessage struct {
+	ID        string    `json:"id"`
 	Msg       string    `json:"msg"`
 	Who       string    `json:"who"`
 	Discord   bool      `json:"discord"`
@@ -34,6 +106,106 @@ type ChatMessage struct {
 	Datetime  time.Time `json:"datetime"`
 	Room      string    `json:"room"`
 	Recipient string    `json:"recipient"`
+	// Indexed by emoji shortcode, the values are the number of users who reacted with that emoji
+	Reactions map[string]int `json:"reactions"`
+	// The ID of the message that this is a reply to (blank if it is not a reply)
+	ReplyTo string `json:"replyTo"`
+	// A snippet of the message that this is a reply to, so that clients can render a quote
+	// (nil if it is not a reply or if the original message was deleted)
+	Quote *ChatQuote `json:"quote"`
+	// Whether this is an action message from the "/me" command (e.g. "* Alice waves")
+	// If so, the "/me " prefix is removed from the message
+	Action bool `json:"action"`
+	// The role of the sender (e.g. "moderator"), so that clients can style their name
+	// This is always determined by the server; it is blank for everyone else
+	Role string `json:"role"`
+	// Whether this is a message from the "/everyone" command,
+	// which clients highlight (and play a sound for) to get everyone's attention
+	HighlightAll bool `json:"highlightAll"`
+	// The name of the moderator (or other protected name) that the sender's name looks like,
+	// so that clients can warn about a possible impersonation (in "chat_lookalike.go")
+	Lookalike string `json:"lookalike"`
+	// The nonce that the sender attached to the message, if any (in "chat_nonce.go")
+	Nonce string `json:"nonce"`
+	// Whether this is a review comment that was sent in a replay (in "chat_review.go"),
+	// so that clients can distinguish it from the chat of the original game
+	Review bool `json:"review"`
+	// Whether the sender is in a game, spectating, or idle (in "chat_presence.go")
+	// This is omitted when it is not known
+	Presence string `json:"presence,omitempty"`
+}
+
+type ChatQuote struct {
+	Who string `json:"who"`
+	Msg string `json:"msg"`
+}
+
+// newChatQuote shortens the message being replied to and removes any formatting from it
+// (the message should already be filled in, so that quotes from memory and from the database match)
+func newChatQuote(who string, msg string) *ChatQuote {
+	if who == "__server" || who == "" {
+		who = WebsiteName
+	}
+
+	return &ChatQuote{
+		Who: who,
+		Msg: chatSnippet(msg, ChatQuoteLength),
+	}
+}
+
+// chatSnippet converts a message that went through the "chatFillAll()" function back to plain
+// text and shortens it to a maximum amount of runes (instead of bytes, so that a multibyte
+// character such as an emoji is never split in half)
+// The text of spoilers is hidden, since snippets are shown outside of the chat
+// The message is still escaped, so it can be rendered as HTML
+func chatSnippet(msg string, maxRunes int) string {
+	msg = chatRemoveSpoilers(msg)
+	msg = htmlTagRegExp.ReplaceAllString(msg, "")
+	if utf8.RuneCountInString(msg) > maxRunes {
+		msg = truncateRunes(msg, maxRunes)
+
+		// Do not leave half of an HTML entity at the end (e.g. "&am")
+		if i := strings.LastIndex(msg, "&"); i != -1 && !strings.Contains(msg[i:], ";") {
+			msg = msg[:i]
+		}
+		msg += "..."
+	}
+
+	return msg
+}
+
+// chatRemoveSpoilers replaces the spans that were created by the "chatReplaceSpoilers()" function
+// with a placeholder
+// Spoilers can contain other spans (e.g. mentions), so we have to find the matching closing tag
+func chatRemoveSpoilers(msg string) string {
+	const openingTag = `<span class="spoiler">`
+	for {
+		start := strings.Index(msg, openingTag)
+		if start == -1 {
+			return msg
+		}
+
+		depth := 0
+		end := len(msg)
+		for i := start; i < len(msg); i++ {
+			if strings.HasPrefix(msg[i:], "<span") {
+				depth++
+			} else if strings.HasPrefix(msg[i:], "</span>") {
+				depth--
+				if depth == 0 {
+					end = i + len("</span>")
+					break
+				}
+			}
+		}
+		msg = msg[:start] + "[spoiler]" + msg[end:]
+	}
+}
+
+// newChatMessageID returns a new unique identifier for a chat message
+// (so that clients can correlate the chat history with live messages, edits, and so forth)
+func newChatMessageID() string {
+	return uuid.NewV4().String()
 }
 
 // chatServerSend is a helper function to send a message from the server
@@ -52,51 +224,171 @@ func chatServerSend(ctx context.Context, msg string, room string, noTablesLock b
 // chatServerSendAll is a helper function to broadcast a message to everyone on the server,
 // whether they are in the lobby or in the middle of a game
 // It is assumed that the tables mutex is locked when calling this function
+// (every caller must acquire it beforehand, since the list of tables is read without locking it
+// and the messages are sent without trying to lock it again)
 func chatServerSendAll(ctx context.Context, msg string) {
-	chatServerSend(ctx, msg, "lobby", false)
+	chatServerSend(ctx, msg, "lobby", true)
+	chatServerSendTables(ctx, msg, tables.GetList(false))
+}
 
-	tableList := tables.GetList(true)
-	roomNames := make([]string, 0)
+// chatServerSendGames is like the "chatServerSendAll()" function,
+// but it skips the lobby and the shared replays so that only the people at the tables that are
+// playing (or are about to play) are notified
+// It is assumed that the tables mutex is locked when calling this function
+func chatServerSendGames(ctx context.Context, msg string) {
+	// Since the tables mutex is locked, a table cannot be converted to a shared replay underneath us
+	gameList := make([]*Table, 0)
+	for _, t := range tables.GetList(false) {
+		if !t.Replay {
+			gameList = append(gameList, t)
+		}
+	}
+	chatServerSendTables(ctx, msg, gameList)
+}
+
+// It is assumed that the tables mutex is locked when calling this function
+// (in the same way as the "chatServerSendAll()" function)
+// Each table is locked while the message is sent to it, so the caller must not hold any table locks
+func chatServerSendTables(ctx context.Context, msg string, tableList []*Table) {
+	// Since the tables mutex is locked, the list of tables cannot change underneath us
+	tableChan := make(chan *Table, len(tableList))
 	for _, t := range tableList {
-		t.Lock(ctx)
-		roomNames = append(roomNames, t.GetRoomName())
-		t.Unlock(ctx)
+		tableChan <- t
 	}
+	close(tableChan)
 
-	for _, roomName := range roomNames {
-		chatServerSend(ctx, msg, roomName, false)
+	// Send the message to the tables concurrently so that a server with a lot of tables does not
+	// stall
+	// Each worker locks the table that it is sending to, since adding a message to the chat of a
+	// table would otherwise race with the goroutines of the table itself
+	numWorkers := ChatServerSendAllWorkers
+	if len(tableList) < numWorkers {
+		numWorkers = len(tableList)
 	}
+	var wg sync.WaitGroup
+	wg.Add(numWorkers)
+	for i := 0; i < numWorkers; i++ {
+		go func() {
+			defer wg.Done()
+			for t := range tableChan {
+				t.Lock(ctx)
+				if !t.Deleted {
+					chatServerSend(ctx, msg, t.GetRoomName(), true)
+				}
+				t.Unlock(ctx)
+			}
+		}()
+	}
+
+	// The caller is holding the tables mutex, so we must not return until every message is sent
+	wg.Wait()
 }
 
 // chatServerSendPM is for sending non-public messages to specific users
-func chatServerSendPM(s *Session, msg string, room string) {
+// The key is rendered in the language of the user with the provided parameters
+// (see "chat_localization.go")
+func chatServerSendPM(s *Session, key string, room string, params ...interface{}) {
 	s.Emit("chat", &ChatMessage{
-		Msg:       msg,
+		ID:        newChatMessageID(),
+		Msg:       chatLocalize(s.Language, key, params...),
 		Who:       WebsiteName,
 		Discord:   false,
 		Server:    true,
 		Datetime:  time.Now(),
 		Room:      room,
 		Recipient: s.Username,
+		Reactions: make(map[string]int),
+		ReplyTo:   "",
+		Quote:     nil,
+		Action:    false,
 	})
 }
 
 func chatFillAll(msg string) string {
-	if discord == nil {
-		return msg
+	return chatReplaceCodeBlocks(msg, chatFillText)
+}
+
+// chatReplaceCodeBlocks converts code blocks and code spans to HTML and applies the "fill" function
+// to the rest of the message, so that mentions, spoilers and formatting are never processed inside
+// of the code
+// The message has already been escaped, so the code is not escaped again
+func chatReplaceCodeBlocks(msg string, fill func(string) string) string {
+	var sb strings.Builder
+	start := 0
+	for _, match := range codeRegExp.FindAllStringSubmatchIndex(msg, -1) {
+		sb.WriteString(fill(msg[start:match[0]]))
+		start = match[1]
+
+		if match[2] != -1 {
+			// Like in Discord, the line breaks directly after the opening backticks and directly
+			// before the closing backticks are not part of the code
+			code := msg[match[2]:match[3]]
+			code = strings.TrimPrefix(code, "\n")
+			code = strings.TrimSuffix(code, "\n")
+			sb.WriteString(`<pre class="chat-code-block"><code>`)
+			sb.WriteString(chatFilterProfanity(code))
+			sb.WriteString("</code></pre>")
+		} else if match[4] != -1 {
+			sb.WriteString(`<code class="chat-code">`)
+			sb.WriteString(chatFilterProfanity(msg[match[4]:match[5]]))
+			sb.WriteString("</code>")
+		} else {
+			// This was already converted
+			sb.WriteString(msg[match[0]:match[1]])
+		}
+	}
+	sb.WriteString(fill(msg[start:]))
+	return sb.String()
+}
+
+// chatFillText applies the transformations to the parts of a message that are not code
+func chatFillText(msg string) string {
+	if discord != nil {
+		// Convert Discord mentions to users, channels and roles
+		msg = chatFillMentions(msg)
+		msg = chatFillRoles(msg)
+		msg = chatFillChannels(msg)
+
+		// Convert other Discord tags
+		msg = chatReplaceSpoilers(msg)
 	}
 
-	// Convert Discord mentions to users, channels and roles
-	msg = chatFillMentions(msg)
-	msg = chatFillRoles(msg)
-	msg = chatFillChannels(msg)
+	// Censor profanity (if the filter is enabled)
+	// (this must be before the formatting so that the censored words are not mistaken for italics)
+	msg = chatFilterProfanity(msg)
+
+	// Convert Markdown-style formatting
+	// (bold must be first so that the double asterisks are not mistaken for italics)
+	msg = chatReplaceBold(msg)
+	msg = chatReplaceItalic(msg)
 
-	// Convert other Discord tags
-	msg = chatReplaceSpoilers(msg)
+	// Link to the replays of any games that are mentioned
+	msg = chatReplaceGameLinks(msg)
 
 	return msg
 }
 
+// chatPrefetchDiscordMentions waits a short time for the Discord mentions in a new message to be
+// looked up, so that the message is not sent with placeholders the first time that someone is
+// mentioned (in "discord_cache.go")
+func chatPrefetchDiscordMentions(msg string) {
+	if discord == nil {
+		return
+	}
+
+	deadline := time.Now().Add(DiscordCachePrefetchTimeout)
+	prefetch := func(cache *DiscordCache, regExp *regexp.Regexp) {
+		discordIDs := make([]string, 0)
+		for _, match := range regExp.FindAllStringSubmatch(msg, -1) {
+			discordIDs = append(discordIDs, match[1])
+		}
+		cache.Prefetch(discordIDs, deadline)
+	}
+	prefetch(discordNicknames, mentionRegExp)
+	prefetch(discordRoles, roleRegExp)
+	prefetch(discordChannels, channelRegExp)
+}
+
 func chatFillMentions(msg string) string {
 	if discord == nil {
 		return msg
@@ -108,17 +400,23 @@ func chatFillMentions(msg string) string {
 	// if a nickname is set for that person
 	// We want to convert this to the username,
 	// so that the lobby displays messages in a manner similar to the Discord client
-	for {
-		match := mentionRegExp.FindStringSubmatch(msg)
-		if match == nil || len(match) <= 1 {
-			break
+	// (this is done in a single pass so that a nickname that looks like a mention cannot cause
+	// the replacement to go on forever)
+	usernames := make(map[string]string) // Indexed by Discord ID
+	return mentionRegExp.ReplaceAllStringFunc(msg, func(mention string) string {
+		discordID := mentionRegExp.FindStringSubmatch(mention)[1]
+		if username, ok := usernames[discordID]; ok {
+			return "@" + username
 		}
-		discordID := match[1]
-		username := discordGetNickname(discordID)
-		msg = strings.ReplaceAll(msg, "&lt;@"+discordID+"&gt;", "@"+username)
-		msg = strings.ReplaceAll(msg, "&lt;@!"+discordID+"&gt;", "@"+username)
-	}
-	return msg
+		username, result := discordNicknames.Get(discordID)
+		if result == DiscordCacheNotFound {
+			username = UnknownDiscordUser
+		} else if result == DiscordCacheMiss {
+			username = PendingDiscordUser
+		}
+		usernames[discordID] = username
+		return "@" + username
+	})
 }
 
 func chatFillRoles(msg string) string {
@@ -128,16 +426,21 @@ func chatFillRoles(msg string) string {
 
 	// Discord roles are in the form of "<@&12345678901234567>"
 	// By the time the message gets here, it will be sanitized to "&lt;@&amp;12345678901234567&gt;"
-	for {
-		match := roleRegExp.FindStringSubmatch(msg)
-		if match == nil || len(match) <= 1 {
-			break
+	roles := make(map[string]string) // Indexed by Discord ID
+	return roleRegExp.ReplaceAllStringFunc(msg, func(mention string) string {
+		discordID := roleRegExp.FindStringSubmatch(mention)[1]
+		if role, ok := roles[discordID]; ok {
+			return "@" + role
 		}
-		discordID := match[1]
-		role := discordGetRole(discordID)
-		msg = strings.ReplaceAll(msg, "&lt;@&amp;"+discordID+"&gt;", "@"+role)
-	}
-	return msg
+		role, result := discordRoles.Get(discordID)
+		if result == DiscordCacheNotFound {
+			role = UnknownDiscordRole
+		} else if result == DiscordCacheMiss {
+			role = PendingDiscordRole
+		}
+		roles[discordID] = role
+		return "@" + role
+	})
 }
 
 func chatFillChannels(msg string) string {
@@ -147,16 +450,21 @@ func chatFillChannels(msg string) string {
 
 	// Discord channels are in the form of "<#380813128176500736>"
 	// By the time the message gets here, it will be sanitized to "&lt;#380813128176500736&gt;"
-	for {
-		match := channelRegExp.FindStringSubmatch(msg)
-		if match == nil || len(match) <= 1 {
-			break
+	channels := make(map[string]string) // Indexed by Discord ID
+	return channelRegExp.ReplaceAllStringFunc(msg, func(mention string) string {
+		discordID := channelRegExp.FindStringSubmatch(mention)[1]
+		if channel, ok := channels[discordID]; ok {
+			return "#" + channel
 		}
-		discordID := match[1]
-		channel := discordGetChannel(discordID)
-		msg = strings.ReplaceAll(msg, "&lt;#"+discordID+"&gt;", "#"+channel)
-	}
-	return msg
+		channel, result := discordChannels.Get(discordID)
+		if result == DiscordCacheNotFound {
+			channel = UnknownDiscordChannel
+		} else if result == DiscordCacheMiss {
+			channel = PendingDiscordChannel
+		}
+		channels[discordID] = channel
+		return "#" + channel
+	})
 }
 
 func chatReplaceSpoilers(msg string) string {
@@ -164,35 +472,204 @@ func chatReplaceSpoilers(msg string) string {
 		return msg
 	}
 
+	// Back-to-back spoilers share the whitespace between them, so the second one cannot match until
+	// the first one has been replaced
 	for {
-		match := spoilerRegExp.FindAllStringSubmatch(msg, -1)
-		if len(match) == 0 {
-			break
+		newMsg := spoilerRegExp.ReplaceAllString(msg, `$1<span class="spoiler">$2</span>$3`)
+		if newMsg == msg {
+			return msg
+		}
+		msg = newMsg
+	}
+}
+
+// chatReplaceBold converts "**text**" to bold text
+func chatReplaceBold(msg string) string {
+	return chatReplaceOutsideNoFormat(msg, func(text string) string {
+		return boldRegExp.ReplaceAllString(text, "<strong>${1}</strong>")
+	})
+}
+
+// chatReplaceItalic converts "*text*" to italic text
+func chatReplaceItalic(msg string) string {
+	return chatReplaceOutsideNoFormat(msg, func(text string) string {
+		// The characters surrounding a match are consumed by the regular expression,
+		// so two italic words separated by a single space require a second pass
+		for {
+			newText := italicRegExp.ReplaceAllString(text, "${1}<em>${2}</em>${3}")
+			if newText == text {
+				return text
+			}
+			text = newText
+		}
+	})
+}
+
+// chatReplaceGameLinks converts "/replay/12345" and "game #12345" to links to the replay
+// The message is already escaped, so the only HTML in the link is the link itself
+func chatReplaceGameLinks(msg string) string {
+	return chatReplaceOutsideNoFormat(msg, func(text string) string {
+		var sb strings.Builder
+		start := 0
+		for _, match := range gameLinkRegExp.FindAllStringSubmatchIndex(text, -1) {
+			// Ignore matches that are part of a bigger word or path
+			// (e.g. "/shared-replay/12345", "/replay/12345/7", or "endgame 5")
+			if match[0] > 0 && chatGameLinkIsPartOfWord(text[match[0]-1]) ||
+				match[1] < len(text) && chatGameLinkIsPartOfWord(text[match[1]]) {
+
+				continue
+			}
+
+			databaseIDString := ""
+			if match[2] != -1 {
+				databaseIDString = text[match[2]:match[3]]
+			} else {
+				databaseIDString = text[match[4]:match[5]]
+			}
+
+			// Leave numbers that cannot be a game ID alone
+			databaseID, err := strconv.Atoi(databaseIDString)
+			if err != nil || databaseID < 1 || databaseID > ChatGameLinkMaxID {
+				continue
+			}
+
+			sb.WriteString(text[start:match[0]])
+			sb.WriteString("<a href=\"/replay/" + strconv.Itoa(databaseID) + "\">")
+			sb.WriteString(text[match[0]:match[1]])
+			sb.WriteString("</a>")
+			start = match[1]
 		}
+		sb.WriteString(text[start:])
+		return sb.String()
+	})
+}
+
+func chatGameLinkIsPartOfWord(c byte) bool {
+	return c == '/' || c == '-' || c == '_' ||
+		(c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
 
-		for _, m := range match {
-			text := m[1]
-			msg = strings.ReplaceAll(msg, "||"+text+"||", "<span class=\"spoiler\">"+text+"</span>")
+type ChatSeedReference struct {
+	Variant *Variant
+	Seed    string
+}
+
+// chatParseSeedReferences finds the "!seed [variant]:[seed]" tokens in a message
+// Tokens with a variant that does not exist are ignored (and are left in the message as normal text)
+func chatParseSeedReferences(msg string) []ChatSeedReference {
+	references := make([]ChatSeedReference, 0)
+	for _, match := range seedReferenceRegExp.FindAllStringSubmatch(msg, -1) {
+		// The message is already escaped (e.g. "Black & Rainbow" is now "Black &amp; Rainbow")
+		variantName := strings.TrimSpace(html.UnescapeString(match[1]))
+		variant, ok := chatGetVariantByName(variantName)
+		if !ok {
+			continue
 		}
+		references = append(references, ChatSeedReference{
+			Variant: variant,
+			Seed:    match[2],
+		})
 	}
-	return msg
+
+	return references
+}
+
+// chatGetVariantByName is case-insensitive, since people will not always type the exact name
+func chatGetVariantByName(variantName string) (*Variant, bool) {
+	if variant, ok := variants[variantName]; ok {
+		return variant, true
+	}
+	for _, name := range variantNames {
+		if strings.EqualFold(name, variantName) {
+			return variants[name], true
+		}
+	}
+
+	return nil, false
+}
+
+// chatReplaceOutsideNoFormat applies the replacement function only to the parts of the message
+// that are not inside of backticks or URLs
+func chatReplaceOutsideNoFormat(msg string, replace func(string) string) string {
+	var sb strings.Builder
+	start := 0
+	for _, match := range noFormatRegExp.FindAllStringIndex(msg, -1) {
+		sb.WriteString(replace(msg[start:match[0]]))
+		sb.WriteString(msg[match[0]:match[1]])
+		start = match[1]
+	}
+	sb.WriteString(replace(msg[start:]))
+	return sb.String()
 }
 
 type ChatListMessage struct {
 	List   []*ChatMessage `json:"list"`
 	Unread int            `json:"unread"`
+	// The ID of the message that is pinned to the top of the chat, if any
+	// (the pinned message will also be the first element of the list)
+	PinnedID string `json:"pinnedID"`
+	// The topic of the room from the "/topic" command, if any (in "chat_topic.go")
+	Topic string `json:"topic"`
 }
 
-func chatSendPastFromDatabase(s *Session, room string, count int) bool {
-	var rawMsgs []DBChatMessage
-	if v, err := models.ChatLog.Get(room, count); err != nil {
+// chatSendPastFromDatabase sends the last "count" messages from a room
+// If "since" is not the zero time, only the messages sent after that time are sent
+// (so that a reconnecting client only has to download the messages that it missed)
+func chatSendPastFromDatabase(s *Session, room string, count int, since time.Time) bool {
+	if limit := chatGetLimit(room); count > limit {
+		count = limit
+	}
+
+	var msgs []*ChatMessage
+	if v, err := chatGetPastFromDatabase(room, count, since, time.Time{}); err != nil {
 		logger.Error("Failed to get the lobby chat history for user \"" + s.Username + "\": " + err.Error())
 		s.Error(DefaultErrorMsg)
 		return false
+	} else {
+		msgs = v
+	}
+
+	topic := ""
+	if room == "lobby" {
+		topic = chatFillAll(lobbyGetTopic())
+	}
+
+	s.Emit("chatList", &ChatListMessage{
+		List:     msgs,
+		Unread:   0,
+		PinnedID: "",
+		Topic:    topic,
+	})
+
+	return true
+}
+
+// chatGetPastFromDatabase gets the last "count" messages from a room, from oldest to newest
+// If "after" or "before" are not the zero time, only the messages in between are returned
+func chatGetPastFromDatabase(
+	room string,
+	count int,
+	after time.Time,
+	before time.Time,
+) ([]*ChatMessage, error) {
+	var rawMsgs []DBChatMessage
+	if v, err := models.ChatLog.Get(room, count, after, before); err != nil {
+		return nil, err
 	} else {
 		rawMsgs = v
 	}
 
+	messageIDs := make([]string, 0, len(rawMsgs))
+	for _, rawMsg := range rawMsgs {
+		messageIDs = append(messageIDs, rawMsg.MessageID)
+	}
+	var reactionsMap map[string]map[string]int
+	if v, err := models.ChatLogReactions.GetCounts(messageIDs); err != nil {
+		return nil, err
+	} else {
+		reactionsMap = v
+	}
+
 	msgs := make([]*ChatMessage, 0)
 	for i := len(rawMsgs) - 1; i >= 0; i-- {
 		// The chat messages were queried from the database in order from newest to newest
@@ -210,7 +687,18 @@ func chatSendPastFromDatabase(s *Session, room string, count int) bool {
 			rawMsg.Name = rawMsg.DiscordName.String
 		}
 		rawMsg.Message = chatFillAll(rawMsg.Message)
+		var action bool
+		rawMsg.Message, action = chatParseAction(rawMsg.Message)
+		reactions, ok := reactionsMap[rawMsg.MessageID]
+		if !ok {
+			reactions = make(map[string]int)
+		}
+		var quote *ChatQuote
+		if rawMsg.QuoteMessage.Valid {
+			quote = newChatQuote(rawMsg.QuoteName.String, chatFillAll(rawMsg.QuoteMessage.String))
+		}
 		msg := &ChatMessage{
+			ID:        rawMsg.MessageID,
 			Msg:       rawMsg.Message,
 			Who:       rawMsg.Name,
 			Discord:   discord,
@@ -218,39 +706,192 @@ func chatSendPastFromDatabase(s *Session, room string, count int) bool {
 			Datetime:  rawMsg.Datetime,
 			Room:      room,
 			Recipient: "",
+			Reactions: reactions,
+			ReplyTo:   rawMsg.ReplyTo.String,
+			Quote:     quote,
+			Action:    action,
+			Role:      chatGetRoleFromDatabase(rawMsg, server, discord),
+			Lookalike: chatGetMessageLookalike(rawMsg.Name, server, discord),
 		}
 		msgs = append(msgs, msg)
 	}
-	s.Emit("chatList", &ChatListMessage{
-		List:   msgs,
-		Unread: 0,
-	})
 
-	return true
+	return msgs, nil
+}
+
+// chatSendPastSince sends only the messages from a room that were sent after the client's
+// last-seen time, which avoids re-sending the entire chat history when a client reconnects
+// Brand new clients will not have a last-seen time, so they get the last 50 messages instead
+func chatSendPastSince(s *Session, room string, since time.Time) bool {
+	if since.IsZero() {
+		return chatSendPastFromDatabase(s, room, 50, since)
+	}
+
+	// Even if they were gone for a long time, they should not get more than the limit
+	return chatSendPastFromDatabase(s, room, chatGetLimit(room), since)
 }
 
 func chatSendPastFromTable(s *Session, t *Table) {
+	// If the server restarted and the in-memory chat history was lost,
+	// then we can restore it from the database
+	if len(t.Chat) == 0 && !t.ChatRestored && !t.Replay {
+		chatRestoreFromDatabase(t)
+	}
+
+	// The review comments from the previous times that this replay was opened are in the database
+	if t.Replay && !t.ReviewChatRestored {
+		chatReviewRestoreFromDatabase(t)
+	}
+
+	// The history is still sent below, but it should not count as unread for a newcomer
+	// (this must be after the chat is restored from the database)
+	t.InitChatRead(s.UserID)
+
 	chatList := make([]*ChatMessage, 0)
+
+	// The pinned message (if any) goes at the top of the chat
+	for _, gcm := range t.Chat {
+		if t.PinnedMessageID != "" && gcm.ID == t.PinnedMessageID {
+			chatList = append(chatList, gcm.ToChatMessage(t))
+			break
+		}
+	}
+
 	i := 0
-	if len(t.Chat) > ChatLimit {
-		i = len(t.Chat) - ChatLimit
+	if len(t.Chat) > chatLimitTable {
+		i = len(t.Chat) - chatLimitTable
 	}
+	numSent := len(t.Chat) - i
 	for ; i < len(t.Chat); i++ {
-		// We have to convert the *GameChatMessage to a *ChatMessage
-		gcm := t.Chat[i]
-		cm := &ChatMessage{
-			Msg:       gcm.Msg,
-			Who:       gcm.Username,
-			Discord:   false,
-			Server:    gcm.Server,
-			Datetime:  gcm.Datetime,
-			Room:      t.GetRoomName(),
-			Recipient: "",
-		}
-		chatList = append(chatList, cm)
+		chatList = append(chatList, t.Chat[i].ToChatMessage(t))
+	}
+
+	// Spectators can also see their side channel while the game is in progress
+	if !t.Replay && t.GetPlayerIndexFromID(s.UserID) == -1 {
+		chatList = chatSpectatorsAddToList(t, chatList)
 	}
+
 	s.Emit("chatList", &ChatListMessage{
-		List:   chatList,
-		Unread: len(t.Chat) - t.ChatRead[s.UserID],
+		List:     chatList,
+		Unread:   t.GetChatUnreadSent(s.UserID, numSent),
+		PinnedID: t.PinnedMessageID,
+		Topic:    chatFillAll(t.Topic),
 	})
+
+	// The poll in progress, if any, is sent separately (in "chat_poll.go")
+	if t.Poll != nil {
+		s.Emit("chatPoll", t.Poll.ToMessage(t.GetRoomName(), false))
+	}
+}
+
+// ToChatMessage converts a *TableChatMessage to a *ChatMessage
+// (the table is needed to look up the message that this is a reply to, if any)
+func (gcm *TableChatMessage) ToChatMessage(t *Table) *ChatMessage {
+	msg, action := chatParseAction(gcm.GetFilledMsg())
+	return &ChatMessage{
+		ID:        gcm.ID,
+		Msg:       msg,
+		Who:       gcm.Username,
+		Discord:   gcm.Discord,
+		Server:    gcm.Server,
+		Datetime:  gcm.Datetime,
+		Room:      t.GetRoomName(),
+		Recipient: "",
+		Reactions: gcm.GetReactionCounts(),
+		ReplyTo:   gcm.ReplyTo,
+		Quote:     t.GetChatQuote(gcm.ReplyTo),
+		Action:    action,
+		Role:      gcm.Role,
+		Lookalike: chatGetMessageLookalike(gcm.Username, gcm.Server, gcm.Discord),
+		Review:    gcm.Review,
+	}
+}
+
+// GetFilledMsg applies the same transformations as the lobby (e.g. spoilers and mentions)
+// Table messages are stored untransformed so that they can still be edited,
+// so this must be called every time that a table message is sent to a client
+// Server messages are skipped, since they are not escaped and can contain arbitrary HTML
+func (gcm *TableChatMessage) GetFilledMsg() string {
+	if gcm.Server {
+		return gcm.Msg
+	}
+	return chatFillAll(gcm.Msg)
+}
+
+// chatRestoreFromDatabase fills the in-memory chat history of a table with the messages that were
+// written to the database
+// It is assumed that the table mutex is locked when calling this function
+func chatRestoreFromDatabase(t *Table) {
+	t.ChatRestored = true
+
+	since := t.DatetimeCreated
+	if t.DatetimeChatCleared.After(since) {
+		since = t.DatetimeChatCleared
+	}
+
+	var rawMsgs []DBChatMessage
+	if v, err := models.ChatLog.GetSince(t.GetRoomName(), since); err != nil {
+		logger.Error("Failed to get the chat history for table " + strconv.FormatUint(t.ID, 10) +
+			": " + err.Error())
+		return
+	} else {
+		rawMsgs = v
+	}
+
+	t.Chat = append(t.Chat, tableChatMessagesFromDatabase(rawMsgs)...)
+
+	// The spectators might have been using their side channel (in "chat_spectators.go")
+	chatSpectatorsRestoreFromDatabase(t, since)
+
+	// The database might have fewer messages than were in memory before the restart
+	// (e.g. if some of them failed to be inserted)
+	t.ReconcileChatRead()
+}
+
+// tableChatMessagesFromDatabase converts the messages and restores their reactions
+// (which are stored in the "chat_log_reactions" table)
+func tableChatMessagesFromDatabase(rawMsgs []DBChatMessage) []*TableChatMessage {
+	messageIDs := make([]string, 0, len(rawMsgs))
+	for _, rawMsg := range rawMsgs {
+		messageIDs = append(messageIDs, rawMsg.MessageID)
+	}
+	reactionsMap, err := models.ChatLogReactions.GetUsers(messageIDs)
+	if err != nil {
+		logger.Error("Failed to get the reactions for the restored chat messages: " + err.Error())
+		// Do not return on a failed query, since the messages are more important than the reactions
+	}
+
+	chatMsgs := make([]*TableChatMessage, 0, len(rawMsgs))
+	for _, rawMsg := range rawMsgs {
+		chatMsg := tableChatMessageFromDatabase(rawMsg)
+		if reactions, ok := reactionsMap[rawMsg.MessageID]; ok {
+			chatMsg.Reactions = reactions
+		}
+		chatMsgs = append(chatMsgs, chatMsg)
+	}
+
+	return chatMsgs
+}
+
+func tableChatMessageFromDatabase(rawMsg DBChatMessage) *TableChatMessage {
+	// Server messages and Discord messages are both stored with a user ID of 0
+	discord := rawMsg.DiscordName.Valid
+	server := rawMsg.UserID == 0 && !discord
+	if server {
+		rawMsg.Name = ""
+	} else if discord {
+		rawMsg.Name = rawMsg.DiscordName.String
+	}
+	return &TableChatMessage{
+		ID:        rawMsg.MessageID,
+		UserID:    rawMsg.UserID,
+		Username:  rawMsg.Name,
+		Msg:       rawMsg.Message,
+		Datetime:  rawMsg.Datetime,
+		Server:    server,
+		Discord:   discord,
+		Reactions: make(map[string][]int),
+		ReplyTo:   rawMsg.ReplyTo.String,
+		Role:      chatGetRoleFromDatabase(rawMsg, server, discord),
+	}
 }
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"strings"
+)
+
+type AFKStatusMessage struct {
+	TableID uint64 `json:"tableID"`
+	Name    string `json:"name"`
+	AFK     bool   `json:"afk"`
+	Reason  string `json:"reason"`
+}
+
+// /afk [reason]
+func chatAFK(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	// The message was already sanitized and escaped in the "commandChat()" function
+	reason := strings.Join(d.Args, " ")
+	s.SetAFK(true, reason)
+
+	chatServerSendPM(s, ChatMsgAFKOn, d.Room)
+	chatAFKNotify(ctx, s, d, t, true, reason)
+}
+
+// chatAFKClear removes the AFK status when someone sends a normal message
+func chatAFKClear(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if s == nil || d.Server || d.Discord || !s.AFK() {
+		return
+	}
+
+	s.SetAFK(false, "")
+
+	chatServerSendPM(s, ChatMsgAFKOff, d.Room)
+	chatAFKNotify(ctx, s, d, t, false, "")
+}
+
+// chatAFKNotify sends the new AFK status to everyone at the table that the user is currently at
+// (if the command was not sent from the table, then we have to look it up and lock it)
+func chatAFKNotify(
+	ctx context.Context,
+	s *Session,
+	d *CommandData,
+	t *Table,
+	afk bool,
+	reason string,
+) {
+	if t == nil {
+		tableID := s.TableID()
+		if tableID == 0 {
+			return
+		}
+		if v, exists := getTableAndLock(ctx, nil, tableID, true, !d.NoTablesLock); !exists {
+			return
+		} else {
+			t = v
+		}
+		defer t.Unlock(ctx)
+	}
+
+	t.NotifyAFKStatus(&AFKStatusMessage{
+		TableID: t.ID,
+		Name:    s.Username,
+		AFK:     afk,
+		Reason:  reason,
+	})
+}
+
+// chatAFKCheckMentions lets the sender know if the message mentioned someone who is AFK
+func chatAFKCheckMentions(s *Session, d *CommandData) {
+	if s == nil || d.Server || d.Discord {
+		return
+	}
+
+	for _, mention := range chatParseMentions(d.Msg) {
+		for _, s2 := range sessions.GetList() {
+			if chatMentionMatches(mention, s2.Username) && s2.AFK() {
+				chatServerSendPM(s, chatAFKMsg(s2), d.Room)
+				break
+			}
+		}
+	}
+}
+
+func chatAFKMsg(s *Session) string {
+	msg := s.Username + " is AFK"
+	if reason := s.AFKReason(); reason != "" {
+		msg += ": " + reason
+	}
+	return msg
+}
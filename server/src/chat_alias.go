@@ -0,0 +1,89 @@
+// Server administrators can add extra names for the existing chat commands with the
+// "CHAT_COMMAND_ALIASES" environment variable (e.g. "wh:whisper,lag:uptime")
+// The aliases are resolved both here and on the client,
+// since some of the commands are only handled by the client
+
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+var (
+	// Indexed by the (lowercase) alias; the values are the names of the commands
+	// This map is not modified after initialization
+	chatCommandAliases = make(map[string]string)
+)
+
+// chatCommandAliasesInit must be called after the chat commands are initialized
+func chatCommandAliasesInit() {
+	// The format is a comma-separated list of "alias:command" pairs
+	aliasesString := os.Getenv("CHAT_COMMAND_ALIASES")
+	for _, alias := range strings.Split(aliasesString, ",") {
+		alias = strings.TrimSpace(alias)
+		if alias == "" {
+			continue
+		}
+
+		parts := strings.Split(alias, ":")
+		if len(parts) != 2 {
+			logger.Fatal("The chat command alias of \"" + alias + "\" is not in the format of " +
+				"\"alias:command\".")
+			return
+		}
+		name := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(parts[0]), "/"))
+		command := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(parts[1]), "/"))
+		if name == "" || strings.Contains(name, " ") {
+			logger.Fatal("The chat command alias of \"" + name + "\" is not valid.")
+			return
+		}
+
+		// Real commands always take precedence over aliases
+		if chatCommandExists(name) {
+			logger.Fatal("The chat command alias of \"" + name + "\" is already used by a " +
+				"command.")
+			return
+		}
+		if !chatCommandExists(command) {
+			logger.Fatal("The chat command alias of \"" + name + "\" is for the command of " +
+				"\"" + command + "\", which does not exist.")
+			return
+		}
+
+		chatCommandAliases[name] = command
+	}
+
+	if len(chatCommandAliases) > 0 {
+		logger.Info("Loaded " + strconv.Itoa(len(chatCommandAliases)) + " chat command aliases.")
+	}
+}
+
+// chatCommandExists includes the commands that are only handled by the client
+// (which are listed in "chat_help.go")
+func chatCommandExists(command string) bool {
+	if _, ok := chatCommandMap[command]; ok {
+		return true
+	}
+	if _, ok := chatCommandSilentMap[command]; ok {
+		return true
+	}
+	for _, help := range chatCommandHelp {
+		if help.Name == command || stringInSlice(command, help.Aliases) {
+			return true
+		}
+	}
+	return false
+}
+
+// chatResolveAlias returns the name of the command that an alias is for
+// (or the command itself if it is not an alias)
+func chatResolveAlias(command string) string {
+	if aliasedCommand, ok := chatCommandAliases[command]; ok {
+		return aliasedCommand
+	}
+	return command
+}
@@ -0,0 +1,213 @@
+// Automated accounts that spam the chat keep tripping the rate limiter (in "chat_rate_limit.go"),
+// so someone who trips it repeatedly is temporarily muted and the moderators are notified
+// Every strike before the last one results in a warning, so that genuine users know to slow down
+// The mutes are only stored in memory, so they are lifted if the server restarts
+
+package main
+
+import (
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+	"github.com/sasha-s/go-deadlock"
+)
+
+const (
+	// By default, tripping the rate limiter 3 times within 5 minutes results in a 10 minute mute
+	DefaultChatAutoMuteStrikes         = 3
+	DefaultChatAutoMuteWindowSeconds   = 300
+	DefaultChatAutoMuteDurationSeconds = 600
+
+	// Only the last few messages that tripped the rate limiter are logged
+	ChatAutoMuteMaxLoggedMessages = 10
+	ChatAutoMuteMaxLoggedLength   = 150
+)
+
+var (
+	chatAutoMuteStrikes  int
+	chatAutoMuteWindow   time.Duration
+	chatAutoMuteDuration time.Duration
+	chatAutoMuter        = NewChatAutoMuter()
+)
+
+type ChatAutoMuter struct {
+	strikes map[int]*ChatAutoMuteStrikes // Indexed by user ID
+	muted   map[int]time.Time            // Indexed by user ID; the values are when the mute ends
+	mutex   *deadlock.Mutex
+}
+
+type ChatAutoMuteStrikes struct {
+	Datetimes []time.Time
+	// The messages that were dropped by the rate limiter, which are logged if the user is muted
+	Messages []string
+}
+
+func NewChatAutoMuter() *ChatAutoMuter {
+	return &ChatAutoMuter{
+		strikes: make(map[int]*ChatAutoMuteStrikes),
+		muted:   make(map[int]time.Time),
+		mutex:   &deadlock.Mutex{},
+	}
+}
+
+func chatAutoMuteInit() {
+	chatAutoMuteStrikes = getEnvInt("CHAT_AUTO_MUTE_STRIKES", DefaultChatAutoMuteStrikes)
+	chatAutoMuteWindowSeconds := getEnvInt(
+		"CHAT_AUTO_MUTE_WINDOW_SECONDS",
+		DefaultChatAutoMuteWindowSeconds,
+	)
+	chatAutoMuteWindow = time.Duration(chatAutoMuteWindowSeconds) * time.Second
+	chatAutoMuteDurationSeconds := getEnvInt(
+		"CHAT_AUTO_MUTE_DURATION_SECONDS",
+		DefaultChatAutoMuteDurationSeconds,
+	)
+	chatAutoMuteDuration = time.Duration(chatAutoMuteDurationSeconds) * time.Second
+}
+
+func chatAutoMuteEnabled() bool {
+	return chatAutoMuteStrikes > 0 && chatAutoMuteWindow > 0 && chatAutoMuteDuration > 0
+}
+
+// Strike is called every time that the rate limiter drops a message from a user
+// A burst of messages only counts as one strike, since the rate limiter drops every message in the
+// burst after the first few
+// It returns the number of the new strike (or 0 if this message did not count as a new strike)
+// and the messages that led to the mute (if the user was just muted)
+func (am *ChatAutoMuter) Strike(userID int, msg string) (int, []string) {
+	if !chatAutoMuteEnabled() {
+		return 0, nil
+	}
+
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	strikes, ok := am.strikes[userID]
+	if !ok {
+		strikes = &ChatAutoMuteStrikes{
+			Datetimes: make([]time.Time, 0),
+			Messages:  make([]string, 0),
+		}
+		am.strikes[userID] = strikes
+	}
+
+	// Discard any strikes that have fallen outside of the window
+	now := time.Now()
+	recentStrikes := make([]time.Time, 0, len(strikes.Datetimes))
+	for _, datetime := range strikes.Datetimes {
+		if now.Sub(datetime) < chatAutoMuteWindow {
+			recentStrikes = append(recentStrikes, datetime)
+		}
+	}
+	strikes.Datetimes = recentStrikes
+	if len(strikes.Datetimes) == 0 {
+		strikes.Messages = make([]string, 0)
+	}
+
+	strikes.Messages = append(strikes.Messages, truncateRunes(msg, ChatAutoMuteMaxLoggedLength))
+	if len(strikes.Messages) > ChatAutoMuteMaxLoggedMessages {
+		strikes.Messages = strikes.Messages[len(strikes.Messages)-ChatAutoMuteMaxLoggedMessages:]
+	}
+
+	if len(strikes.Datetimes) > 0 {
+		lastStrike := strikes.Datetimes[len(strikes.Datetimes)-1]
+		if now.Sub(lastStrike) < chatRateLimitWindow {
+			return 0, nil
+		}
+	}
+	strikes.Datetimes = append(strikes.Datetimes, now)
+
+	numStrikes := len(strikes.Datetimes)
+	if numStrikes < chatAutoMuteStrikes {
+		return numStrikes, nil
+	}
+
+	am.muted[userID] = now.Add(chatAutoMuteDuration)
+	delete(am.strikes, userID)
+	return numStrikes, strikes.Messages
+}
+
+// IsMuted returns false if the user is not muted (or if their mute has ended)
+// Otherwise, it returns the amount of time that is left
+func (am *ChatAutoMuter) IsMuted(userID int) (time.Duration, bool) {
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	end, ok := am.muted[userID]
+	if !ok {
+		return 0, false
+	}
+	remaining := time.Until(end)
+	if remaining <= 0 {
+		delete(am.muted, userID)
+		return 0, false
+	}
+	return remaining, true
+}
+
+// chatAutoMuteCheck returns false if the user is temporarily muted
+func chatAutoMuteCheck(s *Session, room string) bool {
+	if s == nil {
+		return true
+	}
+
+	remaining, muted := chatAutoMuter.IsMuted(s.UserID)
+	if !muted {
+		return true
+	}
+
+	chatServerSendPM(s, ChatMsgAutoMuted, room, chatAutoMuteDurationString(remaining))
+	return false
+}
+
+// chatAutoMuteStrike is called from the "commandChat()" function after the rate limiter has
+// dropped a message
+func chatAutoMuteStrike(s *Session, d *CommandData) {
+	// Moderators are trusted not to be bots
+	if s == nil || s.Moderator {
+		return
+	}
+
+	numStrikes, messages := chatAutoMuter.Strike(s.UserID, d.Msg)
+	if numStrikes == 0 {
+		return
+	}
+
+	durationString := chatAutoMuteDurationString(chatAutoMuteDuration)
+	if messages == nil {
+		chatServerSendPM(s, ChatMsgAutoMuteWarning, d.Room, numStrikes, chatAutoMuteStrikes-1,
+			durationString)
+		return
+	}
+
+	logger.Info("Automatically muted user \"" + s.Username + "\" for " + durationString +
+		" after tripping the chat rate limiter " + strconv.Itoa(numStrikes) + " times in " +
+		"room \"" + d.Room + "\". The triggering messages were:\n" +
+		strings.Join(messages, "\n"))
+
+	chatServerSendPM(s, ChatMsgAutoMuted, d.Room, durationString)
+
+	// Let the moderators who are online know, in case the mute needs to be made permanent
+	// (the messages were not escaped yet, since the rate limiter is checked first)
+	msg := s.Username + " was automatically muted for " + durationString + " for sending " +
+		"messages too quickly in room \"" + d.Room + "\". Last message: " +
+		html.EscapeString(messages[len(messages)-1])
+	for _, s2 := range sessions.GetList() {
+		if s2.Moderator {
+			chatServerSendPM(s2, msg, "lobby")
+		}
+	}
+}
+
+func chatAutoMuteDurationString(duration time.Duration) string {
+	seconds := int(duration.Round(time.Second).Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	if v, err := secondsToDurationString(seconds); err == nil {
+		return v
+	}
+	return strconv.Itoa(seconds) + " seconds"
+}
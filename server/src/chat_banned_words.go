@@ -0,0 +1,188 @@
+// Unlike the profanity filter (in "chat_profanity.go"), which censors words,
+// a message that contains a banned word or pattern (e.g. a slur or a phone number) is not sent
+// at all
+// The list is in the file specified by the "CHAT_BANNED_WORDS_FILE" environment variable,
+// and it can be reloaded without restarting the server
+
+package main
+
+import (
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+	"github.com/sasha-s/go-deadlock"
+)
+
+const (
+	// Lines that start with this are regular expressions instead of words
+	ChatBannedWordsRegExpPrefix = "regex:"
+)
+
+var (
+	chatBannedWords = NewChatBannedWords()
+)
+
+type ChatBannedWords struct {
+	words   map[string]struct{} // Lowercase
+	regexps []*regexp.Regexp
+	mutex   *deadlock.RWMutex
+}
+
+func NewChatBannedWords() *ChatBannedWords {
+	return &ChatBannedWords{
+		words:   make(map[string]struct{}),
+		regexps: make([]*regexp.Regexp, 0),
+		mutex:   &deadlock.RWMutex{},
+	}
+}
+
+func chatBannedWordsInit() {
+	if err := chatBannedWords.Load(); err != nil {
+		logger.Fatal("Failed to load the banned words: " + err.Error())
+	}
+}
+
+// Load reads the banned words file (if any) and replaces the current list
+// If the file cannot be read or parsed, the current list is left untouched
+func (bw *ChatBannedWords) Load() error {
+	words := make(map[string]struct{})
+	regexps := make([]*regexp.Regexp, 0)
+
+	bannedWordsPath := os.Getenv("CHAT_BANNED_WORDS_FILE")
+	if len(bannedWordsPath) > 0 {
+		if !filepath.IsAbs(bannedWordsPath) {
+			bannedWordsPath = path.Join(projectPath, bannedWordsPath)
+		}
+
+		var fileContents []byte
+		if v, err := ioutil.ReadFile(bannedWordsPath); err != nil {
+			return fmt.Errorf("failed to read the \"%v\" file: %w", bannedWordsPath, err)
+		} else {
+			fileContents = v
+		}
+
+		// The file has one word (or "regex:" followed by a regular expression) per line;
+		// blank lines and lines starting with "#" are ignored
+		// Regular expressions are case-insensitive
+		for i, line := range strings.Split(string(fileContents), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			if strings.HasPrefix(line, ChatBannedWordsRegExpPrefix) {
+				pattern := strings.TrimPrefix(line, ChatBannedWordsRegExpPrefix)
+				if v, err := regexp.Compile("(?i)" + pattern); err != nil {
+					return fmt.Errorf("failed to compile the regular expression on line %v: %w",
+						i+1, err)
+				} else {
+					regexps = append(regexps, v)
+				}
+				continue
+			}
+
+			words[strings.ToLower(line)] = struct{}{}
+		}
+	}
+
+	bw.mutex.Lock()
+	bw.words = words
+	bw.regexps = regexps
+	bw.mutex.Unlock()
+
+	if len(words) > 0 || len(regexps) > 0 {
+		logger.Info("Loaded " + strconv.Itoa(len(words)) + " banned words and " +
+			strconv.Itoa(len(regexps)) + " banned patterns.")
+	}
+	return nil
+}
+
+// Match returns the banned word or pattern that the message contains, if any
+// Words are only matched as whole words (in the same way as the profanity filter),
+// so that e.g. "classic" is not blocked by "ass"
+// The message should not be HTML-escaped yet
+func (bw *ChatBannedWords) Match(msg string) (string, bool) {
+	bw.mutex.RLock()
+	defer bw.mutex.RUnlock()
+
+	if len(bw.words) > 0 {
+		for _, word := range profanityWordRegExp.FindAllString(msg, -1) {
+			word = strings.ToLower(word)
+			if _, ok := bw.words[word]; ok {
+				return word, true
+			}
+		}
+
+		// Multi-word phrases cannot be found by looking at one word at a time
+		lowercaseMsg := " " + strings.Join(profanityWordRegExp.FindAllString(
+			strings.ToLower(msg),
+			-1,
+		), " ") + " "
+		for word := range bw.words {
+			if strings.Contains(word, " ") && strings.Contains(lowercaseMsg, " "+word+" ") {
+				return word, true
+			}
+		}
+	}
+
+	for _, bannedRegExp := range bw.regexps {
+		if bannedRegExp.MatchString(msg) {
+			return bannedRegExp.String(), true
+		}
+	}
+
+	return "", false
+}
+
+// chatBannedWordsCheck returns false if the message contains a banned word or pattern
+// The attempt is logged and the moderators who are online are notified
+// (server messages are exempt)
+func chatBannedWordsCheck(s *Session, d *CommandData, msg string) bool {
+	if d.Server {
+		return true
+	}
+
+	match, ok := chatBannedWords.Match(msg)
+	if !ok {
+		return true
+	}
+	chatMetrics.Dropped(ChatDroppedBannedWord)
+
+	name := d.Username
+	if name == "" && s != nil {
+		name = s.Username
+	}
+	if d.Discord {
+		name += " (from Discord)"
+	}
+	room := d.Room
+	if d.Recipient != "" {
+		room = "a private message to " + d.Recipient
+	}
+	logger.Info("Blocked a chat message from \"" + name + "\" in room \"" + room + "\" because " +
+		"it matched the banned word or pattern of \"" + match + "\": " + msg)
+
+	// Let the moderators who are online know, since the sender might need to be muted or banned
+	// (the message was not escaped yet)
+	modMsg := "Blocked a message from " + html.EscapeString(name) + " in " +
+		html.EscapeString(room) + " because it contains a banned word or pattern: " +
+		html.EscapeString(msg)
+	for _, s2 := range sessions.GetList() {
+		if s2.Moderator && (s == nil || s2.UserID != s.UserID) {
+			chatServerSendPM(s2, modMsg, "lobby")
+		}
+	}
+
+	if s != nil {
+		chatServerSendPM(s, ChatMsgBannedWord, d.Room)
+	}
+	return false
+}
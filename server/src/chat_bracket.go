@@ -0,0 +1,217 @@
+// Tournament organizers can send the bracket of a tournament to the server,
+// which announces the pairings for each round in the lobby and at the tables that the paired
+// players are at (instead of the organizers having to paste them manually)
+// The bracket is only kept in memory, so it is lost if the server restarts
+
+package main
+
+import (
+	"context"
+	"errors"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/sasha-s/go-deadlock"
+)
+
+const (
+	TournamentBracketMaxRounds   = 20
+	TournamentBracketMaxPairings = 32
+)
+
+var (
+	tournamentBracket = &TournamentBracketState{
+		Bracket:      nil,
+		CurrentRound: 0,
+		mutex:        &deadlock.Mutex{},
+	}
+)
+
+type TournamentBracketState struct {
+	Bracket      *TournamentBracket // nil if there is no bracket
+	CurrentRound int                // Starts at 1 (like the rounds that are shown to the user)
+	mutex        *deadlock.Mutex
+}
+
+// TournamentBracket is the structured data that is sent by the organizers, e.g.
+// {
+//   "name": "Winter Tournament",
+//   "rounds": [
+//     {
+//       "name": "Round 1",
+//       "pairings": [
+//         { "name": "Table A", "players": ["Alice", "Bob", "Cathy"] },
+//         { "name": "Table B", "players": ["Donald", "Emily", "Frank"] }
+//       ]
+//     }
+//   ]
+// }
+type TournamentBracket struct {
+	Name   string             `json:"name"`
+	Rounds []*TournamentRound `json:"rounds"`
+}
+
+type TournamentRound struct {
+	Name     string               `json:"name"` // Optional; defaults to e.g. "Round 1"
+	Pairings []*TournamentPairing `json:"pairings"`
+}
+
+type TournamentPairing struct {
+	Name    string   `json:"name"` // Optional; e.g. the name of the table or the team
+	Players []string `json:"players"`
+}
+
+// chatBracketSet replaces the current bracket (if any)
+// The current round is reset to the first round
+func chatBracketSet(bracket *TournamentBracket) error {
+	if err := chatBracketValidate(bracket); err != nil {
+		return err
+	}
+
+	tournamentBracket.mutex.Lock()
+	tournamentBracket.Bracket = bracket
+	tournamentBracket.CurrentRound = 1
+	tournamentBracket.mutex.Unlock()
+
+	return nil
+}
+
+func chatBracketValidate(bracket *TournamentBracket) error {
+	if bracket == nil {
+		return errors.New("the bracket is empty")
+	}
+	bracket.Name = strings.TrimSpace(bracket.Name)
+	if bracket.Name == "" {
+		return errors.New("the bracket must have a name")
+	}
+	if len(bracket.Rounds) == 0 {
+		return errors.New("the bracket must have at least one round")
+	}
+	if len(bracket.Rounds) > TournamentBracketMaxRounds {
+		return errors.New("the bracket cannot have more than " +
+			strconv.Itoa(TournamentBracketMaxRounds) + " rounds")
+	}
+
+	for i, round := range bracket.Rounds {
+		roundString := "round " + strconv.Itoa(i+1)
+		if round == nil || len(round.Pairings) == 0 {
+			return errors.New(roundString + " must have at least one pairing")
+		}
+		if len(round.Pairings) > TournamentBracketMaxPairings {
+			return errors.New(roundString + " cannot have more than " +
+				strconv.Itoa(TournamentBracketMaxPairings) + " pairings")
+		}
+		round.Name = strings.TrimSpace(round.Name)
+		if round.Name == "" {
+			round.Name = "Round " + strconv.Itoa(i+1)
+		}
+
+		for j, pairing := range round.Pairings {
+			pairingString := "pairing " + strconv.Itoa(j+1) + " of " + roundString
+			if pairing == nil || len(pairing.Players) == 0 {
+				return errors.New(pairingString + " must have at least one player")
+			}
+			pairing.Name = strings.TrimSpace(pairing.Name)
+			for k, player := range pairing.Players {
+				pairing.Players[k] = strings.TrimSpace(player)
+				if pairing.Players[k] == "" {
+					return errors.New(pairingString + " has a blank player")
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// chatBracketAnnounce sends the pairings for a round to the lobby and to every table that one of
+// the paired players is at
+// If the round is 0, the current round is announced again;
+// otherwise, the round becomes the current round
+func chatBracketAnnounce(ctx context.Context, roundNum int) error {
+	tournamentBracket.mutex.Lock()
+	bracket := tournamentBracket.Bracket
+	if bracket == nil {
+		tournamentBracket.mutex.Unlock()
+		return errors.New("there is no bracket")
+	}
+	if roundNum == 0 {
+		roundNum = tournamentBracket.CurrentRound
+	}
+	if roundNum < 1 || roundNum > len(bracket.Rounds) {
+		tournamentBracket.mutex.Unlock()
+		return errors.New("the round must be between 1 and " + strconv.Itoa(len(bracket.Rounds)))
+	}
+	tournamentBracket.CurrentRound = roundNum
+	tournamentBracket.mutex.Unlock()
+
+	// The bracket is never modified after it is set, so we do not need the mutex for the rest
+	round := bracket.Rounds[roundNum-1]
+
+	// Server messages are not escaped (so that the server can send links),
+	// so everything that came from the organizers has to be escaped here
+	// Each pairing is sent as a separate message so that the list stays readable
+	// (and so that a long list is not truncated)
+	msgs := []string{
+		html.EscapeString(bracket.Name) + " - " + html.EscapeString(round.Name) + " (" +
+			strconv.Itoa(roundNum) + " of " + strconv.Itoa(len(bracket.Rounds)) + ") pairings:",
+	}
+	normalizedPlayers := make(map[string]struct{})
+	for i, pairing := range round.Pairings {
+		msg := strconv.Itoa(i+1) + ") "
+		if pairing.Name != "" {
+			msg += html.EscapeString(pairing.Name) + ": "
+		}
+		escapedPlayers := make([]string, 0, len(pairing.Players))
+		for _, player := range pairing.Players {
+			escapedPlayers = append(escapedPlayers, html.EscapeString(player))
+			normalizedPlayers[normalizeString(player)] = struct{}{}
+		}
+		msg += strings.Join(escapedPlayers, ", ")
+		msgs = append(msgs, msg)
+	}
+
+	// We must acquire the tables lock before entering the "chatServerSendTables()" function
+	tables.Lock(ctx)
+	defer tables.Unlock(ctx)
+
+	tableList := make([]*Table, 0)
+	for _, t := range tables.GetList(false) {
+		if chatBracketTableIsRelevant(ctx, t, normalizedPlayers) {
+			tableList = append(tableList, t)
+		}
+	}
+
+	for _, msg := range msgs {
+		chatServerSend(ctx, msg, "lobby", true)
+		chatServerSendTables(ctx, msg, tableList)
+	}
+
+	return nil
+}
+
+// chatBracketTableIsRelevant returns true if one of the players is playing or spectating at the
+// table
+// It is assumed that the tables mutex is locked when calling this function
+func chatBracketTableIsRelevant(
+	ctx context.Context,
+	t *Table,
+	normalizedPlayers map[string]struct{},
+) bool {
+	t.Lock(ctx)
+	defer t.Unlock(ctx)
+
+	for _, p := range t.Players {
+		if _, ok := normalizedPlayers[normalizeString(p.Name)]; ok {
+			return true
+		}
+	}
+	for _, sp := range t.Spectators {
+		if _, ok := normalizedPlayers[normalizeString(sp.Name)]; ok {
+			return true
+		}
+	}
+
+	return false
+}
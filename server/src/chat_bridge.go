@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+// /bridge [on/off]
+// Temporarily stop replicating the messages from the current room to Discord
+// (e.g. during an off-topic flood); this is reset when the server restarts
+func chatBridge(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if !s.Moderator {
+		chatServerSendPM(s, ChatMsgNotMod, d.Room)
+		return
+	}
+
+	if len(discordGetBridgedChannels(d.Room)) == 0 {
+		chatServerSendPM(s, "This room is not bridged to Discord.", d.Room)
+		return
+	}
+
+	if len(d.Args) == 0 {
+		msg := "Bridging to Discord is currently "
+		if discordBridgeIsOff(d.Room) {
+			msg += "off"
+		} else {
+			msg += "on"
+		}
+		msg += " for this room. The format of the /bridge command is: /bridge [on/off]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	var on bool
+	switch strings.ToLower(d.Args[0]) {
+	case "on":
+		on = true
+	case "off":
+		on = false
+	default:
+		msg := "The format of the /bridge command is: /bridge [on/off]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	state := strings.ToLower(d.Args[0])
+	if !discordBridgeSet(d.Room, on) {
+		chatServerSendPM(s, "Bridging to Discord is already "+state+" for this room.", d.Room)
+		return
+	}
+
+	logger.Info("Moderator \"" + s.Username + "\" turned bridging to Discord " + state + " for " +
+		"room \"" + d.Room + "\".")
+	chatServerSendPM(s, "Bridging to Discord is now "+state+" for this room.", d.Room)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+// ChatClearMessage is sent to clients when all of the messages at a table have been cleared
+type ChatClearMessage struct {
+	Room string `json:"room"`
+}
+
+// /clear [--hard]
+// By default, the messages are only cleared from memory (so that they are still available to
+// moderators); moderators can use the "--hard" flag to also delete them from the database
+func chatClear(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if t == nil {
+		chatServerSendPM(s, ChatMsgNotInGame, d.Room)
+		return
+	}
+
+	if s.UserID != t.OwnerID && !s.Moderator {
+		chatServerSendPM(s, "Only the table owner or a moderator can clear the chat.", d.Room)
+		return
+	}
+
+	hard := false
+	if len(d.Args) == 1 && d.Args[0] == "--hard" {
+		hard = true
+	} else if len(d.Args) != 0 {
+		msg := "The format of the /clear command is: /clear [--hard]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	if hard {
+		if !s.Moderator {
+			chatServerSendPM(s, "Only moderators can use the \"--hard\" flag.", d.Room)
+			return
+		}
+
+		// Table IDs are reused after a server restart,
+		// so we only delete the messages that were sent after this table was created
+		// (the review comments in a replay are stored in a different room, in "chat_review.go")
+		room := d.Room
+		if t.GetReviewRoomName() != "" {
+			room = t.GetReviewRoomName()
+		}
+		if numDeleted, err := models.ChatLog.DeleteSince(room, t.DatetimeCreated); err != nil {
+			logger.Error("Failed to delete the chat messages for " + t.GetName() + ": " +
+				err.Error())
+			s.Error(DefaultErrorMsg)
+			return
+		} else {
+			logger.Info(t.GetName() + " Moderator \"" + s.Username + "\" deleted " +
+				strconv.FormatInt(numDeleted, 10) + " chat messages.")
+		}
+	}
+
+	t.Chat = make([]*TableChatMessage, 0)
+	t.PinnedMessageID = ""
+	for userID := range t.ChatRead {
+		t.ChatRead[userID] = 0
+	}
+
+	// Prevent the cleared messages from being restored from the database after a server restart
+	t.DatetimeChatCleared = time.Now()
+
+	t.NotifyChatClear(&ChatClearMessage{
+		Room: d.Room,
+	})
+	t.NotifyChatUnread(nil)
+}
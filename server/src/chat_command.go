@@ -8,6 +8,10 @@ import (
 var (
 	// Used to store all of the functions that handle each command
 	chatCommandMap = make(map[string]func(context.Context, *Session, *CommandData, *Table))
+
+	// Used to store all of the functions that handle commands that are not echoed to the room
+	// (these are checked before the chat message is stored or sent to anyone)
+	chatCommandSilentMap = make(map[string]func(context.Context, *Session, *CommandData, *Table))
 )
 
 func chatCommandInit() {
@@ -43,6 +47,7 @@ func chatCommandInit() {
 	chatCommandMap["random"] = chatRandom
 	chatCommandMap["uptime"] = chatUptime
 	chatCommandMap["timeleft"] = chatTimeLeft
+	chatCommandMap["me"] = chatMe
 
 	// Undocumented info commands (that work only in the lobby)
 	// chatCommandMap["here"] = chatHere
@@ -76,9 +81,8 @@ func chatCommandInit() {
 	chatCommandMap["randomvariant"] = chatFindVariant
 	chatCommandMap["random-variant"] = chatFindVariant
 
-	// Table-only commands (game only)
-	// chatCommandMap["pause"] = chatPause
-	// chatCommandMap["unpause"] = chatUnpause
+	// Table-only commands (pregame, game, or replay)
+	chatCommandMap["roll"] = chatRoll
 
 	// Table-only commands (replay only)
 	chatCommandMap["suggest"] = chatSuggest
@@ -93,8 +97,115 @@ func chatCommandInit() {
 	chatCommandMap["f"] = chatCommandWebsiteOnly
 	chatCommandMap["friend"] = chatCommandWebsiteOnly
 	chatCommandMap["friends"] = chatCommandWebsiteOnly
+	chatCommandMap["friendlist"] = chatCommandWebsiteOnly
+	chatCommandMap["friendslist"] = chatCommandWebsiteOnly
 	chatCommandMap["unfriend"] = chatCommandWebsiteOnly
 	chatCommandMap["version"] = chatCommandWebsiteOnly
+	chatCommandMap["edit"] = chatCommandWebsiteOnly
+	chatCommandMap["deletemsg"] = chatCommandWebsiteOnly
+	chatCommandMap["pin"] = chatCommandWebsiteOnly
+	chatCommandMap["clear"] = chatCommandWebsiteOnly
+	chatCommandMap["afk"] = chatCommandWebsiteOnly
+	chatCommandMap["motd"] = chatCommandWebsiteOnly
+	chatCommandMap["history"] = chatCommandWebsiteOnly
+	chatCommandMap["ignore"] = chatCommandWebsiteOnly
+	chatCommandMap["unignore"] = chatCommandWebsiteOnly
+	chatCommandMap["ignorelist"] = chatCommandWebsiteOnly
+	chatCommandMap["lastseen"] = chatCommandWebsiteOnly
+	chatCommandMap["stats"] = chatCommandWebsiteOnly
+	chatCommandMap["search"] = chatCommandWebsiteOnly
+	chatCommandMap["slowmode"] = chatCommandWebsiteOnly
+	chatCommandMap["report"] = chatCommandWebsiteOnly
+	chatCommandMap["who"] = chatCommandWebsiteOnly
+	chatCommandMap["mutespectator"] = chatCommandWebsiteOnly
+	chatCommandMap["unmutespectator"] = chatCommandWebsiteOnly
+	chatCommandMap["variantinfo"] = chatCommandWebsiteOnly
+	chatCommandMap["gameevents"] = chatCommandWebsiteOnly
+	chatCommandMap["unread"] = chatCommandWebsiteOnly
+	chatCommandMap["invite"] = chatCommandWebsiteOnly
+	chatCommandMap["everyone"] = chatCommandWebsiteOnly
+	chatCommandMap["bridge"] = chatCommandWebsiteOnly
+	chatCommandMap["highlight"] = chatCommandWebsiteOnly
+	chatCommandMap["unhighlight"] = chatCommandWebsiteOnly
+	chatCommandMap["highlights"] = chatCommandWebsiteOnly
+	chatCommandMap["pause"] = chatCommandWebsiteOnly
+	chatCommandMap["unpause"] = chatCommandWebsiteOnly
+	chatCommandMap["spectators"] = chatCommandWebsiteOnly
+	chatCommandMap["tz"] = chatCommandWebsiteOnly
+	chatCommandMap["spectatornotices"] = chatCommandWebsiteOnly
+	chatCommandMap["lockroom"] = chatCommandWebsiteOnly
+	chatCommandMap["feedback"] = chatCommandWebsiteOnly
+	chatCommandMap["feedbacklist"] = chatCommandWebsiteOnly
+	chatCommandMap["translate"] = chatCommandWebsiteOnly
+	chatCommandMap["topic"] = chatCommandWebsiteOnly
+	chatCommandMap["poll"] = chatCommandWebsiteOnly
+	chatCommandMap["publicpoll"] = chatCommandWebsiteOnly
+	chatCommandMap["closepoll"] = chatCommandWebsiteOnly
+	chatCommandMap["vote"] = chatCommandWebsiteOnly
+
+	// Silent commands (that work both in the lobby and at a table)
+	// (the non-silent "/help" above is still used from Discord)
+	chatCommandSilentMap["help"] = chatHelpList
+	chatCommandSilentMap["commands"] = chatHelpList
+	chatCommandSilentMap["?"] = chatHelpList
+	chatCommandSilentMap["edit"] = chatEdit
+	chatCommandSilentMap["afk"] = chatAFK
+	chatCommandSilentMap["ignore"] = chatIgnore
+	chatCommandSilentMap["unignore"] = chatUnignore
+	chatCommandSilentMap["ignorelist"] = chatIgnoreList
+	chatCommandSilentMap["lastseen"] = chatLastSeen
+	chatCommandSilentMap["stats"] = chatStats
+	chatCommandSilentMap["f"] = chatFriends
+	chatCommandSilentMap["friends"] = chatFriends
+	chatCommandSilentMap["friendlist"] = chatFriends
+	chatCommandSilentMap["friendslist"] = chatFriends
+	chatCommandSilentMap["search"] = chatSearch
+	chatCommandSilentMap["report"] = chatReport
+	chatCommandSilentMap["feedback"] = chatFeedback
+	chatCommandSilentMap["translate"] = chatTranslate
+	chatCommandSilentMap["who"] = chatWho
+	chatCommandSilentMap["variantinfo"] = chatVariantInfo
+	chatCommandSilentMap["unread"] = chatUnread
+	chatCommandSilentMap["everyone"] = chatEveryone
+	chatCommandSilentMap["topic"] = chatTopic
+	chatCommandSilentMap["highlight"] = chatHighlight
+	chatCommandSilentMap["unhighlight"] = chatUnhighlight
+	chatCommandSilentMap["highlights"] = chatHighlights
+	chatCommandSilentMap["tz"] = chatTZ
+
+	// Silent table-only commands (pregame, game, or replay)
+	chatCommandSilentMap["whisper"] = chatWhisper
+	chatCommandSilentMap["invite"] = chatInvite
+
+	// Silent table-only commands (game only, players only)
+	chatCommandSilentMap["pause"] = chatPause
+	chatCommandSilentMap["unpause"] = chatUnpause
+
+	// Silent table-only commands (game only, spectators only)
+	chatCommandSilentMap["spectators"] = chatSpectators
+
+	// Silent table-only commands (replay only)
+	chatCommandSilentMap["poll"] = chatPoll
+	chatCommandSilentMap["publicpoll"] = chatPublicPoll
+	chatCommandSilentMap["closepoll"] = chatClosePoll
+	chatCommandSilentMap["vote"] = chatVote
+
+	// Silent table-only commands (table owner or moderator only)
+	chatCommandSilentMap["pin"] = chatPin
+	chatCommandSilentMap["clear"] = chatClear
+	chatCommandSilentMap["slowmode"] = chatSlowMode
+	chatCommandSilentMap["mutespectator"] = chatMuteSpectator
+	chatCommandSilentMap["unmutespectator"] = chatUnmuteSpectator
+	chatCommandSilentMap["gameevents"] = chatGameEvents
+	chatCommandSilentMap["spectatornotices"] = chatSpectatorNotices
+
+	// Silent moderator-only commands (that work both in the lobby and at a table)
+	chatCommandSilentMap["deletemsg"] = chatDeleteMsg
+	chatCommandSilentMap["motd"] = chatMOTD
+	chatCommandSilentMap["history"] = chatHistory
+	chatCommandSilentMap["bridge"] = chatBridge
+	chatCommandSilentMap["lockroom"] = chatLockRoom
+	chatCommandSilentMap["feedbacklist"] = chatFeedbackList
 }
 
 func chatCommand(ctx context.Context, s *Session, d *CommandData, t *Table) {
@@ -110,6 +221,8 @@ func chatCommand(ctx context.Context, s *Session, d *CommandData, t *Table) {
 	}
 	command = strings.TrimPrefix(command, "/")
 	command = strings.ToLower(command) // Commands are case-insensitive
+	command = chatResolveAlias(command)
+	chatCommandLog(s, d, command, d.Args)
 
 	// Check to see if there is a command handler for this command
 	chatCommandFunction, ok := chatCommandMap[command]
@@ -121,6 +234,36 @@ func chatCommand(ctx context.Context, s *Session, d *CommandData, t *Table) {
 	}
 }
 
+// chatCommandSilent checks to see if a chat message is a command that should not be echoed to the
+// room (e.g. because it modifies a previous message)
+// It returns true if the message was handled by a silent command
+func chatCommandSilent(ctx context.Context, s *Session, d *CommandData, t *Table) bool {
+	// Silent commands can only be performed by users on the website
+	if s == nil || d.Server || d.Discord {
+		return false
+	}
+
+	// Parse the command
+	args := strings.Split(d.Msg, " ")
+	command := args[0]
+	if !strings.HasPrefix(command, "/") {
+		return false
+	}
+	command = strings.TrimPrefix(command, "/")
+	command = strings.ToLower(command) // Commands are case-insensitive
+	command = chatResolveAlias(command)
+
+	chatCommandFunction, ok := chatCommandSilentMap[command]
+	if !ok {
+		return false
+	}
+
+	d.Args = args[1:] // This will be an empty slice if there is nothing after the command
+	chatCommandLog(s, d, command, d.Args)
+	chatCommandFunction(ctx, s, d, t)
+	return true
+}
+
 func chatCommandWebsiteOnly(ctx context.Context, s *Session, d *CommandData, t *Table) {
 	msg := "You cannot perform that command from Discord; please use the website instead."
 	chatServerSend(ctx, msg, d.Room, d.NoTablesLock)
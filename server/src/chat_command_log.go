@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+	"go.uber.org/zap"
+)
+
+var (
+	chatCommandLoggingEnabled bool
+
+	// The arguments to these commands contain a message body,
+	// so we only log the recipient and the number of arguments
+	// (the values are whether or not the first argument is the recipient)
+	chatCommandLogSensitive = map[string]bool{
+		"pm":      true,
+		"w":       true,
+		"whisper": true,
+		"msg":     true,
+		"r":       false,
+	}
+)
+
+func chatCommandLogInit() {
+	chatCommandLoggingEnabled = getEnvInt("CHAT_COMMAND_LOGGING", 1) != 0
+}
+
+// chatCommandLog records every chat command that is processed so that moderators can reconstruct
+// incidents later on
+// "command" should already be lowercase and stripped of the leading slash
+func chatCommandLog(s *Session, d *CommandData, command string, args []string) {
+	if !chatCommandLoggingEnabled || d.Server {
+		return
+	}
+
+	userID := 0 // 0 is a Discord user
+	if s != nil {
+		userID = s.UserID
+	}
+
+	fields := []zap.Field{
+		zap.String("command", command),
+		zap.String("username", d.Username),
+		zap.Int("userID", userID),
+		zap.Bool("discord", d.Discord),
+		zap.String("room", d.Room),
+	}
+	if hasRecipient, ok := chatCommandLogSensitive[command]; ok {
+		fields = append(fields, zap.Int("numArgs", len(args)))
+		if hasRecipient && len(args) > 0 {
+			fields = append(fields, zap.String("recipient", args[0]))
+		}
+	} else {
+		fields = append(fields, zap.Strings("args", args))
+	}
+
+	logger.Info("Chat command: /"+command, fields...)
+}
+
+// chatLogRedact returns the text of a chat message as it should appear in the logs
+// The body of a sensitive command (e.g. "/whisper") is replaced with the number of words in it
+func chatLogRedact(msg string) string {
+	if !strings.HasPrefix(msg, "/") {
+		return msg
+	}
+
+	args := strings.Split(msg, " ")
+	command := strings.ToLower(strings.TrimPrefix(args[0], "/"))
+	command = chatResolveAlias(command)
+	hasRecipient, ok := chatCommandLogSensitive[command]
+	if !ok {
+		return msg
+	}
+
+	text := args[0]
+	args = args[1:]
+	if hasRecipient && len(args) > 0 {
+		text += " " + args[0]
+		args = args[1:]
+	}
+	return text + " [" + strconv.Itoa(len(args)) + " words redacted]"
+}
@@ -0,0 +1,214 @@
+// Some chat commands are cheap to spam but noisy or expensive for the server (e.g. "/roll" or
+// "/missingscores"), so each user has to wait for a while before they can use them again
+// (this is separate from the chat rate-limiting in "chat_rate_limit.go";
+// a command must pass both checks)
+
+package main
+
+import (
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+	"github.com/sasha-s/go-deadlock"
+)
+
+type ChatCommandCooldown struct {
+	// Aliases of the same command share a cooldown
+	Commands []string
+	Seconds  int
+}
+
+var (
+	// Commands that are not listed here (e.g. "/me") do not have a cooldown
+	// These can be overwritten with the "CHAT_COMMAND_COOLDOWNS" environment variable
+	defaultChatCommandCooldowns = []ChatCommandCooldown{
+		{
+			Commands: []string{"roll"},
+			Seconds:  10,
+		},
+		{
+			Commands: []string{"random"},
+			Seconds:  10,
+		},
+		{
+			Commands: []string{
+				"m",
+				"missing",
+				"missingscores",
+				"missing-scores",
+				"sharedmissingscores",
+				"shared-missing-scores",
+			},
+			Seconds: 30,
+		},
+		{
+			Commands: []string{
+				"fv",
+				"findvariant",
+				"find-variant",
+				"randomvariant",
+				"random-variant",
+			},
+			Seconds: 10,
+		},
+		{
+			Commands: []string{"lastseen"},
+			Seconds:  10,
+		},
+		{
+			Commands: []string{"search"},
+			Seconds:  10,
+		},
+		{
+			Commands: []string{"who"},
+			Seconds:  10,
+		},
+		{
+			Commands: []string{"report"},
+			Seconds:  60,
+		},
+		{
+			Commands: []string{"feedback"},
+			Seconds:  60,
+		},
+		{
+			Commands: []string{"translate"},
+			Seconds:  10,
+		},
+		{
+			Commands: []string{"everyone"},
+			Seconds:  60,
+		},
+	}
+
+	chatCommandCooldowns = NewChatCommandCooldowns()
+)
+
+type ChatCommandCooldowns struct {
+	// Each alias is mapped to the first command of its group (e.g. "m" is mapped to "m")
+	groups    map[string]string
+	cooldowns map[string]time.Duration // Indexed by group
+	// Indexed by user ID, then by group
+	// The values are the times that each command was last used
+	lastUsed map[int]map[string]time.Time
+	mutex    *deadlock.Mutex
+}
+
+func NewChatCommandCooldowns() *ChatCommandCooldowns {
+	return &ChatCommandCooldowns{
+		groups:    make(map[string]string),
+		cooldowns: make(map[string]time.Duration),
+		lastUsed:  make(map[int]map[string]time.Time),
+		mutex:     &deadlock.Mutex{},
+	}
+}
+
+func chatCommandCooldownsInit() {
+	for _, cooldown := range defaultChatCommandCooldowns {
+		group := cooldown.Commands[0]
+		for _, command := range cooldown.Commands {
+			chatCommandCooldowns.groups[command] = group
+		}
+		chatCommandCooldowns.cooldowns[group] = time.Duration(cooldown.Seconds) * time.Second
+	}
+
+	// The format is a comma-separated list of "command:seconds" pairs, e.g. "roll:5,tags:30"
+	// Setting a command to 0 seconds removes its cooldown
+	cooldownsString := os.Getenv("CHAT_COMMAND_COOLDOWNS")
+	for _, cooldown := range strings.Split(cooldownsString, ",") {
+		cooldown = strings.TrimSpace(cooldown)
+		if cooldown == "" {
+			continue
+		}
+
+		parts := strings.Split(cooldown, ":")
+		if len(parts) != 2 {
+			logger.Fatal("The chat command cooldown of \"" + cooldown + "\" is not in the format " +
+				"of \"command:seconds\".")
+			return
+		}
+		command := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(parts[0]), "/"))
+		var seconds int
+		if v, err := strconv.Atoi(strings.TrimSpace(parts[1])); err != nil {
+			logger.Fatal("The chat command cooldown of \"" + cooldown + "\" does not have a valid " +
+				"number of seconds.")
+			return
+		} else {
+			seconds = v
+		}
+
+		group, ok := chatCommandCooldowns.groups[command]
+		if !ok {
+			group = command
+			chatCommandCooldowns.groups[command] = group
+		}
+		chatCommandCooldowns.cooldowns[group] = time.Duration(seconds) * time.Second
+	}
+}
+
+// Check sees if the user is allowed to use the command again
+// If they are, the time is recorded and true is returned
+// Otherwise, the remaining time is returned
+func (cc *ChatCommandCooldowns) Check(userID int, command string) (time.Duration, bool) {
+	// This map is not modified after initialization
+	group, ok := cc.groups[command]
+	if !ok {
+		return 0, true
+	}
+	cooldown := cc.cooldowns[group]
+	if cooldown <= 0 {
+		return 0, true
+	}
+
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+
+	lastUsed, ok := cc.lastUsed[userID]
+	if !ok {
+		lastUsed = make(map[string]time.Time)
+		cc.lastUsed[userID] = lastUsed
+	}
+
+	// Discard the commands that are no longer on cooldown
+	// (for every group, so that the map does not grow forever)
+	now := time.Now()
+	for group2, datetimeUsed := range lastUsed {
+		if now.Sub(datetimeUsed) >= cc.cooldowns[group2] {
+			delete(lastUsed, group2)
+		}
+	}
+
+	if datetimeUsed, ok := lastUsed[group]; ok {
+		return cooldown - now.Sub(datetimeUsed), false
+	}
+
+	lastUsed[group] = now
+	return 0, true
+}
+
+// chatCommandCooldownCheck returns false if the message is a command that is on cooldown
+// Server messages and messages from Discord are exempt
+func chatCommandCooldownCheck(s *Session, d *CommandData, userID int) bool {
+	if s == nil || d.Server || d.Discord || !strings.HasPrefix(d.Msg, "/") {
+		return true
+	}
+
+	command := strings.ToLower(strings.TrimPrefix(strings.Fields(d.Msg)[0], "/"))
+	command = chatResolveAlias(command)
+	remaining, ok := chatCommandCooldowns.Check(userID, command)
+	if ok {
+		return true
+	}
+
+	seconds := int(math.Ceil(remaining.Seconds()))
+	if seconds == 1 {
+		chatServerSendPM(s, ChatMsgCooldownOne, d.Room, command)
+	} else {
+		chatServerSendPM(s, ChatMsgCooldown, d.Room, seconds, command)
+	}
+	return false
+}
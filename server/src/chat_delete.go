@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+// ChatDeleteMessage is sent to clients when a message has been removed by a moderator
+type ChatDeleteMessage struct {
+	ID   string `json:"id"`
+	Room string `json:"room"`
+}
+
+// /deletemsg [id]
+func chatDeleteMsg(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if !s.Moderator {
+		chatServerSendPM(s, ChatMsgNotMod, d.Room)
+		return
+	}
+
+	if len(d.Args) != 1 {
+		msg := "The format of the /deletemsg command is: /deletemsg [id]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+	messageID := d.Args[0]
+
+	// Remove the message from the in-memory chat, if any
+	deletedMsg := ""
+	foundInMemory := false
+	if t != nil {
+		deletedMsg, foundInMemory = t.DeleteChatMessage(messageID)
+	}
+
+	// Remove the message from the database, if any
+	// (table messages are written to the database as they are sent, so they can be in both places)
+	// (the review comments in a replay are stored in a different room, in "chat_review.go")
+	room := d.Room
+	if t != nil && t.GetReviewRoomName() != "" {
+		room = t.GetReviewRoomName()
+	}
+	var foundInDatabase bool
+	if v1, v2, err := models.ChatLog.Delete(messageID, room); err != nil {
+		logger.Error("Failed to delete chat message \"" + messageID + "\": " + err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else {
+		foundInDatabase = v1
+		if !foundInMemory {
+			deletedMsg = v2
+		}
+	}
+
+	if !foundInMemory && !foundInDatabase {
+		chatServerSendPM(s, ChatMsgMessageNotFound, d.Room, messageID)
+		return
+	}
+
+	if foundInDatabase {
+		if err := models.ChatLogReactions.DeleteAll(messageID); err != nil {
+			logger.Error("Failed to delete the reactions for chat message \"" + messageID + "\": " +
+				err.Error())
+			// Do not return on failed reaction deletion, since the message itself is already gone
+		}
+	}
+
+	logger.Info("Moderator \"" + s.Username + "\" deleted chat message \"" + messageID + "\" " +
+		"from room \"" + d.Room + "\": " + deletedMsg)
+
+	chatDeleteMessage := &ChatDeleteMessage{
+		ID:   messageID,
+		Room: d.Room,
+	}
+	if t == nil {
+		// Lobby messages go to everyone
+		for _, s2 := range sessions.GetList() {
+			s2.Emit("chatDelete", chatDeleteMessage)
+		}
+	} else {
+		t.NotifyChatDelete(chatDeleteMessage)
+	}
+
+	chatServerSendPM(s, ChatMsgMessageDeleted, d.Room)
+}
+
+// DeleteChatMessage removes a message from the in-memory chat of the table
+// It returns the text of the message and false if it was not found
+func (t *Table) DeleteChatMessage(messageID string) (string, bool) {
+	for i, chatMsg := range t.Chat {
+		if chatMsg.ID != messageID {
+			continue
+		}
+
+		t.Chat = append(t.Chat[:i], t.Chat[i+1:]...)
+		if t.PinnedMessageID == messageID {
+			t.PinnedMessageID = ""
+		}
+
+		// Users that have read past the deleted message now have one less message read
+		for userID, numRead := range t.ChatRead {
+			if numRead > i {
+				t.ChatRead[userID] = numRead - 1
+			}
+		}
+
+		return chatMsg.Msg, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+const (
+	// Users can only edit messages that they sent recently
+	ChatEditGracePeriod = 60 * time.Second
+)
+
+// ChatEditMessage is sent to clients when a message has been edited
+type ChatEditMessage struct {
+	ID       string    `json:"id"`
+	Msg      string    `json:"msg"`
+	Who      string    `json:"who"`
+	Datetime time.Time `json:"datetime"` // The time that the original message was sent
+	Room     string    `json:"room"`
+}
+
+// /edit [msg]
+func chatEdit(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if len(d.Args) == 0 {
+		msg := "The format of the /edit command is: /edit [msg]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	// The message was already sanitized and escaped in the "commandChat()" function
+	newMsg := strings.Join(d.Args, " ")
+
+	if t == nil {
+		chatEditLobby(s, d, newMsg)
+	} else {
+		chatEditTable(s, d, t, newMsg)
+	}
+}
+
+func chatEditLobby(s *Session, d *CommandData, newMsg string) {
+	var exists bool
+	var messageID string
+	var datetimeSent time.Time
+	if v1, v2, v3, err := models.ChatLog.GetLast(s.UserID, d.Room); err != nil {
+		logger.Error("Failed to get the last chat message for user \"" + s.Username + "\": " +
+			err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else {
+		exists = v1
+		messageID = v2
+		datetimeSent = v3
+	}
+
+	if !exists {
+		chatServerSendPM(s, ChatMsgEditNone, d.Room)
+		return
+	}
+
+	if time.Since(datetimeSent) > ChatEditGracePeriod {
+		chatServerSendPM(s, ChatMsgEditTooOld, d.Room, int(ChatEditGracePeriod.Seconds()))
+		return
+	}
+
+	if err := chatEditLobbySend(messageID, newMsg, s.Username, datetimeSent, d.Room); err != nil {
+		logger.Error("Failed to update chat message \"" + messageID + "\" for user " +
+			"\"" + s.Username + "\": " + err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	}
+}
+
+// chatEditLobbySend replaces the text of a lobby message and lets everyone know
+// (this is also used for the messages that were edited on Discord, in "discord_messages.go")
+func chatEditLobbySend(
+	messageID string,
+	newMsg string,
+	who string,
+	datetimeSent time.Time,
+	room string,
+) error {
+	// The mentions and formatting are filled in when the message is read from the database
+	// (in the same way as in the "chat()" function)
+	if err := models.ChatLog.UpdateMessage(messageID, newMsg); err != nil {
+		return err
+	}
+
+	// Lobby messages go to everyone
+	chatEditMessage := &ChatEditMessage{
+		ID:       messageID,
+		Msg:      chatFillAll(newMsg),
+		Who:      who,
+		Datetime: datetimeSent,
+		Room:     room,
+	}
+	for _, s2 := range sessions.GetList() {
+		s2.Emit("chatEdit", chatEditMessage)
+	}
+
+	return nil
+}
+
+func chatEditTable(s *Session, d *CommandData, t *Table, newMsg string) {
+	// Find the most recent message that this user sent to the table
+	var chatMsg *TableChatMessage
+	for i := len(t.Chat) - 1; i >= 0; i-- {
+		if t.Chat[i].UserID == s.UserID && !t.Chat[i].Server {
+			chatMsg = t.Chat[i]
+			break
+		}
+	}
+
+	if chatMsg == nil {
+		chatServerSendPM(s, ChatMsgEditNone, d.Room)
+		return
+	}
+
+	if time.Since(chatMsg.Datetime) > ChatEditGracePeriod {
+		chatServerSendPM(s, ChatMsgEditTooOld, d.Room, int(ChatEditGracePeriod.Seconds()))
+		return
+	}
+
+	chatEditTableSend(t, chatMsg, newMsg, d.Room)
+}
+
+// chatEditTableSend replaces the text of a table message and lets everyone at the table know
+// (this is also used for the messages that were edited on Discord, in "discord_messages.go")
+func chatEditTableSend(t *Table, chatMsg *TableChatMessage, newMsg string, room string) {
+	// The original send time is intentionally kept
+	chatMsg.Msg = newMsg
+	if err := models.ChatLog.UpdateMessage(chatMsg.ID, newMsg); err != nil {
+		logger.Error("Failed to update chat message \"" + chatMsg.ID + "\" from " +
+			"\"" + chatMsg.Username + "\": " + err.Error())
+		// Do not return on a failed update, since the message is still stored in memory
+	}
+
+	t.NotifyChatEdit(&ChatEditMessage{
+		ID:       chatMsg.ID,
+		Msg:      chatFillAll(newMsg),
+		Who:      chatMsg.Username,
+		Datetime: chatMsg.Datetime,
+		Room:     room,
+	})
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"html"
+	"strings"
+)
+
+// /everyone [message]
+// Sends a message that is highlighted for (and plays a sound for) everyone who is seated at the
+// table, e.g. for a ready check
+// Spectators see the message too, but they are not pinged
+// In the lobby, this would ping the entire server, so only moderators can use it there
+// This is rate-limited with the chat command cooldowns (in "chat_cooldown.go")
+func chatEveryone(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if t == nil {
+		if !s.Moderator {
+			chatServerSendPM(s, ChatMsgNotMod, d.Room)
+			return
+		}
+	} else {
+		if s.UserID != t.OwnerID {
+			chatServerSendPM(s, "Only the table owner can use the /everyone command.", d.Room)
+			return
+		}
+
+		if t.Replay {
+			chatServerSendPM(s, "You cannot use the /everyone command in a replay.", d.Room)
+			return
+		}
+	}
+
+	// The message was already escaped, but it will be escaped again when it is sent for real
+	msg := html.UnescapeString(strings.Join(d.Args, " "))
+	if strings.TrimSpace(msg) == "" {
+		chatServerSendPM(s, "The format of the /everyone command is: /everyone [message]", d.Room)
+		return
+	}
+
+	commandChat(ctx, s, &CommandData{ // nolint: exhaustivestruct
+		Msg:          msg,
+		Room:         d.Room,
+		HighlightAll: true,
+		// If this is a table, it is already locked
+		NoTableLock:  true,
+		NoTablesLock: d.NoTablesLock,
+	})
+}
@@ -0,0 +1,122 @@
+// Feedback (e.g. a bug report) can be left from the chat with the "/feedback" command,
+// so that it is not lost in the lobby chat history
+// It is stored in the "chat_feedback" table, sent to the moderators who are online,
+// and optionally forwarded to the Discord channel in the "DISCORD_CHANNEL_FEEDBACK" environment
+// variable
+// Feedback is rate-limited by the "/feedback" entry in "chat_cooldown.go"
+
+package main
+
+import (
+	"context"
+	"html"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+const (
+	// By default, the /feedbacklist command shows the last 10 entries
+	FeedbackListDefaultCount = 10
+	FeedbackListMaxCount     = 50
+)
+
+var (
+	discordChannelFeedback string
+)
+
+func chatFeedbackInit() {
+	discordChannelFeedback = os.Getenv("DISCORD_CHANNEL_FEEDBACK")
+}
+
+// /feedback [message]
+func chatFeedback(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	// The message was already escaped when it was sent
+	message := strings.TrimSpace(strings.Join(d.Args, " "))
+	if message == "" {
+		msg := "The format of the /feedback command is: /feedback [message]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	if err := models.ChatFeedback.Insert(s.UserID, d.Room, message); err != nil {
+		logger.Error("Failed to insert the feedback from user \"" + s.Username + "\": " +
+			err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	}
+
+	logger.Info("User \"" + s.Username + "\" left feedback from room \"" + d.Room + "\": " +
+		message)
+
+	// Let the moderators who are online know about the feedback in the lobby
+	roomName := "the lobby"
+	if t != nil {
+		roomName = "table \"" + html.EscapeString(t.Name) + "\""
+	}
+	msg := s.Username + " left feedback from " + roomName + ": " + message
+	for _, s2 := range sessions.GetList() {
+		if s2.Moderator && s2.UserID != s.UserID {
+			chatServerSendPM(s2, msg, "lobby")
+		}
+	}
+
+	if discordChannelFeedback != "" {
+		discordSend(discordChannelFeedback, "", "Feedback from **"+s.Username+"**: "+
+			html.UnescapeString(message))
+	}
+
+	chatServerSendPM(s, "Thank you. Your feedback has been sent to the moderators.", d.Room)
+}
+
+// /feedbacklist [count]
+func chatFeedbackList(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if !s.Moderator {
+		chatServerSendPM(s, ChatMsgNotMod, d.Room)
+		return
+	}
+
+	if len(d.Args) > 1 {
+		msg := "The format of the /feedbacklist command is: /feedbacklist [count]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	count := FeedbackListDefaultCount
+	if len(d.Args) == 1 {
+		if v, err := strconv.Atoi(d.Args[0]); err != nil || v < 1 {
+			chatServerSendPM(s, "The count of \""+d.Args[0]+"\" is not a positive number.", d.Room)
+			return
+		} else if v > FeedbackListMaxCount {
+			count = FeedbackListMaxCount
+		} else {
+			count = v
+		}
+	}
+
+	var feedback []FeedbackRow
+	if v, err := models.ChatFeedback.GetRecent(count); err != nil {
+		logger.Error("Failed to get the recent feedback: " + err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else {
+		feedback = v
+	}
+
+	if len(feedback) == 0 {
+		chatServerSendPM(s, "No-one has left any feedback yet.", d.Room)
+		return
+	}
+
+	chatServerSendPM(s, "The last "+strconv.Itoa(len(feedback))+" feedback entries:", d.Room)
+
+	// The entries are stored from newest to oldest, but we want to show the newest at the bottom
+	// (the messages were already HTML-escaped before they were stored in the database)
+	for i := len(feedback) - 1; i >= 0; i-- {
+		row := feedback[i]
+		chatServerSendPM(s, "["+s.FormatTimestamp(row.Datetime)+"] ["+row.Room+"] "+
+			row.Username+": "+row.Message, d.Room)
+	}
+}
@@ -0,0 +1,81 @@
+// Spammers often send the exact same message over and over,
+// so we suppress a message if it is identical to the last few that the user sent
+// (this is separate from the rate-limiting in "chat_rate_limit.go",
+// so that a user can say the same thing a couple of times without being penalized)
+
+package main
+
+import (
+	"time"
+
+	"github.com/sasha-s/go-deadlock"
+)
+
+const (
+	// By default, a user can send the same message up to 3 times in a row within 30 seconds
+	DefaultChatFloodRepeats = 3
+	DefaultChatFloodSeconds = 30
+)
+
+var (
+	chatFloodRepeats  int
+	chatFloodWindow   time.Duration
+	chatFloodDetector = NewChatFloodDetector()
+)
+
+type ChatFloodDetector struct {
+	lastMessages map[int]*ChatFloodLastMessage // Indexed by user ID
+	mutex        *deadlock.Mutex
+}
+
+type ChatFloodLastMessage struct {
+	Msg         string
+	RepeatCount int
+	// The time that the first message in the current streak was sent
+	DatetimeFirst time.Time
+}
+
+func NewChatFloodDetector() *ChatFloodDetector {
+	return &ChatFloodDetector{
+		lastMessages: make(map[int]*ChatFloodLastMessage),
+		mutex:        &deadlock.Mutex{},
+	}
+}
+
+func chatFloodInit() {
+	chatFloodRepeats = getEnvInt("CHAT_FLOOD_REPEATS", DefaultChatFloodRepeats)
+	chatFloodSeconds := getEnvInt("CHAT_FLOOD_SECONDS", DefaultChatFloodSeconds)
+	chatFloodWindow = time.Duration(chatFloodSeconds) * time.Second
+}
+
+// Check returns false if the message is a duplicate that should be suppressed
+// The comparison is made against the last message that the user sent in any room,
+// so that spammers cannot get around it by alternating between the lobby and a table
+func (fd *ChatFloodDetector) Check(userID int, msg string) bool {
+	// A value of 0 or less disables flood protection
+	if chatFloodRepeats <= 0 || chatFloodWindow <= 0 {
+		return true
+	}
+
+	fd.mutex.Lock()
+	defer fd.mutex.Unlock()
+
+	now := time.Now()
+	lastMessage, ok := fd.lastMessages[userID]
+	if !ok || lastMessage.Msg != msg || now.Sub(lastMessage.DatetimeFirst) >= chatFloodWindow {
+		// This is the start of a new streak
+		fd.lastMessages[userID] = &ChatFloodLastMessage{
+			Msg:           msg,
+			RepeatCount:   1,
+			DatetimeFirst: now,
+		}
+		return true
+	}
+
+	if lastMessage.RepeatCount >= chatFloodRepeats {
+		return false
+	}
+
+	lastMessage.RepeatCount++
+	return true
+}
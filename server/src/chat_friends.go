@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+// /friends
+func chatFriends(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	var friends []User
+	if v, err := models.UserFriends.GetAll(s.UserID); err != nil {
+		logger.Error("Failed to get the friends for user \"" + s.Username + "\": " + err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else {
+		friends = v
+	}
+
+	if len(friends) == 0 {
+		chatServerSendPM(s, ChatMsgFriendsNone, d.Room)
+		return
+	}
+
+	// Online friends are listed first (the friends are already sorted by username)
+	online := make([]string, 0)
+	offline := make([]string, 0)
+	for _, friend := range friends {
+		if s2, ok := sessions.Get(friend.ID); ok {
+			status := chatLocalize(s.Language, chatFriendsStatus(s2.Status()))
+			online = append(online, friend.Username+" ("+status+")")
+		} else {
+			offline = append(offline, friend.Username)
+		}
+	}
+
+	if len(online) > 0 {
+		chatServerSendPM(s, ChatMsgFriendsOnline, d.Room, strings.Join(online, ", "))
+	} else {
+		chatServerSendPM(s, ChatMsgFriendsNoneOnline, d.Room)
+	}
+	if len(offline) > 0 {
+		chatServerSendPM(s, ChatMsgFriendsOffline, d.Room, strings.Join(offline, ", "))
+	}
+}
+
+// chatFriendsStatus returns the key of the localized message for a status
+func chatFriendsStatus(status int) string {
+	switch status {
+	case StatusPregame:
+		return ChatMsgFriendStatusPregame
+	case StatusPlaying:
+		return ChatMsgFriendStatusPlaying
+	case StatusSpectating:
+		return ChatMsgFriendStatusSpectate
+	case StatusReplay, StatusSharedReplay:
+		return ChatMsgFriendStatusReplay
+	default:
+		return ChatMsgFriendStatusLobby
+	}
+}
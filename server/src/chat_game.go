@@ -1,34 +1,52 @@
 package main
 
-/*
-// /pause
+import (
+	"context"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+const (
+	// The reason is shown in the chat message that announces the pause
+	ChatPauseReasonMaxLength = 150
+)
+
+// /pause [reason]
 func chatPause(ctx context.Context, s *Session, d *CommandData, t *Table) {
-	if t == nil || d.Room == "lobby" {
-		chatServerSend(ctx, NotInGameFail, "lobby", d.NoTablesLock)
+	if !chatPauseValidate(s, d, t) {
 		return
 	}
 
-	if !t.Running {
-		chatServerSend(ctx, NotStartedFail, d.Room, d.NoTablesLock)
+	if t.Game.Paused {
+		chatServerSendPM(s, "The game is already paused.", d.Room)
 		return
 	}
 
+	// The arguments were already sanitized and escaped in the "commandChat()" function,
+	// but the reason is truncated without the escaping so that an entity is never cut in half
+	// (it is escaped again in the "commandPause()" function)
+	reason := html.UnescapeString(strings.Join(d.Args, " "))
+	reason = truncateRunes(reason, ChatPauseReasonMaxLength)
+
 	commandPause(ctx, s, &CommandData{ // nolint: exhaustivestruct
 		TableID:     t.ID,
 		Setting:     "pause",
+		PauseReason: reason,
 		NoTableLock: true,
 	})
 }
 
 // /unpause
 func chatUnpause(ctx context.Context, s *Session, d *CommandData, t *Table) {
-	if t == nil || d.Room == "lobby" {
-		chatServerSend(ctx, NotInGameFail, "lobby", d.NoTablesLock)
+	if !chatPauseValidate(s, d, t) {
 		return
 	}
 
-	if !t.Running {
-		chatServerSend(ctx, NotStartedFail, d.Room, d.NoTablesLock)
+	if !t.Game.Paused {
+		chatServerSendPM(s, "The game is not paused, so you cannot unpause.", d.Room)
 		return
 	}
 
@@ -38,4 +56,86 @@ func chatUnpause(ctx context.Context, s *Session, d *CommandData, t *Table) {
 		NoTableLock: true,
 	})
 }
-*/
+
+// chatPauseValidate performs the same validation as the "commandPause()" function,
+// but reports the problem in the chat instead of with a warning
+func chatPauseValidate(s *Session, d *CommandData, t *Table) bool {
+	if t == nil {
+		chatServerSendPM(s, ChatMsgNotInGame, d.Room)
+		return false
+	}
+
+	if !t.Running || t.Replay {
+		chatServerSendPM(s, "You can only pause or unpause a game that is in progress.", d.Room)
+		return false
+	}
+
+	// Spectators cannot pause
+	if t.GetPlayerIndexFromID(s.UserID) == -1 {
+		chatServerSendPM(s, "Only the players can pause or unpause the game.", d.Room)
+		return false
+	}
+
+	if !t.Options.Timed {
+		chatServerSendPM(s, "This is not a timed game, so you cannot pause / unpause.", d.Room)
+		return false
+	}
+
+	return true
+}
+
+// chatPauseRecord stores the ID of the chat message that was just sent to announce a pause
+// It is assumed that the table mutex is locked when calling this function
+func chatPauseRecord(t *Table) {
+	g := t.Game
+	g.PauseChatID = ""
+	if len(t.Chat) > 0 {
+		if chatMsg := t.Chat[len(t.Chat)-1]; chatMsg.Server {
+			g.PauseChatID = chatMsg.ID
+		}
+	}
+}
+
+// chatPauseResolve annotates the chat message that announced the pause with how long the game was
+// paused for, so that it is clear that the pause is over
+// It is assumed that the table mutex is locked when calling this function
+func chatPauseResolve(t *Table) {
+	g := t.Game
+	if g.PauseChatID == "" {
+		return
+	}
+
+	var chatMsg *TableChatMessage
+	for i := len(t.Chat) - 1; i >= 0; i-- {
+		if t.Chat[i].ID == g.PauseChatID {
+			chatMsg = t.Chat[i]
+			break
+		}
+	}
+	g.PauseChatID = ""
+	if chatMsg == nil {
+		// The chat might have been cleared in the meantime
+		return
+	}
+
+	annotation := " (resolved)"
+	seconds := int(time.Since(g.DatetimePaused).Round(time.Second).Seconds())
+	if v, err := secondsToDurationString(seconds); err == nil {
+		annotation = " (resumed after " + v + ")"
+	}
+
+	// This is a server message, so it is stored as HTML (instead of escaped)
+	chatMsg.Msg += annotation
+	if err := models.ChatLog.UpdateMessage(chatMsg.ID, chatMsg.Msg); err != nil {
+		logger.Error("Failed to update chat message \"" + chatMsg.ID + "\": " + err.Error())
+		// Do not return on a failed update, since the message is still stored in memory
+	}
+
+	t.NotifyChatEdit(&ChatEditMessage{
+		ID:       chatMsg.ID,
+		Msg:      chatMsg.GetFilledMsg(), // The same text as when the message was first sent
+		Who:      chatMsg.Username,
+		Datetime: chatMsg.Datetime,
+		Room:     t.GetRoomName(),
+	})
+}
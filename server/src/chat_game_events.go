@@ -0,0 +1,165 @@
+// For accessibility and for spectators, tables can optionally echo the important game events
+// (clues, strikes, and the end of the game) to the table chat with the "/gameevents" command
+// These are off by default, since they would clutter the chat for most games
+
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+var (
+	// By default, the events are only sent to the people at the table at the time
+	chatGameEventsSaveToDatabase bool
+)
+
+func chatGameEventsInit() {
+	chatGameEventsSaveToDatabase = getEnvInt("CHAT_GAME_EVENTS_SAVE_TO_DATABASE", 0) != 0
+}
+
+// /gameevents [on/off]
+func chatGameEvents(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if t == nil {
+		chatServerSendPM(s, ChatMsgNotInGame, d.Room)
+		return
+	}
+
+	if s.UserID != t.OwnerID && !s.Moderator {
+		msg := "Only the table owner or a moderator can change whether game events are shown."
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	if len(d.Args) == 0 {
+		msg := "Game events are currently "
+		if t.ChatGameEvents {
+			msg += "on"
+		} else {
+			msg += "off"
+		}
+		msg += ". The format of the /gameevents command is: /gameevents [on/off]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	switch strings.ToLower(d.Args[0]) {
+	case "on":
+		t.ChatGameEvents = true
+	case "off":
+		t.ChatGameEvents = false
+	default:
+		msg := "The format of the /gameevents command is: /gameevents [on/off]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	msg := s.Username + " turned game events " + strings.ToLower(d.Args[0]) + "."
+	chatServerSend(ctx, msg, d.Room, d.NoTablesLock)
+}
+
+// chatGameEventSend echoes a game action to the table chat, if it is an important one
+// This is called from the "NotifyGameAction()" function, which can be called while the tables
+// mutex is locked, so the message is sent directly instead of going through the
+// "commandChat()" function
+// It is assumed that the table mutex is locked when calling this function
+func chatGameEventSend(t *Table, action interface{}) {
+	if !t.ChatGameEvents || t.Replay {
+		return
+	}
+
+	msg := chatGameEventFormat(t, action)
+	if msg == "" {
+		return
+	}
+
+	chatMsg := &TableChatMessage{
+		ID:        newChatMessageID(),
+		UserID:    0,
+		Username:  "",
+		Msg:       msg,
+		Datetime:  time.Now(),
+		Server:    true,
+		Discord:   false,
+		Reactions: make(map[string][]int),
+		ReplyTo:   "",
+		Role:      "",
+	}
+
+	// Like the countdown before an automatic start,
+	// events that are kept out of the database are not kept in memory either
+	if chatGameEventsSaveToDatabase {
+		t.Chat = append(t.Chat, chatMsg)
+		if err := models.ChatLog.Insert(chatMsg.ID, 0, msg, t.GetRoomName(), ""); err != nil {
+			logger.Error("Failed to insert a game event into the database: " + err.Error())
+			// Do not return on failed chat insertion,
+			// since the message is still stored in memory
+		}
+	}
+
+	t.NotifyChat(chatMsg.ToChatMessage(t))
+}
+
+// chatGameEventFormat returns a blank string for the actions that should not be echoed
+func chatGameEventFormat(t *Table, action interface{}) string {
+	g := t.Game
+	variant := variants[t.Options.VariantName]
+
+	switch a := action.(type) {
+	case ActionClue:
+		giver := g.Players[a.Giver].Name
+		target := g.Players[a.Target].Name
+		touched := "touching " + strconv.Itoa(len(a.List)) + " cards"
+		if len(a.List) == 0 {
+			touched = "touching no cards"
+		} else if len(a.List) == 1 {
+			touched = "touching 1 card"
+		}
+
+		// Some variants do not reveal what the clue was
+		if variant.IsDuck() {
+			return giver + " clued " + target + ", " + touched + "."
+		}
+		var clue string
+		if a.Clue.Type == ClueTypeColor {
+			if a.Clue.Value < 0 || a.Clue.Value >= len(variant.ClueColors) {
+				return ""
+			}
+			clue = variant.ClueColors[a.Clue.Value]
+		} else {
+			clue = strconv.Itoa(a.Clue.Value)
+		}
+		if variant.IsCowAndPig() {
+			if a.Clue.Type == ClueTypeColor {
+				clue = "a color clue"
+			} else {
+				clue = "a rank clue"
+			}
+		}
+		return giver + " clued " + target + " with " + clue + ", " + touched + "."
+
+	case ActionStrike:
+		return g.Players[g.ActivePlayerIndex].Name + " misplayed a card (strike " +
+			strconv.Itoa(a.Num) + " of " + strconv.Itoa(MaxStrikeNum) + ")."
+
+	case ActionGameOver:
+		score := "The final score is " + strconv.Itoa(g.Score) + " out of " +
+			strconv.Itoa(g.MaxScore) + "."
+		switch a.EndCondition {
+		case EndConditionNormal:
+			return "The game is over. " + score
+		case EndConditionStrikeout:
+			return "The game is over because of the strikes. " + score
+		case EndConditionTimeout:
+			return "The game is over because a player ran out of time. " + score
+		default:
+			return "The game was ended early. " + score
+		}
+	}
+
+	return ""
+}
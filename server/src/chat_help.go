@@ -0,0 +1,615 @@
+// The "/help" command lists the chat commands (or shows the details of a specific one) so that
+// users do not have to look them up in "docs/CHAT_COMMANDS.md"
+// Moderator commands are only listed for moderators
+
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+type ChatCommandHelp struct {
+	Name string
+	// The other names that can be used for the same command (not including the aliases that are
+	// configured with the "CHAT_COMMAND_ALIASES" environment variable)
+	Aliases     []string
+	Usage       string // e.g. "[username] [msg]"
+	Description string
+	Category    string
+	Moderator   bool
+}
+
+const (
+	ChatHelpCategoryGeneral   = "General"
+	ChatHelpCategoryModerator = "Moderator"
+	ChatHelpCategoryPregame   = "Pre-game"
+	ChatHelpCategoryTable     = "Table"
+	ChatHelpCategoryGame      = "Game"
+	ChatHelpCategoryReplay    = "Replay"
+)
+
+var (
+	chatHelpCategories = []string{
+		ChatHelpCategoryGeneral,
+		ChatHelpCategoryPregame,
+		ChatHelpCategoryTable,
+		ChatHelpCategoryGame,
+		ChatHelpCategoryReplay,
+		ChatHelpCategoryModerator,
+	}
+
+	// Commands that are missing from here still work, but they are not shown by "/help"
+	chatCommandHelp = []ChatCommandHelp{
+		{
+			Name:        "help",
+			Aliases:     []string{"commands", "?"},
+			Usage:       "[command]",
+			Description: "List the chat commands, or show the details of a specific one",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "discord",
+			Description: "Get the link for the Discord server",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "manual",
+			Aliases:     []string{"features"},
+			Description: "Get the link for the Hanab Live Manual & List of Features",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "rules",
+			Aliases:     []string{"community", "guidelines"},
+			Description: "Get the link for the Community Guidelines",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "new",
+			Aliases:     []string{"beginner", "beginners", "guide"},
+			Description: "Get the link for the H-Group beginner's guide",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "doc",
+			Aliases:     []string{"document", "reference"},
+			Description: "Get the link for the H-Group reference document",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "path",
+			Aliases:     []string{"levels", "level", "learningpath", "learning"},
+			Description: "Get the link for the H-Group level summary",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "bga",
+			Description: "Get the link for the Board Game Arena transition guide",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "efficiency",
+			Description: "Get the link for the efficiency document",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:    "playerinfo",
+			Aliases: []string{"p", "games", "stats"},
+			Usage:   "[username1] [username2]",
+			Description: "Get the number of games played for a list of players " +
+				"(or for all the players in the current game)",
+			Category: ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "replay",
+			Usage:       "[game ID] [turn]",
+			Description: "Generate a link to a replay so that you can share it with others",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "random",
+			Usage:       "[min] [max]",
+			Description: "Get a random integer",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "uptime",
+			Description: "Get how long the server has been online",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "timeleft",
+			Description: "Get how much time is left before the server shuts down",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "me",
+			Usage:       "[action]",
+			Description: "Send an action message (e.g. \"/me waves\")",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "pm",
+			Aliases:     []string{"w", "msg", "tell", "t"},
+			Usage:       "[username] [msg]",
+			Description: "Send a private message",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "friend",
+			Aliases:     []string{"addfriend"},
+			Usage:       "[username]",
+			Description: "Add someone to your friends list",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "unfriend",
+			Usage:       "[username]",
+			Description: "Remove someone from your friends list",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "friends",
+			Aliases:     []string{"f", "friendlist", "friendslist"},
+			Description: "Show a list of all your friends (and which of them are online)",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "tagsearch",
+			Usage:       "[tag]",
+			Description: "Search through all games for a specific tag",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "version",
+			Description: "Show the version number of the client code",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "edit",
+			Usage:       "[msg]",
+			Description: "Edit the last message that you sent (within 60 seconds)",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "whisper",
+			Usage:       "[username] [msg]",
+			Description: "Send a private message to someone at the same table",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "afk",
+			Usage:       "[reason]",
+			Description: "Mark yourself as away until you send your next message",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "ignore",
+			Usage:       "[username]",
+			Description: "Hide all messages from someone (except for moderator messages)",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "unignore",
+			Usage:       "[username]",
+			Description: "Stop hiding messages from someone",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "ignorelist",
+			Description: "Show the list of people that you are ignoring",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "lastseen",
+			Usage:       "[username]",
+			Description: "Show how long ago someone was last online",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "stats",
+			Usage:       "[username]",
+			Description: "Show the stats of someone (or yourself)",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "search",
+			Usage:       "[terms]",
+			Description: "Show the last messages in this room that contain all of the terms",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "report",
+			Usage:       "[id] [reason]",
+			Description: "Report a chat message to the moderators",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "feedback",
+			Usage:       "[message]",
+			Description: "Leave feedback (e.g. a bug report) for the moderators",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "translate",
+			Usage:       "[id]",
+			Description: "Translate a chat message into your language",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "variantinfo",
+			Usage:       "[variant]",
+			Description: "Show a summary of the rules of a variant",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "who",
+			Description: "Show how many people are online",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "unread",
+			Description: "List the tables that have unread messages",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:  "everyone",
+			Usage: "[msg]",
+			Description: "Highlight a message for everyone who is playing at the table " +
+				"(table owner only)",
+			Category: ChatHelpCategoryGeneral,
+		},
+		{
+			Name:  "topic",
+			Usage: "[text]",
+			Description: "Set the topic of the room (e.g. the house rules), or clear it without any " +
+				"text (table owner only)",
+			Category: ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "highlight",
+			Usage:       "[word]",
+			Description: "Be notified whenever someone says a word",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "unhighlight",
+			Usage:       "[word]",
+			Description: "Stop being notified when someone says a word",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "highlights",
+			Description: "List the words that you are highlighting",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "tz",
+			Usage:       "[timezone]",
+			Description: "Show times from the server in your timezone (e.g. \"/tz America/New_York\")",
+			Category:    ChatHelpCategoryGeneral,
+		},
+		{
+			Name:        "setvariant",
+			Aliases:     []string{"sv", "changevariant", "cv"},
+			Usage:       "[variant]",
+			Description: "Change the variant of the current game (table owner only)",
+			Category:    ChatHelpCategoryPregame,
+		},
+		{
+			Name: "s",
+			Description: "Automatically start the game when the next person joins " +
+				"(or use /s2 to /s6 for a specific number of players; table owner only)",
+			Category: ChatHelpCategoryPregame,
+		},
+		{
+			Name:    "startin",
+			Aliases: []string{"si"},
+			Usage:   "[minutes]",
+			Description: "Automatically start the game in the provided amount of minutes " +
+				"(table owner only)",
+			Category: ChatHelpCategoryPregame,
+		},
+		{
+			Name:        "kick",
+			Usage:       "[username]",
+			Description: "Remove a player from the table (table owner only)",
+			Category:    ChatHelpCategoryPregame,
+		},
+		{
+			Name: "impostor",
+			Description: "Randomly tell one of the players that they are an impostor " +
+				"(table owner only)",
+			Category: ChatHelpCategoryPregame,
+		},
+		{
+			Name:        "invite",
+			Usage:       "[username]",
+			Description: "Send someone a link that joins the table",
+			Category:    ChatHelpCategoryPregame,
+		},
+		{
+			Name: "missing",
+			Aliases: []string{
+				"m",
+				"missingscores",
+				"missing-scores",
+				"sharedmissingscores",
+				"shared-missing-scores",
+			},
+			Description: "Get the list of every max score that the team is missing",
+			Category:    ChatHelpCategoryPregame,
+		},
+		{
+			Name:        "findvariant",
+			Aliases:     []string{"fv", "find-variant", "randomvariant", "random-variant"},
+			Description: "Find a random variant that everyone needs the max score in",
+			Category:    ChatHelpCategoryPregame,
+		},
+		{
+			Name: "setleader",
+			Aliases: []string{
+				"setlead",
+				"setowner",
+				"changeleader",
+				"changelead",
+				"changeowner",
+			},
+			Description: "Change the owner/leader of the game",
+			Category:    ChatHelpCategoryTable,
+		},
+		{
+			Name:        "pin",
+			Usage:       "[id]",
+			Description: "Pin a message to the top of the chat (table owner only)",
+			Category:    ChatHelpCategoryTable,
+		},
+		{
+			Name:        "clear",
+			Description: "Clear the chat for everyone at the table (table owner only)",
+			Category:    ChatHelpCategoryTable,
+		},
+		{
+			Name:  "slowmode",
+			Usage: "[seconds]",
+			Description: "Only allow everyone at the table to send one message every X seconds " +
+				"(table owner only)",
+			Category: ChatHelpCategoryTable,
+		},
+		{
+			Name:        "mutespectator",
+			Usage:       "[username]",
+			Description: "Stop a spectator from chatting at the table (table owner only)",
+			Category:    ChatHelpCategoryTable,
+		},
+		{
+			Name:        "unmutespectator",
+			Usage:       "[username]",
+			Description: "Allow a muted spectator to chat again (table owner only)",
+			Category:    ChatHelpCategoryTable,
+		},
+		{
+			Name:  "gameevents",
+			Usage: "[on/off]",
+			Description: "Announce clues, strikes, and the end of the game in the chat " +
+				"(table owner only)",
+			Category: ChatHelpCategoryTable,
+		},
+		{
+			Name:  "spectatornotices",
+			Usage: "[on/off]",
+			Description: "Announce when spectators come and go, a few at a time " +
+				"(table owner only)",
+			Category: ChatHelpCategoryTable,
+		},
+		{
+			Name:        "roll",
+			Usage:       "[NdM]",
+			Description: "Roll N dice with M sides each (e.g. \"/roll 2d6\")",
+			Category:    ChatHelpCategoryTable,
+		},
+		{
+			Name:        "pause",
+			Usage:       "[reason]",
+			Description: "Pause a timed game and announce why in the chat (players only)",
+			Category:    ChatHelpCategoryGame,
+		},
+		{
+			Name:        "unpause",
+			Description: "Unpause the game (players only)",
+			Category:    ChatHelpCategoryGame,
+		},
+		{
+			Name:        "spectators",
+			Usage:       "[msg]",
+			Description: "Send a message that only the other spectators can see (spectators only)",
+			Category:    ChatHelpCategoryGame,
+		},
+		{
+			Name:        "tag",
+			Usage:       "[tag]",
+			Description: "Tag this game with a word or phrase so that you can find it later",
+			Category:    ChatHelpCategoryGame,
+		},
+		{
+			Name:        "suggest",
+			Usage:       "[turn]",
+			Description: "Suggest a specific turn for the shared replay leader to go to",
+			Category:    ChatHelpCategoryReplay,
+		},
+		{
+			Name:        "tagdelete",
+			Usage:       "[tag]",
+			Description: "Delete an existing tag from the game",
+			Category:    ChatHelpCategoryReplay,
+		},
+		{
+			Name:        "tagsdeleteall",
+			Description: "Delete all of your tags from the game",
+			Category:    ChatHelpCategoryReplay,
+		},
+		{
+			Name:        "tags",
+			Aliases:     []string{"taglist"},
+			Description: "Show all of the tags for this game",
+			Category:    ChatHelpCategoryReplay,
+		},
+		{
+			Name:        "copy",
+			Description: "Copy the current game to your clipboard in the JSON format",
+			Category:    ChatHelpCategoryReplay,
+		},
+		{
+			Name:  "poll",
+			Usage: "[question] | [option 1] | [option 2]",
+			Description: "Ask the spectators to vote on something, e.g. the best play " +
+				"(table owner only)",
+			Category: ChatHelpCategoryReplay,
+		},
+		{
+			Name:        "publicpoll",
+			Usage:       "[question] | [option 1] | [option 2]",
+			Description: "Start a poll that shows who voted for what when it closes (table owner only)",
+			Category:    ChatHelpCategoryReplay,
+		},
+		{
+			Name:        "closepoll",
+			Description: "Close the poll and show the results (table owner only)",
+			Category:    ChatHelpCategoryReplay,
+		},
+		{
+			Name:        "vote",
+			Usage:       "[number]",
+			Description: "Vote in the poll (or change your vote)",
+			Category:    ChatHelpCategoryReplay,
+		},
+		{
+			Name:        "deletemsg",
+			Usage:       "[id]",
+			Description: "Delete a specific chat message from the current room",
+			Category:    ChatHelpCategoryModerator,
+			Moderator:   true,
+		},
+		{
+			Name:        "motd",
+			Usage:       "[on/off]",
+			Description: "Turn the rotating server messages in the lobby on or off",
+			Category:    ChatHelpCategoryModerator,
+			Moderator:   true,
+		},
+		{
+			Name:        "history",
+			Usage:       "[username] [count]",
+			Description: "Show the last messages that someone sent in any room",
+			Category:    ChatHelpCategoryModerator,
+			Moderator:   true,
+		},
+		{
+			Name:        "bridge",
+			Usage:       "[on/off]",
+			Description: "Turn the replication of the current room to Discord on or off",
+			Category:    ChatHelpCategoryModerator,
+			Moderator:   true,
+		},
+		{
+			Name:        "lockroom",
+			Usage:       "[on/off]",
+			Description: "Make the current room read-only for everyone except for moderators",
+			Category:    ChatHelpCategoryModerator,
+			Moderator:   true,
+		},
+		{
+			Name:        "feedbacklist",
+			Usage:       "[count]",
+			Description: "Show the most recent feedback from the /feedback command",
+			Category:    ChatHelpCategoryModerator,
+			Moderator:   true,
+		},
+	}
+)
+
+// /help [command]
+func chatHelpList(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if len(d.Args) > 0 {
+		chatHelpCommand(s, d, d.Args[0])
+		return
+	}
+
+	for _, category := range chatHelpCategories {
+		names := make([]string, 0)
+		for _, help := range chatCommandHelp {
+			if help.Category == category && (!help.Moderator || s.Moderator) {
+				names = append(names, "/"+help.Name)
+			}
+		}
+		if len(names) > 0 {
+			chatServerSendPM(s, category+" commands: "+strings.Join(names, ", "), d.Room)
+		}
+	}
+
+	macroNames := chatMacros.Names()
+	if len(macroNames) > 0 {
+		for i, name := range macroNames {
+			macroNames[i] = "/" + name
+		}
+		chatServerSendPM(s, "Text macros: "+strings.Join(macroNames, ", "), d.Room)
+	}
+
+	msg := "Use \"/help [command]\" to see the details of a command, or see the full list here: " +
+		"https://github.com/Hanabi-Live/hanabi-live/blob/main/docs/CHAT_COMMANDS.md"
+	chatServerSendPM(s, msg, d.Room)
+}
+
+func chatHelpCommand(s *Session, d *CommandData, name string) {
+	name = strings.ToLower(strings.TrimPrefix(name, "/"))
+	name = chatResolveAlias(name)
+
+	var help *ChatCommandHelp
+	for i := range chatCommandHelp {
+		if chatCommandHelp[i].Name == name || stringInSlice(name, chatCommandHelp[i].Aliases) {
+			help = &chatCommandHelp[i]
+			break
+		}
+	}
+
+	// Moderator commands are hidden from everyone else
+	if help == nil || (help.Moderator && !s.Moderator) {
+		msg := "There is no chat command of \"/" + name + "\". Use \"/help\" to see the list of " +
+			"commands."
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	msg := "/" + help.Name
+	if help.Usage != "" {
+		msg += " " + help.Usage
+	}
+	msg += " - " + help.Description
+
+	aliases := make([]string, 0)
+	for _, alias := range help.Aliases {
+		aliases = append(aliases, "/"+alias)
+	}
+	for _, alias := range chatGetAliasesFor(help.Name, help.Aliases) {
+		aliases = append(aliases, "/"+alias)
+	}
+	if len(aliases) > 0 {
+		msg += " (also: " + strings.Join(aliases, ", ") + ")"
+	}
+
+	chatServerSendPM(s, msg, d.Room)
+}
+
+// chatGetAliasesFor returns the configured aliases for a command (or for any of its other names)
+func chatGetAliasesFor(name string, otherNames []string) []string {
+	aliases := make([]string, 0)
+	for alias, command := range chatCommandAliases {
+		if command == name || stringInSlice(command, otherNames) {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
@@ -0,0 +1,124 @@
+// Users can subscribe to highlight words (e.g. the name of a convention),
+// so that they are notified whenever someone says one of them, like when they are mentioned
+// The words are stored in the "chatHighlightWords" setting (in "chat_notifications.go"),
+// which can also be changed from the "Settings" tooltip in the lobby
+
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// ChatHighlightMessage is sent to a user when someone says one of their highlight words in a chat
+// message
+type ChatHighlightMessage struct {
+	ID   string `json:"id"`
+	Room string `json:"room"`
+	Who  string `json:"who"`
+	Word string `json:"word"`
+	// Highlights use the same sound as mentions
+	Sound bool `json:"sound"`
+}
+
+// /highlight [word]
+func chatHighlight(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	highlight(s, d, true)
+}
+
+// /unhighlight [word]
+func chatUnhighlight(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	highlight(s, d, false)
+}
+
+func highlight(s *Session, d *CommandData, add bool) {
+	// Validate that they sent a single word
+	if len(d.Args) != 1 || strings.Contains(d.Args[0], ",") {
+		var msg string
+		if add {
+			msg = "The format of the /highlight command is: /highlight [word]"
+		} else {
+			msg = "The format of the /unhighlight command is: /unhighlight [word]"
+		}
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+	word := strings.ToLower(d.Args[0])
+
+	words := s.ChatNotifications().HighlightWords
+	index := -1
+	for i, word2 := range words {
+		if word2 == word {
+			index = i
+			break
+		}
+	}
+
+	newWords := make([]string, 0, len(words)+1)
+	if add {
+		if index != -1 {
+			chatServerSendPM(s, "You are already highlighting \""+word+"\".", d.Room)
+			return
+		}
+		newWords = append(newWords, words...)
+		newWords = append(newWords, word)
+	} else {
+		if index == -1 {
+			chatServerSendPM(s, "You are not highlighting \""+word+"\".", d.Room)
+			return
+		}
+		newWords = append(newWords, words[:index]...)
+		newWords = append(newWords, words[index+1:]...)
+	}
+
+	var newSetting string
+	if v, valid := chatValidateHighlightWords(strings.Join(newWords, ",")); !valid {
+		msg := "You can highlight at most " + strconv.Itoa(ChatHighlightWordsMax) + " words, " +
+			"and each of them can only contain letters, numbers, underscores, and hyphens " +
+			"(up to " + strconv.Itoa(ChatHighlightWordMaxLength) + " characters)."
+		chatServerSendPM(s, msg, d.Room)
+		return
+	} else {
+		newSetting = v
+	}
+
+	// This is the same as changing the setting from the lobby
+	if !setting(s, &CommandData{ // nolint: exhaustivestruct
+		Name:    "chatHighlightWords",
+		Setting: newSetting,
+	}) {
+		return
+	}
+
+	var msg string
+	if add {
+		msg = "You will now be notified when someone says \"" + word + "\"."
+	} else {
+		msg = "You will no longer be notified when someone says \"" + word + "\"."
+	}
+	chatServerSendPM(s, msg, d.Room)
+}
+
+// /highlights
+func chatHighlights(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	words := s.ChatNotifications().HighlightWords
+	if len(words) == 0 {
+		chatServerSendPM(s, "You are not highlighting any words.", d.Room)
+		return
+	}
+
+	msg := "You are highlighting: " + strings.Join(words, ", ")
+	chatServerSendPM(s, msg, d.Room)
+}
+
+// chatHighlightSend is called from the "chatNotifyMentions()" function
+func chatHighlightSend(s *Session, d *CommandData, messageID string, word string) {
+	s.Emit("chatHighlight", &ChatHighlightMessage{
+		ID:    messageID,
+		Room:  d.Room,
+		Who:   d.Username,
+		Word:  word,
+		Sound: s.ChatNotifications().SoundMention,
+	})
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+const (
+	// By default, the /history command shows the last 20 messages
+	HistoryDefaultCount = 20
+	HistoryMaxCount     = 100
+)
+
+// /history [username] [count]
+func chatHistory(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if !s.Moderator {
+		chatServerSendPM(s, ChatMsgNotMod, d.Room)
+		return
+	}
+
+	if len(d.Args) < 1 || len(d.Args) > 2 {
+		msg := "The format of the /history command is: /history [username] [count]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+	username := d.Args[0]
+	normalizedUsername := normalizeString(username)
+
+	count := HistoryDefaultCount
+	if len(d.Args) == 2 {
+		if v, err := strconv.Atoi(d.Args[1]); err != nil || v < 1 {
+			chatServerSendPM(s, "The count of \""+d.Args[1]+"\" is not a positive number.", d.Room)
+			return
+		} else if v > HistoryMaxCount {
+			count = HistoryMaxCount
+		} else {
+			count = v
+		}
+	}
+
+	// Validate that this person exists in the database
+	var user User
+	if exists, v, err := models.Users.GetUserFromNormalizedUsername(
+		normalizedUsername,
+	); err != nil {
+		logger.Error("Failed to validate that \"" + normalizedUsername + "\" " +
+			"exists in the database: " + err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else if !exists {
+		chatServerSendPM(s, ChatMsgUserNotFound, d.Room, username)
+		return
+	} else {
+		user = v
+	}
+
+	var msgs []UserChatMessage
+	if v, err := models.ChatLog.GetFromUser(user.ID, count); err != nil {
+		logger.Error("Failed to get the chat history for user \"" + user.Username + "\": " +
+			err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else {
+		msgs = v
+	}
+
+	if len(msgs) == 0 {
+		chatServerSendPM(s, "\""+user.Username+"\" has not sent any messages.", d.Room)
+		return
+	}
+
+	chatServerSendPM(s, "The last "+strconv.Itoa(len(msgs))+" messages from "+
+		"\""+user.Username+"\":", d.Room)
+
+	// The messages are stored from newest to oldest, but we want to show the newest at the bottom
+	// (the messages were already HTML-escaped before they were stored in the database,
+	// but the mentions and formatting are filled in when they are read)
+	for i := len(msgs) - 1; i >= 0; i-- {
+		msg := msgs[i]
+		chatServerSendPM(s, "["+s.FormatTimestamp(msg.Datetime)+"] ["+msg.Room+"] "+
+			chatFillAll(msg.Message), d.Room)
+	}
+}
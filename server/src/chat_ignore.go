@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+// /ignore [username]
+func chatIgnore(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	ignore(s, d, true)
+}
+
+// /unignore [username]
+func chatUnignore(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	ignore(s, d, false)
+}
+
+func ignore(s *Session, d *CommandData, add bool) {
+	// Validate that they sent a username
+	if len(d.Args) != 1 {
+		var msg string
+		if add {
+			msg = "The format of the /ignore command is: /ignore [username]"
+		} else {
+			msg = "The format of the /unignore command is: /unignore [username]"
+		}
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+	username := d.Args[0]
+	normalizedUsername := normalizeString(username)
+
+	// Validate that they did not target themselves
+	if normalizedUsername == normalizeString(s.Username) {
+		if add {
+			chatServerSendPM(s, ChatMsgIgnoreSelf, d.Room)
+		} else {
+			chatServerSendPM(s, ChatMsgUnignoreSelf, d.Room)
+		}
+		return
+	}
+
+	// Validate that this person exists in the database
+	var ignoredUser User
+	if exists, v, err := models.Users.GetUserFromNormalizedUsername(
+		normalizedUsername,
+	); err != nil {
+		logger.Error("Failed to validate that \"" + normalizedUsername + "\" " +
+			"exists in the database: " + err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else if !exists {
+		chatServerSendPM(s, ChatMsgUserNotFound, d.Room, username)
+		return
+	} else {
+		ignoredUser = v
+	}
+
+	ignoredMap := s.Ignored()
+
+	if add {
+		// Validate that this user is not already ignored
+		if _, ok := ignoredMap[ignoredUser.ID]; ok {
+			chatServerSendPM(s, ChatMsgAlreadyIgnoring, d.Room, ignoredUser.Username)
+			return
+		}
+
+		if err := models.UserIgnores.Insert(s.UserID, ignoredUser.ID); err != nil {
+			logger.Error("Failed to insert a new ignored user for user " +
+				"\"" + s.Username + "\": " + err.Error())
+			s.Error(DefaultErrorMsg)
+			return
+		}
+		ignoredMap[ignoredUser.ID] = struct{}{}
+
+		chatServerSendPM(s, ChatMsgNowIgnoring, d.Room, ignoredUser.Username)
+	} else {
+		// Validate that this user is ignored
+		if _, ok := ignoredMap[ignoredUser.ID]; !ok {
+			chatServerSendPM(s, ChatMsgNotIgnoring, d.Room, ignoredUser.Username)
+			return
+		}
+
+		if err := models.UserIgnores.Delete(s.UserID, ignoredUser.ID); err != nil {
+			logger.Error("Failed to delete an ignored user for user \"" + s.Username + "\": " +
+				err.Error())
+			s.Error(DefaultErrorMsg)
+			return
+		}
+		delete(ignoredMap, ignoredUser.ID)
+
+		chatServerSendPM(s, ChatMsgNoLongerIgnoring, d.Room, ignoredUser.Username)
+	}
+}
+
+// /ignorelist
+func chatIgnoreList(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	var ignored []string
+	if v, err := models.UserIgnores.GetAllUsernames(s.UserID); err != nil {
+		logger.Error("Failed to get the ignored users for user \"" + s.Username + "\": " +
+			err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else {
+		ignored = v
+	}
+
+	if len(ignored) == 0 {
+		chatServerSendPM(s, ChatMsgIgnoringNone, d.Room)
+	} else {
+		chatServerSendPM(s, ChatMsgIgnoringList, d.Room, strings.Join(ignored, ", "))
+	}
+}
+
+// chatIsIgnored returns true if the recipient should not be sent a message from the sender
+// Messages from the server (with a nil sender) and from moderators are never ignored
+func chatIsIgnored(recipient *Session, sender *Session) bool {
+	if recipient == nil || sender == nil || sender.Moderator {
+		return false
+	}
+
+	_, ok := recipient.Ignored()[sender.UserID]
+	return ok
+}
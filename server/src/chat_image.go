@@ -0,0 +1,123 @@
+// Links to images (e.g. screenshots) that are posted in chat can be shown as an inline thumbnail
+// They are disabled unless the "CHAT_IMAGE_HOSTS" environment variable is set
+// Unlike link previews (in "chat_preview.go"), the server never fetches the images itself;
+// it only tells the clients which links are safe to render, which are sent in a separate
+// "chatImage" message
+// Links to any other host stay as plain links
+
+package main
+
+import (
+	"html"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	// Only the first few images in a message are shown, so that one message cannot fill the chat
+	ChatImageMaxImages = 3
+)
+
+type ChatImageMessage struct {
+	ID   string   `json:"id"` // The ID of the chat message that contains the links
+	Room string   `json:"room"`
+	URLs []string `json:"urls"`
+}
+
+var (
+	// The hosts that images are allowed to be shown from; nil if images are disabled
+	// Subdomains are also allowed (e.g. "imgur.com" also allows "i.imgur.com")
+	chatImageHosts []string
+
+	// Only HTTPS links are allowed, since the website is served over HTTPS and an HTTP image would
+	// be mixed content
+	chatImageURLRegExp = regexp.MustCompile(
+		`(?i)https://[^\s<>"']+\.(?:png|jpe?g|gif|webp)(?:\?[^\s<>"']*)?`,
+	)
+)
+
+func chatImageInit() {
+	hostsString := os.Getenv("CHAT_IMAGE_HOSTS")
+	if len(hostsString) == 0 {
+		return
+	}
+
+	chatImageHosts = make([]string, 0)
+	for _, host := range strings.Split(hostsString, ",") {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host != "" {
+			chatImageHosts = append(chatImageHosts, host)
+		}
+	}
+}
+
+// chatImageSend looks for links to images from the allowed hosts in a chat message and sends them
+// to the provided sessions
+// The message must have already gone through the "chatFillAll()" function,
+// so that the links inside of code blocks are skipped
+func chatImageSend(messageID string, room string, msg string, recipients []*Session) {
+	if chatImageHosts == nil || messageID == "" || len(recipients) == 0 {
+		return
+	}
+
+	urls := chatImageFind(msg)
+	if len(urls) == 0 {
+		return
+	}
+
+	chatImageMsg := &ChatImageMessage{
+		ID:   messageID,
+		Room: room,
+		URLs: urls,
+	}
+	for _, s := range recipients {
+		s.Emit("chatImage", chatImageMsg)
+	}
+}
+
+func chatImageFind(msg string) []string {
+	urls := make([]string, 0)
+	seen := make(map[string]struct{})
+
+	// The code blocks are left as they are, so we only need to look at the rest of the message
+	chatReplaceCodeBlocks(msg, func(text string) string {
+		// The message has already been HTML-escaped (e.g. "&" is now "&amp;")
+		for _, match := range chatImageURLRegExp.FindAllString(html.UnescapeString(text), -1) {
+			if len(urls) >= ChatImageMaxImages {
+				break
+			}
+			if _, ok := seen[match]; ok {
+				continue
+			}
+
+			if u, err := url.Parse(match); err != nil || !chatImageHostAllowed(u) {
+				continue
+			}
+			seen[match] = struct{}{}
+
+			// The client renders the URL as an attribute, so it is escaped again
+			urls = append(urls, html.EscapeString(match))
+		}
+		return text
+	})
+
+	return urls
+}
+
+func chatImageHostAllowed(u *url.URL) bool {
+	// Links with a username or a password (e.g. "https://evil.com@imgur.com/") are never allowed,
+	// since they are often used to disguise the real host
+	if u.Scheme != "https" || u.User != nil {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, allowedHost := range chatImageHosts {
+		if host == allowedHost || strings.HasSuffix(host, "."+allowedHost) {
+			return true
+		}
+	}
+	return false
+}
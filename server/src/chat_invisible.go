@@ -0,0 +1,81 @@
+// Invisible characters (e.g. zero-width spaces and right-to-left overrides) can be used to break
+// the rendering of the chat or to get around the profanity filter, so they are removed from chat
+// messages before they are stored or sent
+// Most of them are not printable (and are removed for that reason),
+// but some of them are needed for emoji, and some of them are printable but render as nothing
+
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	zeroWidthJoiner     = '\u200D'
+	variationSelector16 = '\uFE0F'
+	// Subdivision flags (e.g. the flag of Scotland) are a black flag followed by tag characters
+	blackFlag = '\U0001F3F4'
+	tagFirst  = '\U000E0020'
+	tagLast   = '\U000E007F'
+)
+
+var (
+	// These are letters according to Unicode, but they render as blank space
+	invisibleLetters = map[rune]struct{}{
+		'\u115F': {}, // Hangul Choseong Filler
+		'\u1160': {}, // Hangul Jungseong Filler
+		'\u3164': {}, // Hangul Filler
+		'\uFFA0': {}, // Halfwidth Hangul Filler
+	}
+)
+
+// removeInvisibleCharacters removes the non-printable and invisible characters from a single line
+// of a chat message
+// Combining marks are kept (excessive diacritics are handled separately in "sanitizeChatInput()"),
+// and so are the zero-width joiners and tag characters that are part of an emoji sequence
+func removeInvisibleCharacters(line string) string {
+	runes := []rune(line)
+	var sb strings.Builder
+	inFlag := false
+	for i, r := range runes {
+		switch {
+		case r == zeroWidthJoiner:
+			// e.g. "👨‍👩‍👧" is three emoji that are joined together
+			if i > 0 && i < len(runes)-1 && isEmojiRune(runes[i-1]) && isEmojiRune(runes[i+1]) {
+				sb.WriteRune(r)
+			}
+
+		case r >= tagFirst && r <= tagLast:
+			if inFlag {
+				sb.WriteRune(r)
+			}
+
+		case !unicode.IsPrint(r):
+			// Returning without writing drops the character from the string with no replacement
+
+		default:
+			if _, ok := invisibleLetters[r]; !ok {
+				sb.WriteRune(r)
+			}
+		}
+
+		// The tag characters of a flag end with the "cancel tag" character
+		if r == blackFlag {
+			inFlag = true
+		} else if r < tagFirst || r >= tagLast {
+			inFlag = false
+		}
+	}
+
+	return sb.String()
+}
+
+func isEmojiRune(r rune) bool {
+	// "So" stands for other symbol, which is where most of the emoji are
+	// The variation selector makes the previous character display as an emoji (e.g. "❤️"),
+	// and the skin tone modifiers are "Sk" (modifier symbol)
+	return unicode.Is(unicode.So, r) ||
+		r == variationSelector16 ||
+		(r >= '\U0001F3FB' && r <= '\U0001F3FF')
+}
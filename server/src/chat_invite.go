@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+)
+
+// ChatInviteMessage is sent to a user when someone invites them to join their table
+// (the client shows it as a link that joins the table when clicked)
+type ChatInviteMessage struct {
+	TableID   uint64 `json:"tableID"`
+	TableName string `json:"tableName"`
+	Who       string `json:"who"`
+}
+
+// /invite [username]
+func chatInvite(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if t == nil {
+		chatServerSendPM(s, ChatMsgNotInGame, d.Room)
+		return
+	}
+
+	if t.GetPlayerIndexFromID(s.UserID) == -1 {
+		chatServerSendPM(s, "You can only invite people to a table that you are playing at.", d.Room)
+		return
+	}
+
+	if t.Running || t.Replay {
+		chatServerSendPM(s, "You can only invite people before the game starts.", d.Room)
+		return
+	}
+
+	if len(d.Args) != 1 {
+		chatServerSendPM(s, "The format of the /invite command is: /invite [username]", d.Room)
+		return
+	}
+
+	// Validate that the recipient is online
+	normalizedUsername := normalizeString(d.Args[0])
+	var recipientSession *Session
+	for _, s2 := range sessions.GetList() {
+		if normalizeString(s2.Username) == normalizedUsername {
+			recipientSession = s2
+			break
+		}
+	}
+	if recipientSession == nil {
+		chatServerSendPM(s, ChatMsgUserNotFound, d.Room, d.Args[0])
+		return
+	}
+
+	if recipientSession.UserID == s.UserID {
+		chatServerSendPM(s, "You cannot invite yourself.", d.Room)
+		return
+	}
+
+	if t.GetPlayerIndexFromID(recipientSession.UserID) != -1 {
+		chatServerSendPM(s, recipientSession.Username+" is already playing at this table.", d.Room)
+		return
+	}
+
+	if _, ok := t.KickedPlayers[recipientSession.UserID]; ok {
+		msg := recipientSession.Username + " was kicked from this table, so they cannot be invited."
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	if len(t.Players) >= t.MaxPlayers {
+		chatServerSendPM(s, "This table is already full.", d.Room)
+		return
+	}
+
+	// Like private messages, invitations from someone who is ignored are silently dropped
+	if !chatIsIgnored(recipientSession, s) {
+		// Invited users do not need to know the password
+		// (the table can still fill up or start before they accept,
+		// which is handled by the normal checks in the "commandTableJoin()" function)
+		t.Invited[recipientSession.UserID] = struct{}{}
+		recipientSession.Emit("chatInvite", &ChatInviteMessage{
+			TableID:   t.ID,
+			TableName: t.Name,
+			Who:       s.Username,
+		})
+	}
+
+	chatServerSendPM(s, "You invited "+recipientSession.Username+" to this table.", d.Room)
+}
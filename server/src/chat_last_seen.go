@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+// /lastseen [username]
+func chatLastSeen(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	// Validate that they sent a username
+	if len(d.Args) != 1 {
+		msg := "The format of the /lastseen command is: /lastseen [username]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+	username := d.Args[0]
+	normalizedUsername := normalizeString(username)
+
+	// Validate that this person exists in the database
+	var user User
+	if exists, v, err := models.Users.GetUserFromNormalizedUsername(
+		normalizedUsername,
+	); err != nil {
+		logger.Error("Failed to validate that \"" + normalizedUsername + "\" " +
+			"exists in the database: " + err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else if !exists {
+		chatServerSendPM(s, ChatMsgUserNotFound, d.Room, username)
+		return
+	} else {
+		user = v
+	}
+
+	// Online users are already shown in the lobby user list, so the privacy setting does not apply
+	if _, ok := sessions.Get(user.ID); ok {
+		chatServerSendPM(s, ChatMsgLastSeenOnline, d.Room, user.Username)
+		return
+	}
+
+	// Users can opt out of being queried from the "Settings" tooltip in the lobby
+	if hidden, err := models.UserSettings.IsLastSeenHidden(user.ID); err != nil {
+		logger.Error("Failed to get the \"hide_last_seen\" setting for user " +
+			"\"" + user.Username + "\": " + err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else if hidden {
+		chatServerSendPM(s, ChatMsgLastSeenHidden, d.Room, user.Username)
+		return
+	}
+
+	var lastSeen time.Time
+	if v, err := models.Users.GetLastSeen(user.ID); err != nil {
+		logger.Error("Failed to get the last seen time for user \"" + user.Username + "\": " +
+			err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else {
+		lastSeen = v
+	}
+
+	var durationString string
+	if v, err := secondsToDurationString(int(time.Since(lastSeen).Seconds())); err != nil {
+		logger.Error("Failed to parse the duration string: " + err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else {
+		durationString = v
+	}
+
+	msg := "\"" + user.Username + "\" was last seen " + durationString + " ago " +
+		"(" + s.FormatTimestamp(lastSeen) + ")."
+	chatServerSendPM(s, msg, d.Room)
+}
@@ -0,0 +1,511 @@
+// Private messages from the server (e.g. command feedback) are shown in the language of the
+// recipient, which is taken from the "Accept-Language" header of their browser when they connect
+// Messages that are sent to an entire room are still in English,
+// since they are stored in the database and shared by everyone
+
+package main
+
+import (
+	"fmt"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	DefaultLanguage = "en"
+)
+
+// The keys of the localized messages
+// (the parameters of each message are documented next to its English text below)
+const (
+	ChatMsgNotMod               = "notMod"
+	ChatMsgNotInGame            = "notInGame"
+	ChatMsgReplyNotFound        = "replyNotFound"
+	ChatMsgTooLong              = "tooLong"
+	ChatMsgRateLimited          = "rateLimited"
+	ChatMsgFlooded              = "flooded"
+	ChatMsgAutoMuteWarning      = "autoMuteWarning"
+	ChatMsgAutoMuted            = "autoMuted"
+	ChatMsgBannedWord           = "bannedWord"
+	ChatMsgRoomLocked           = "roomLocked"
+	ChatMsgTooManyMentions      = "tooManyMentions"
+	ChatMsgCooldown             = "cooldown"
+	ChatMsgCooldownOne          = "cooldownOne"
+	ChatMsgUserNotFound         = "userNotFound"
+	ChatMsgAFKOn                = "afkOn"
+	ChatMsgAFKOff               = "afkOff"
+	ChatMsgEditNone             = "editNone"
+	ChatMsgEditTooOld           = "editTooOld"
+	ChatMsgMessageNotFound      = "messageNotFound"
+	ChatMsgMessageDeleted       = "messageDeleted"
+	ChatMsgWhisperSelf          = "whisperSelf"
+	ChatMsgIgnoreSelf           = "ignoreSelf"
+	ChatMsgUnignoreSelf         = "unignoreSelf"
+	ChatMsgAlreadyIgnoring      = "alreadyIgnoring"
+	ChatMsgNotIgnoring          = "notIgnoring"
+	ChatMsgNowIgnoring          = "nowIgnoring"
+	ChatMsgNoLongerIgnoring     = "noLongerIgnoring"
+	ChatMsgIgnoringNone         = "ignoringNone"
+	ChatMsgIgnoringList         = "ignoringList"
+	ChatMsgFriendsNone          = "friendsNone"
+	ChatMsgFriendsOnline        = "friendsOnline"
+	ChatMsgFriendsNoneOnline    = "friendsNoneOnline"
+	ChatMsgFriendsOffline       = "friendsOffline"
+	ChatMsgFriendStatusLobby    = "friendStatusLobby"
+	ChatMsgFriendStatusPregame  = "friendStatusPregame"
+	ChatMsgFriendStatusPlaying  = "friendStatusPlaying"
+	ChatMsgFriendStatusSpectate = "friendStatusSpectate"
+	ChatMsgFriendStatusReplay   = "friendStatusReplay"
+	ChatMsgLastSeenOnline       = "lastSeenOnline"
+	ChatMsgLastSeenHidden       = "lastSeenHidden"
+	ChatMsgStatsHidden          = "statsHidden"
+	ChatMsgStatsNoGames         = "statsNoGames"
+	ChatMsgPMQueued             = "pmQueued"
+	ChatMsgPMQueueFull          = "pmQueueFull"
+	ChatMsgPMsWhileAway         = "pmsWhileAway"
+	ChatMsgSlowMode             = "slowMode"
+	ChatMsgSlowModeOne          = "slowModeOne"
+	ChatMsgMentionedInLobby     = "mentionedInLobby"
+	ChatMsgMentionedAtTable     = "mentionedAtTable"
+	ChatMsgTranslated           = "translated"
+	ChatMsgTranslateFailed      = "translateFailed"
+)
+
+var (
+	// DefaultLanguage must be first, since the matcher falls back to the first language
+	chatLanguages = []language.Tag{
+		language.English,
+		language.French,
+		language.Spanish,
+		language.German,
+	}
+	chatLanguageMatcher = language.NewMatcher(chatLanguages)
+
+	// Indexed by key, then by language
+	// Every message must have an English version
+	chatMessages = map[string]map[string]string{
+		ChatMsgNotMod: {
+			"en": NotModFail,
+			"fr": "Seuls les modérateurs peuvent utiliser cette commande.",
+			"es": "Solo los moderadores pueden usar ese comando.",
+			"de": "Nur Moderatoren können diesen Befehl verwenden.",
+		},
+		ChatMsgNotInGame: {
+			"en": NotInGameFail,
+			"fr": "Vous ne pouvez utiliser cette commande que pendant une partie.",
+			"es": "Solo puedes usar este comando durante una partida.",
+			"de": "Du kannst diesen Befehl nur während eines Spiels verwenden.",
+		},
+		ChatMsgReplyNotFound: {
+			"en": ReplyNotFoundFail,
+			"fr": "Le message auquel vous répondez n'existe pas dans ce salon.",
+			"es": "El mensaje al que respondes no existe en esta sala.",
+			"de": "Die Nachricht, auf die du antwortest, existiert in diesem Raum nicht.",
+		},
+		// 1: the maximum number of characters
+		ChatMsgTooLong: {
+			"en": "Chat messages cannot be longer than %v characters.",
+			"fr": "Les messages ne peuvent pas dépasser %v caractères.",
+			"es": "Los mensajes no pueden tener más de %v caracteres.",
+			"de": "Chatnachrichten dürfen nicht länger als %v Zeichen sein.",
+		},
+		// 1: the number of messages, 2: the number of seconds
+		ChatMsgRateLimited: {
+			"en": "You are sending messages too quickly. You can only send %v messages every %v " +
+				"seconds in the same room.",
+			"fr": "Vous envoyez des messages trop rapidement. Vous ne pouvez envoyer que %v messages " +
+				"toutes les %v secondes dans le même salon.",
+			"es": "Estás enviando mensajes demasiado rápido. Solo puedes enviar %v mensajes cada %v " +
+				"segundos en la misma sala.",
+			"de": "Du sendest Nachrichten zu schnell. Du kannst im selben Raum nur %v Nachrichten " +
+				"alle %v Sekunden senden.",
+		},
+		// 1: the number of repeats, 2: the number of seconds
+		ChatMsgFlooded: {
+			"en": "Please do not repeat the same message. You can only send the same message %v " +
+				"times in a row every %v seconds.",
+			"fr": "Merci de ne pas répéter le même message. Vous ne pouvez envoyer le même message " +
+				"que %v fois de suite toutes les %v secondes.",
+			"es": "Por favor, no repitas el mismo mensaje. Solo puedes enviar el mismo mensaje %v " +
+				"veces seguidas cada %v segundos.",
+			"de": "Bitte wiederhole nicht dieselbe Nachricht. Du kannst dieselbe Nachricht nur %v " +
+				"Mal hintereinander alle %v Sekunden senden.",
+		},
+		// 1: the number of the warning, 2: the number of warnings before the mute,
+		// 3: the length of the mute
+		ChatMsgAutoMuteWarning: {
+			"en": "Warning %v of %v: if you keep sending messages too quickly, you will be " +
+				"temporarily muted for %v.",
+			"fr": "Avertissement %v sur %v : si vous continuez à envoyer des messages trop " +
+				"rapidement, vous serez temporairement réduit au silence pendant %v.",
+			"es": "Advertencia %v de %v: si sigues enviando mensajes demasiado rápido, serás " +
+				"silenciado temporalmente durante %v.",
+			"de": "Warnung %v von %v: Wenn du weiterhin zu schnell Nachrichten sendest, wirst du " +
+				"vorübergehend für %v stummgeschaltet.",
+		},
+		// 1: the amount of time that is left
+		ChatMsgAutoMuted: {
+			"en": "You have been temporarily muted for sending messages too quickly. You can " +
+				"chat again in %v.",
+			"fr": "Vous avez été temporairement réduit au silence pour avoir envoyé des messages " +
+				"trop rapidement. Vous pourrez de nouveau discuter dans %v.",
+			"es": "Has sido silenciado temporalmente por enviar mensajes demasiado rápido. Podrás " +
+				"volver a chatear en %v.",
+			"de": "Du wurdest vorübergehend stummgeschaltet, weil du zu schnell Nachrichten " +
+				"gesendet hast. Du kannst in %v wieder chatten.",
+		},
+		ChatMsgBannedWord: {
+			"en": "Your message was not sent because it contains a word or phrase that is not " +
+				"allowed.",
+			"fr": "Votre message n'a pas été envoyé car il contient un mot ou une expression qui " +
+				"n'est pas autorisé.",
+			"es": "Tu mensaje no se ha enviado porque contiene una palabra o frase que no está " +
+				"permitida.",
+			"de": "Deine Nachricht wurde nicht gesendet, weil sie ein Wort oder eine Wendung " +
+				"enthält, die nicht erlaubt ist.",
+		},
+		ChatMsgRoomLocked: {
+			"en": "This room has been locked by a moderator. Only moderators can send messages " +
+				"to it for the time being.",
+			"fr": "Ce salon a été verrouillé par un modérateur. Seuls les modérateurs peuvent y " +
+				"envoyer des messages pour le moment.",
+			"es": "Esta sala ha sido bloqueada por un moderador. Por ahora, solo los moderadores " +
+				"pueden enviar mensajes en ella.",
+			"de": "Dieser Raum wurde von einem Moderator gesperrt. Vorerst können nur " +
+				"Moderatoren Nachrichten darin senden.",
+		},
+		// 1: the maximum number of mentions
+		ChatMsgTooManyMentions: {
+			"en": "Your message mentions too many people. You can only mention %v different " +
+				"users or roles in the same message.",
+			"fr": "Votre message mentionne trop de personnes. Vous ne pouvez mentionner que %v " +
+				"utilisateurs ou rôles différents dans un même message.",
+			"es": "Tu mensaje menciona a demasiadas personas. Solo puedes mencionar %v usuarios o " +
+				"roles diferentes en un mismo mensaje.",
+			"de": "Deine Nachricht erwähnt zu viele Personen. Du kannst in einer Nachricht nur %v " +
+				"verschiedene Benutzer oder Rollen erwähnen.",
+		},
+		// 1: the number of seconds, 2: the command
+		ChatMsgCooldown: {
+			"en": "You must wait %v more seconds before using the /%v command again.",
+			"fr": "Vous devez attendre encore %v secondes avant de réutiliser la commande /%v.",
+			"es": "Debes esperar %v segundos más antes de volver a usar el comando /%v.",
+			"de": "Du musst noch %v Sekunden warten, bevor du den Befehl /%v erneut verwenden " +
+				"kannst.",
+		},
+		// 1: the command
+		ChatMsgCooldownOne: {
+			"en": "You must wait 1 more second before using the /%v command again.",
+			"fr": "Vous devez attendre encore 1 seconde avant de réutiliser la commande /%v.",
+			"es": "Debes esperar 1 segundo más antes de volver a usar el comando /%v.",
+			"de": "Du musst noch 1 Sekunde warten, bevor du den Befehl /%v erneut verwenden kannst.",
+		},
+		// 1: the username
+		ChatMsgUserNotFound: {
+			"en": "The username of \"%v\" does not exist in the database.",
+			"fr": "Le nom d'utilisateur \"%v\" n'existe pas dans la base de données.",
+			"es": "El nombre de usuario \"%v\" no existe en la base de datos.",
+			"de": "Der Benutzername \"%v\" existiert nicht in der Datenbank.",
+		},
+		ChatMsgAFKOn: {
+			"en": "You are now marked as AFK. This will be cleared when you send your next message.",
+			"fr": "Vous êtes maintenant marqué comme absent. Cela sera annulé lorsque vous enverrez " +
+				"votre prochain message.",
+			"es": "Ahora estás marcado como ausente. Esto se quitará cuando envíes tu próximo " +
+				"mensaje.",
+			"de": "Du bist jetzt als abwesend markiert. Das wird aufgehoben, sobald du deine " +
+				"nächste Nachricht sendest.",
+		},
+		ChatMsgAFKOff: {
+			"en": "You are no longer marked as AFK.",
+			"fr": "Vous n'êtes plus marqué comme absent.",
+			"es": "Ya no estás marcado como ausente.",
+			"de": "Du bist nicht mehr als abwesend markiert.",
+		},
+		ChatMsgEditNone: {
+			"en": "You have not sent any messages that can be edited.",
+			"fr": "Vous n'avez envoyé aucun message qui puisse être modifié.",
+			"es": "No has enviado ningún mensaje que se pueda editar.",
+			"de": "Du hast keine Nachrichten gesendet, die bearbeitet werden können.",
+		},
+		// 1: the number of seconds
+		ChatMsgEditTooOld: {
+			"en": "You can only edit messages that were sent in the last %v seconds.",
+			"fr": "Vous ne pouvez modifier que les messages envoyés au cours des %v dernières " +
+				"secondes.",
+			"es": "Solo puedes editar los mensajes enviados en los últimos %v segundos.",
+			"de": "Du kannst nur Nachrichten bearbeiten, die in den letzten %v Sekunden gesendet " +
+				"wurden.",
+		},
+		// 1: the message ID
+		ChatMsgMessageNotFound: {
+			"en": "There is no message with an ID of \"%v\".",
+			"fr": "Il n'y a aucun message avec l'identifiant \"%v\".",
+			"es": "No hay ningún mensaje con el identificador \"%v\".",
+			"de": "Es gibt keine Nachricht mit der ID \"%v\".",
+		},
+		ChatMsgMessageDeleted: {
+			"en": "The message has been deleted.",
+			"fr": "Le message a été supprimé.",
+			"es": "El mensaje ha sido eliminado.",
+			"de": "Die Nachricht wurde gelöscht.",
+		},
+		ChatMsgWhisperSelf: {
+			"en": "You cannot whisper to yourself.",
+			"fr": "Vous ne pouvez pas vous chuchoter à vous-même.",
+			"es": "No puedes susurrarte a ti mismo.",
+			"de": "Du kannst dir nicht selbst zuflüstern.",
+		},
+		ChatMsgIgnoreSelf: {
+			"en": "You cannot ignore yourself.",
+			"fr": "Vous ne pouvez pas vous ignorer vous-même.",
+			"es": "No puedes ignorarte a ti mismo.",
+			"de": "Du kannst dich nicht selbst ignorieren.",
+		},
+		ChatMsgUnignoreSelf: {
+			"en": "You cannot unignore yourself.",
+			"fr": "Vous ne pouvez pas ne plus vous ignorer vous-même.",
+			"es": "No puedes dejar de ignorarte a ti mismo.",
+			"de": "Du kannst das Ignorieren von dir selbst nicht aufheben.",
+		},
+		// 1: the username
+		ChatMsgAlreadyIgnoring: {
+			"en": "You are already ignoring \"%v\".",
+			"fr": "Vous ignorez déjà \"%v\".",
+			"es": "Ya estás ignorando a \"%v\".",
+			"de": "Du ignorierst \"%v\" bereits.",
+		},
+		// 1: the username
+		ChatMsgNotIgnoring: {
+			"en": "You are not ignoring \"%v\".",
+			"fr": "Vous n'ignorez pas \"%v\".",
+			"es": "No estás ignorando a \"%v\".",
+			"de": "Du ignorierst \"%v\" nicht.",
+		},
+		// 1: the username
+		ChatMsgNowIgnoring: {
+			"en": "You are now ignoring \"%v\". (Messages from the server and from moderators " +
+				"will still be shown.)",
+			"fr": "Vous ignorez maintenant \"%v\". (Les messages du serveur et des modérateurs " +
+				"seront toujours affichés.)",
+			"es": "Ahora estás ignorando a \"%v\". (Los mensajes del servidor y de los moderadores " +
+				"se seguirán mostrando.)",
+			"de": "Du ignorierst jetzt \"%v\". (Nachrichten vom Server und von Moderatoren werden " +
+				"weiterhin angezeigt.)",
+		},
+		// 1: the username
+		ChatMsgNoLongerIgnoring: {
+			"en": "You are no longer ignoring \"%v\".",
+			"fr": "Vous n'ignorez plus \"%v\".",
+			"es": "Ya no estás ignorando a \"%v\".",
+			"de": "Du ignorierst \"%v\" nicht mehr.",
+		},
+		ChatMsgIgnoringNone: {
+			"en": "You are not ignoring anyone.",
+			"fr": "Vous n'ignorez personne.",
+			"es": "No estás ignorando a nadie.",
+			"de": "Du ignorierst niemanden.",
+		},
+		// 1: a comma-separated list of usernames
+		ChatMsgIgnoringList: {
+			"en": "You are ignoring: %v",
+			"fr": "Vous ignorez : %v",
+			"es": "Estás ignorando a: %v",
+			"de": "Du ignorierst: %v",
+		},
+		ChatMsgFriendsNone: {
+			"en": "Currently, you do not have any friends on your friends list.",
+			"fr": "Vous n'avez actuellement aucun ami dans votre liste d'amis.",
+			"es": "Actualmente no tienes ningún amigo en tu lista de amigos.",
+			"de": "Du hast derzeit keine Freunde auf deiner Freundesliste.",
+		},
+		// 1: a comma-separated list of usernames (with their status)
+		ChatMsgFriendsOnline: {
+			"en": "Online friends: %v",
+			"fr": "Amis en ligne : %v",
+			"es": "Amigos conectados: %v",
+			"de": "Freunde online: %v",
+		},
+		ChatMsgFriendsNoneOnline: {
+			"en": "None of your friends are currently online.",
+			"fr": "Aucun de vos amis n'est actuellement en ligne.",
+			"es": "Ninguno de tus amigos está conectado en este momento.",
+			"de": "Keiner deiner Freunde ist gerade online.",
+		},
+		// 1: a comma-separated list of usernames
+		ChatMsgFriendsOffline: {
+			"en": "Offline friends: %v",
+			"fr": "Amis hors ligne : %v",
+			"es": "Amigos desconectados: %v",
+			"de": "Freunde offline: %v",
+		},
+		ChatMsgFriendStatusLobby: {
+			"en": "in the lobby",
+			"fr": "dans le salon principal",
+			"es": "en la sala principal",
+			"de": "in der Lobby",
+		},
+		ChatMsgFriendStatusPregame: {
+			"en": "waiting for a game to start",
+			"fr": "en attente du début d'une partie",
+			"es": "esperando a que empiece una partida",
+			"de": "wartet auf den Start eines Spiels",
+		},
+		ChatMsgFriendStatusPlaying: {
+			"en": "in a game",
+			"fr": "en partie",
+			"es": "en una partida",
+			"de": "in einem Spiel",
+		},
+		ChatMsgFriendStatusSpectate: {
+			"en": "spectating a game",
+			"fr": "spectateur d'une partie",
+			"es": "observando una partida",
+			"de": "schaut einem Spiel zu",
+		},
+		ChatMsgFriendStatusReplay: {
+			"en": "watching a replay",
+			"fr": "regarde un replay",
+			"es": "viendo una repetición",
+			"de": "schaut eine Wiederholung an",
+		},
+		// 1: the username
+		ChatMsgLastSeenOnline: {
+			"en": "\"%v\" is currently online.",
+			"fr": "\"%v\" est actuellement en ligne.",
+			"es": "\"%v\" está conectado en este momento.",
+			"de": "\"%v\" ist gerade online.",
+		},
+		// 1: the username
+		ChatMsgLastSeenHidden: {
+			"en": "\"%v\" has chosen not to share when they were last seen.",
+			"fr": "\"%v\" a choisi de ne pas partager sa dernière connexion.",
+			"es": "\"%v\" ha decidido no compartir cuándo se conectó por última vez.",
+			"de": "\"%v\" hat die Anzeige des letzten Besuchs deaktiviert.",
+		},
+		ChatMsgStatsHidden: {
+			"en": "\"%v\" has chosen not to share their stats.",
+			"fr": "\"%v\" a choisi de ne pas partager ses statistiques.",
+			"es": "\"%v\" ha decidido no compartir sus estadísticas.",
+			"de": "\"%v\" hat die Anzeige der eigenen Statistiken deaktiviert.",
+		},
+		ChatMsgStatsNoGames: {
+			"en": "\"%v\" has not played any games yet.",
+			"fr": "\"%v\" n'a encore joué aucune partie.",
+			"es": "\"%v\" todavía no ha jugado ninguna partida.",
+			"de": "\"%v\" hat noch keine Spiele gespielt.",
+		},
+		// 1: the username, 2: the number of days
+		ChatMsgPMQueued: {
+			"en": "\"%v\" is not currently online. Your message will be delivered when they next " +
+				"log in (within %v days).",
+			"fr": "\"%v\" n'est pas en ligne actuellement. Votre message sera remis lors de sa " +
+				"prochaine connexion (dans les %v jours).",
+			"es": "\"%v\" no está conectado en este momento. Tu mensaje se entregará cuando vuelva " +
+				"a iniciar sesión (en un plazo de %v días).",
+			"de": "\"%v\" ist gerade nicht online. Deine Nachricht wird bei der nächsten Anmeldung " +
+				"zugestellt (innerhalb von %v Tagen).",
+		},
+		// 1: the username
+		ChatMsgPMQueueFull: {
+			"en": "\"%v\" is not currently online and has too many undelivered messages. " +
+				"Please try again later.",
+			"fr": "\"%v\" n'est pas en ligne actuellement et a trop de messages non remis. " +
+				"Veuillez réessayer plus tard.",
+			"es": "\"%v\" no está conectado en este momento y tiene demasiados mensajes sin " +
+				"entregar. Vuelve a intentarlo más tarde.",
+			"de": "\"%v\" ist gerade nicht online und hat zu viele nicht zugestellte Nachrichten. " +
+				"Bitte versuche es später erneut.",
+		},
+		// 1: the number of messages
+		ChatMsgPMsWhileAway: {
+			"en": "You received %v private message(s) while you were away:",
+			"fr": "Vous avez reçu %v message(s) privé(s) pendant votre absence :",
+			"es": "Recibiste %v mensaje(s) privado(s) mientras estabas ausente:",
+			"de": "Du hast %v private Nachricht(en) erhalten, während du weg warst:",
+		},
+		// 1: the number of seconds
+		ChatMsgSlowMode: {
+			"en": "Slow mode is enabled at this table. You must wait %v more seconds before " +
+				"sending another message.",
+			"fr": "Le mode lent est activé à cette table. Vous devez attendre encore %v secondes " +
+				"avant d'envoyer un autre message.",
+			"es": "El modo lento está activado en esta mesa. Debes esperar %v segundos más antes " +
+				"de enviar otro mensaje.",
+			"de": "Der langsame Modus ist an diesem Tisch aktiviert. Du musst noch %v Sekunden " +
+				"warten, bevor du eine weitere Nachricht senden kannst.",
+		},
+		ChatMsgSlowModeOne: {
+			"en": "Slow mode is enabled at this table. You must wait 1 more second before " +
+				"sending another message.",
+			"fr": "Le mode lent est activé à cette table. Vous devez attendre encore 1 seconde " +
+				"avant d'envoyer un autre message.",
+			"es": "El modo lento está activado en esta mesa. Debes esperar 1 segundo más antes " +
+				"de enviar otro mensaje.",
+			"de": "Der langsame Modus ist an diesem Tisch aktiviert. Du musst noch 1 Sekunde " +
+				"warten, bevor du eine weitere Nachricht senden kannst.",
+		},
+		// 1: the username, 2: the message
+		ChatMsgMentionedInLobby: {
+			"en": "%v mentioned you in the lobby while you were away: %v",
+			"fr": "%v vous a mentionné dans le salon principal pendant votre absence : %v",
+			"es": "%v te mencionó en el vestíbulo mientras estabas ausente: %v",
+			"de": "%v hat dich in der Lobby erwähnt, während du weg warst: %v",
+		},
+		// 1: the username, 2: the name of the table, 3: the message
+		ChatMsgMentionedAtTable: {
+			"en": "%v mentioned you at the table \"%v\" while you were away: %v",
+			"fr": "%v vous a mentionné à la table \"%v\" pendant votre absence : %v",
+			"es": "%v te mencionó en la mesa \"%v\" mientras estabas ausente: %v",
+			"de": "%v hat dich am Tisch \"%v\" erwähnt, während du weg warst: %v",
+		},
+		// 1: the detected language of the original message, 2: the translation
+		ChatMsgTranslated: {
+			"en": "[Translated from %v] %v",
+			"fr": "[Traduit depuis %v] %v",
+			"es": "[Traducido desde %v] %v",
+			"de": "[Übersetzt aus %v] %v",
+		},
+		ChatMsgTranslateFailed: {
+			"en": "Failed to translate that message. Please try again later.",
+			"fr": "La traduction de ce message a échoué. Veuillez réessayer plus tard.",
+			"es": "No se pudo traducir ese mensaje. Por favor, inténtalo más tarde.",
+			"de": "Die Nachricht konnte nicht übersetzt werden. Bitte versuche es später erneut.",
+		},
+	}
+)
+
+// chatGetLanguage returns the best supported language for the value of an "Accept-Language"
+// header (e.g. "fr-CA,fr;q=0.9,en;q=0.8" returns "fr")
+func chatGetLanguage(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLanguage
+	}
+
+	_, index, confidence := chatLanguageMatcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLanguage
+	}
+
+	base, _ := chatLanguages[index].Base()
+	return base.String()
+}
+
+// chatLocalize renders a message in the given language, falling back to English
+// Messages that have not been localized yet are not keys, so they are returned unchanged
+func chatLocalize(lang string, key string, params ...interface{}) string {
+	translations, ok := chatMessages[key]
+	if !ok {
+		return key
+	}
+
+	msg, ok := translations[lang]
+	if !ok {
+		msg = translations[DefaultLanguage]
+	}
+	if len(params) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, params...)
+}
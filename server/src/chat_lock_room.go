@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+	"github.com/sasha-s/go-deadlock"
+)
+
+var (
+	chatLockedRooms      = make(map[string]struct{}) // Indexed by room
+	chatLockedRoomsMutex = &deadlock.RWMutex{}
+)
+
+// /lockroom [on/off]
+// Temporarily make the current room read-only so that only moderators can send messages to it
+// (e.g. during a raid); this is reset when the server restarts
+func chatLockRoom(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if !s.Moderator {
+		chatServerSendPM(s, ChatMsgNotMod, d.Room)
+		return
+	}
+
+	if len(d.Args) == 0 {
+		msg := "This room is currently "
+		if chatRoomIsLocked(d.Room) {
+			msg += "locked"
+		} else {
+			msg += "unlocked"
+		}
+		msg += ". The format of the /lockroom command is: /lockroom [on/off]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	var on bool
+	switch strings.ToLower(d.Args[0]) {
+	case "on":
+		on = true
+	case "off":
+		on = false
+	default:
+		msg := "The format of the /lockroom command is: /lockroom [on/off]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	if !chatRoomSetLocked(d.Room, on) {
+		if on {
+			chatServerSendPM(s, "This room is already locked.", d.Room)
+		} else {
+			chatServerSendPM(s, "This room is not locked.", d.Room)
+		}
+		return
+	}
+
+	var msg string
+	if on {
+		logger.Info("Moderator \"" + s.Username + "\" locked room \"" + d.Room + "\".")
+		msg = s.Username + " locked this room. Only moderators can send messages until it is " +
+			"unlocked."
+	} else {
+		logger.Info("Moderator \"" + s.Username + "\" unlocked room \"" + d.Room + "\".")
+		msg = s.Username + " unlocked this room. Everyone can send messages again."
+	}
+	chatServerSend(ctx, msg, d.Room, d.NoTablesLock)
+}
+
+func chatRoomIsLocked(room string) bool {
+	chatLockedRoomsMutex.RLock()
+	defer chatLockedRoomsMutex.RUnlock()
+
+	_, ok := chatLockedRooms[room]
+	return ok
+}
+
+// chatRoomSetLocked returns false if the room was already in the requested state
+func chatRoomSetLocked(room string, locked bool) bool {
+	chatLockedRoomsMutex.Lock()
+	defer chatLockedRoomsMutex.Unlock()
+
+	if _, ok := chatLockedRooms[room]; ok == locked {
+		return false
+	}
+	if locked {
+		chatLockedRooms[room] = struct{}{}
+	} else {
+		delete(chatLockedRooms, room)
+	}
+	return true
+}
+
+// chatRoomLockCheck returns false if the room is locked and the user is not allowed to send
+// messages to it
+// Server messages and messages from moderators are exempt
+func chatRoomLockCheck(s *Session, d *CommandData) bool {
+	if d.Server || (s != nil && s.Moderator) || !chatRoomIsLocked(d.Room) {
+		return true
+	}
+
+	if d.Discord {
+		// There is no-one to send the notice to
+		logger.Info("Ignoring a Discord message for room \"" + d.Room + "\", since it is locked.")
+		return false
+	}
+
+	chatServerSendPM(s, ChatMsgRoomLocked, d.Room)
+	return false
+}
@@ -0,0 +1,128 @@
+// Usernames on the website are already unique after they are transliterated to ASCII
+// (in "http_login.go"), but a name can still look like the name of a moderator
+// (e.g. "AIice" with an uppercase "i" instead of an "l", or a Discord nickname of "Alice")
+// Chat messages from these names are flagged so that clients can warn about them,
+// but they are not blocked, since most similar names are not impersonations
+
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+	"github.com/sasha-s/go-deadlock"
+)
+
+const (
+	// Very short names would match too many other names to be useful
+	ChatLookalikeMinLength = 3
+)
+
+var (
+	// Indexed by the skeleton of the name, the values are the protected names
+	chatLookalikeNames      = make(map[string]string)
+	chatLookalikeNamesMutex = &deadlock.RWMutex{}
+
+	// Characters (and sequences of characters) that look like other characters
+	// This is applied after the name is transliterated to lowercase ASCII
+	chatLookalikeReplacer = strings.NewReplacer(
+		"rn", "m",
+		"vv", "w",
+		"cl", "d",
+		"0", "o",
+		"1", "l",
+		"i", "l",
+		"|", "l",
+		"!", "l",
+		"3", "e",
+		"4", "a",
+		"@", "a",
+		"5", "s",
+		"$", "s",
+		"7", "t",
+		"8", "b",
+	)
+)
+
+func chatLookalikeInit() {
+	// Moderators are always protected
+	if usernames, err := models.Users.GetModeratorUsernames(); err != nil {
+		logger.Fatal("Failed to get the usernames of the moderators: " + err.Error())
+		return
+	} else {
+		for _, username := range usernames {
+			chatLookalikeProtect(username)
+		}
+	}
+
+	// Other names can be protected with a comma-separated list (e.g. the names of the staff on
+	// Discord)
+	for _, name := range strings.Split(os.Getenv("CHAT_PROTECTED_NAMES"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			chatLookalikeProtect(name)
+		}
+	}
+}
+
+// chatLookalikeProtect is also called when a moderator logs in,
+// so that new moderators are protected without having to restart the server
+func chatLookalikeProtect(name string) {
+	skeleton := chatLookalikeSkeleton(name)
+	if len(skeleton) < ChatLookalikeMinLength {
+		return
+	}
+
+	chatLookalikeNamesMutex.Lock()
+	defer chatLookalikeNamesMutex.Unlock()
+
+	if _, ok := chatLookalikeNames[skeleton]; !ok {
+		chatLookalikeNames[skeleton] = name
+	}
+}
+
+// chatLookalikeSkeleton reduces a name to the characters that it looks like,
+// so that two names that look the same have the same skeleton
+func chatLookalikeSkeleton(name string) string {
+	// An uppercase "i" looks like a lowercase "L", so it must be converted before the name is
+	// lowercased
+	name = strings.ReplaceAll(name, "I", "l")
+	name = normalizeString(name)
+	name = chatLookalikeReplacer.Replace(name)
+
+	// Punctuation and spaces are easy to miss (e.g. "Alice_" or "A.lice")
+	var sb strings.Builder
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// chatGetLookalike returns the protected name that a name looks like (or a blank string if it
+// does not look like any of them)
+// Website usernames are verified, so someone with the exact protected name is the real person;
+// Discord nicknames can be anything, so they are always checked
+func chatGetLookalike(name string, verified bool) string {
+	skeleton := chatLookalikeSkeleton(name)
+
+	chatLookalikeNamesMutex.RLock()
+	protectedName, ok := chatLookalikeNames[skeleton]
+	chatLookalikeNamesMutex.RUnlock()
+
+	if !ok || (verified && name == protectedName) {
+		return ""
+	}
+	return protectedName
+}
+
+// chatGetMessageLookalike is the same as the "chatGetLookalike()" function,
+// but for the sender of a chat message
+// Server messages cannot be impersonated
+func chatGetMessageLookalike(who string, server bool, discord bool) string {
+	if server {
+		return ""
+	}
+	return chatGetLookalike(who, !discord)
+}
@@ -0,0 +1,157 @@
+// Text macros are chat commands that expand into a snippet of text (e.g. "/shrug")
+// Admins can define additional macros in the file specified by the "CHAT_MACROS_FILE" environment
+// variable and reload them without restarting the server
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+	"github.com/sasha-s/go-deadlock"
+)
+
+type ChatMacros struct {
+	macros map[string]string // Indexed by the (lowercase) name of the macro
+	mutex  *deadlock.RWMutex
+}
+
+var (
+	chatMacros = NewChatMacros()
+
+	// These are always available, but they can be overwritten by the macros file
+	defaultChatMacros = map[string]string{
+		"shrug":     `¯\_(ツ)_/¯`,
+		"tableflip": "(╯°□°)╯︵ ┻━┻",
+		"unflip":    "┬─┬ ノ( ゜-゜ノ)",
+	}
+)
+
+func NewChatMacros() *ChatMacros {
+	return &ChatMacros{
+		macros: make(map[string]string),
+		mutex:  &deadlock.RWMutex{},
+	}
+}
+
+func chatMacrosInit() {
+	if err := chatMacros.Load(); err != nil {
+		logger.Fatal("Failed to load the chat macros: " + err.Error())
+	}
+}
+
+// Load reads the macros file (if any) and replaces the current set of macros
+// If the file cannot be read or parsed, the current set of macros is left untouched
+func (cm *ChatMacros) Load() error {
+	macros := make(map[string]string)
+	for name, text := range defaultChatMacros {
+		macros[name] = text
+	}
+
+	macrosPath := os.Getenv("CHAT_MACROS_FILE")
+	if len(macrosPath) > 0 {
+		if !filepath.IsAbs(macrosPath) {
+			macrosPath = path.Join(projectPath, macrosPath)
+		}
+
+		var fileContents []byte
+		if v, err := ioutil.ReadFile(macrosPath); err != nil {
+			return fmt.Errorf("failed to read the \"%v\" file: %w", macrosPath, err)
+		} else {
+			fileContents = v
+		}
+
+		// The file is a JSON object that maps the name of each macro to its text
+		var fileMacros map[string]string
+		if err := json.Unmarshal(fileContents, &fileMacros); err != nil {
+			return fmt.Errorf("failed to unmarshal the \"%v\" file: %w", macrosPath, err)
+		}
+
+		for name, text := range fileMacros {
+			name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "/"))
+			if name == "" || strings.Contains(name, " ") {
+				return fmt.Errorf("the macro name of \"%v\" is not valid", name)
+			}
+
+			// Real commands always take precedence over macros
+			if _, ok := chatCommandMap[name]; ok {
+				return fmt.Errorf("the macro name of \"%v\" is already used by a command", name)
+			}
+			if _, ok := chatCommandSilentMap[name]; ok {
+				return fmt.Errorf("the macro name of \"%v\" is already used by a command", name)
+			}
+			if _, ok := chatCommandAliases[name]; ok {
+				return fmt.Errorf("the macro name of \"%v\" is already used by an alias", name)
+			}
+
+			macros[name] = text
+		}
+	}
+
+	cm.mutex.Lock()
+	cm.macros = macros
+	cm.mutex.Unlock()
+
+	logger.Info("Loaded " + strconv.Itoa(len(macros)) + " chat macros.")
+	return nil
+}
+
+// Names returns a sorted list of the names of all of the macros
+// (the client needs to know them so that it does not report them as invalid commands)
+func (cm *ChatMacros) Names() []string {
+	cm.mutex.RLock()
+	names := make([]string, 0, len(cm.macros))
+	for name := range cm.macros {
+		names = append(names, name)
+	}
+	cm.mutex.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
+
+// NotifyAll sends every connected user the (new) list of macros
+func (cm *ChatMacros) NotifyAll() {
+	type ChatMacrosMessage struct {
+		ChatMacros []string `json:"chatMacros"`
+	}
+	chatMacrosMessage := &ChatMacrosMessage{
+		ChatMacros: cm.Names(),
+	}
+	for _, s := range sessions.GetList() {
+		s.Emit("chatMacros", chatMacrosMessage)
+	}
+}
+
+// Expand returns the message with the macro replaced by its text, if the message starts with a
+// macro
+// Like on Discord, any text after the macro is kept and the macro text is appended to it
+// (e.g. "/shrug I don't know" becomes "I don't know ¯\_(ツ)_/¯")
+func (cm *ChatMacros) Expand(msg string) string {
+	if !strings.HasPrefix(msg, "/") {
+		return msg
+	}
+
+	args := strings.SplitN(msg, " ", 2)
+	name := strings.ToLower(strings.TrimPrefix(args[0], "/")) // Macros are case-insensitive
+
+	cm.mutex.RLock()
+	text, ok := cm.macros[name]
+	cm.mutex.RUnlock()
+	if !ok {
+		return msg
+	}
+
+	if len(args) == 1 || strings.TrimSpace(args[1]) == "" {
+		return text
+	}
+	return args[1] + " " + text
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"strings"
+)
+
+// /me [action]
+// Action messages are stored and sent like any other message;
+// they are only converted to an action when they are sent to clients (in "chatParseAction()")
+func chatMe(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if len(d.Args) == 0 || strings.TrimSpace(strings.Join(d.Args, " ")) == "" {
+		msg := "The format of the /me command is: /me [action]"
+		chatServerSend(ctx, msg, d.Room, d.NoTablesLock)
+	}
+}
+
+// chatParseAction returns the message without the "/me " prefix and true if it is an action
+// message (e.g. "/me waves" should be shown as "* Alice waves")
+func chatParseAction(msg string) (string, bool) {
+	// Commands are case-insensitive
+	if len(msg) < len("/me ") || !strings.EqualFold(msg[:len("/me ")], "/me ") {
+		return msg, false
+	}
+
+	action := msg[len("/me "):]
+	if strings.TrimSpace(action) == "" {
+		return msg, false
+	}
+
+	return action, true
+}
+
+// chatDiscordAction formats an action message in the way that Discord shows its own "/me" messages
+func chatDiscordAction(username string, action string) string {
+	return "**" + username + "** _" + action + "_"
+}
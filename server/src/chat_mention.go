@@ -0,0 +1,126 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ChatMentionMessage is sent to a user when someone mentions them in a chat message
+// (so that the client can highlight the message and play a notification)
+type ChatMentionMessage struct {
+	ID   string `json:"id"`
+	Room string `json:"room"`
+	Who  string `json:"who"`
+	// From the "soundMention" setting of the recipient (in "chat_notifications.go")
+	Sound bool `json:"sound"`
+}
+
+var (
+	// Mentions are in the form of "@username"
+	// The "@" must not be preceded by a character that could be part of a username,
+	// so that e.g. email addresses do not count as mentions
+	localMentionRegExp = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_.\-@])@([\p{L}\p{N}_.\-]+)`)
+)
+
+// chatParseMentions returns all of the "@username" mentions in a message (without the "@")
+func chatParseMentions(msg string) []string {
+	mentions := make([]string, 0)
+	for _, match := range localMentionRegExp.FindAllStringSubmatch(msg, -1) {
+		mentions = append(mentions, match[1])
+	}
+	return mentions
+}
+
+// chatMentionMatches checks to see if a mention refers to a particular user (case-insensitive)
+// Usernames can end in a period or a hyphen, so we only strip them from the mention if the
+// full mention does not match (e.g. "Thanks @Alice.")
+func chatMentionMatches(mention string, username string) bool {
+	return strings.EqualFold(mention, username) ||
+		strings.EqualFold(strings.TrimRight(mention, ".-"), username)
+}
+
+// chatNotifyMentions sends a notification to everyone who was mentioned in a chat message
+// (or who has a highlight word that is in the message)
+// Only the people who can see the message are notified
+// (everyone for the lobby, or the players and spectators for a table)
+func chatNotifyMentions(s *Session, d *CommandData, t *Table, messageID string) {
+	if d.Server {
+		return
+	}
+
+	mentions := chatParseMentions(d.Msg)
+
+	var candidates []*Session
+	if t == nil {
+		candidates = sessions.GetList()
+	} else {
+		candidates = make([]*Session, 0)
+		if !t.Replay {
+			for _, p := range t.Players {
+				if p.Present {
+					candidates = append(candidates, p.Session)
+				}
+			}
+		}
+		for _, sp := range t.Spectators {
+			candidates = append(candidates, sp.Session)
+		}
+	}
+
+	notified := make(map[int]struct{})
+	for _, mention := range mentions {
+		for _, s2 := range candidates {
+			if s2 == nil || !chatMentionMatches(mention, s2.Username) {
+				continue
+			}
+			if s != nil && s2.UserID == s.UserID {
+				continue // Mentioning yourself does nothing
+			}
+			if _, ok := notified[s2.UserID]; ok {
+				continue
+			}
+			if chatIsIgnored(s2, s) {
+				continue
+			}
+
+			notified[s2.UserID] = struct{}{}
+			chatMentionSend(s2, d, messageID)
+		}
+	}
+
+	// People can also choose to be notified when someone says a particular word
+	// (in "chat_highlight.go")
+	// Someone who is mentioned and also has a highlight word in the message is only notified once
+	for _, s2 := range candidates {
+		if s2 == nil || (s != nil && s2.UserID == s.UserID) {
+			continue
+		}
+		if _, ok := notified[s2.UserID]; ok {
+			continue
+		}
+		word, ok := chatHighlightMatch(d.Msg, s2.ChatNotifications().HighlightWords)
+		if !ok {
+			continue
+		}
+		if chatIsIgnored(s2, s) {
+			continue
+		}
+
+		notified[s2.UserID] = struct{}{}
+		chatHighlightSend(s2, d, messageID, word)
+	}
+
+	// The people who are not online will be notified when they next log in
+	if len(mentions) > 0 {
+		chatMentionQueueOffline(s, d, t, mentions)
+	}
+}
+
+func chatMentionSend(s *Session, d *CommandData, messageID string) {
+	s.Emit("chatMention", &ChatMentionMessage{
+		ID:    messageID,
+		Room:  d.Room,
+		Who:   d.Username,
+		Sound: s.ChatNotifications().SoundMention,
+	})
+}
@@ -0,0 +1,44 @@
+// Messages that mention too many people or roles at once are rejected,
+// since a single message could otherwise be used to ping a large part of the community
+
+package main
+
+import (
+	"strings"
+)
+
+const (
+	// By default, a message can mention up to 5 different users and roles
+	DefaultChatMaxMentions = 5
+)
+
+var (
+	chatMaxMentions int
+)
+
+func chatMaxMentionsInit() {
+	chatMaxMentions = getEnvInt("CHAT_MAX_MENTIONS", DefaultChatMaxMentions)
+}
+
+// chatCountMentions returns the number of different Discord users, Discord roles,
+// and website users that are mentioned in a message
+// The message must already be HTML-escaped, but the mentions must not be filled in yet
+// (since "chatFillMentions()" and "chatFillRoles()" replace the Discord mentions with names)
+func chatCountMentions(msg string) int {
+	mentions := make(map[string]struct{})
+	for _, match := range mentionRegExp.FindAllStringSubmatch(msg, -1) {
+		mentions["user:"+match[1]] = struct{}{}
+	}
+	for _, match := range roleRegExp.FindAllStringSubmatch(msg, -1) {
+		mentions["role:"+match[1]] = struct{}{}
+	}
+
+	// Remove the Discord mentions so that the ID inside of them is not mistaken for a username
+	msg = mentionRegExp.ReplaceAllString(msg, " ")
+	msg = roleRegExp.ReplaceAllString(msg, " ")
+	for _, mention := range chatParseMentions(msg) {
+		mentions["local:"+strings.ToLower(mention)] = struct{}{}
+	}
+
+	return len(mentions)
+}
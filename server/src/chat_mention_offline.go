@@ -0,0 +1,149 @@
+// Users who are mentioned while they are offline are notified when they next log in
+// The notifications are stored in the same queue as private messages to offline users
+// (in "chat_pm_queue.go")
+
+package main
+
+import (
+	"strings"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+// chatMentionQueueOffline stores a notification for everyone who was mentioned in a chat message
+// but who is not currently online
+// Only the lobby and the tables that the mentioned person is playing at are important enough to
+// notify them
+// It is assumed that the table mutex is locked when calling this function (if it is a table)
+func chatMentionQueueOffline(s *Session, d *CommandData, t *Table, mentions []string) {
+	// Messages from the server and from Discord do not have a user to associate the notification
+	// with
+	if s == nil || d.Server || d.Discord || !chatPMQueueEnabled() {
+		return
+	}
+
+	room := "lobby"
+	var playerIDs map[int]struct{}
+	if t != nil {
+		if t.Replay {
+			return
+		}
+		room = t.Name
+		playerIDs = make(map[int]struct{})
+		for _, p := range t.Players {
+			playerIDs[p.UserID] = struct{}{}
+		}
+	}
+
+	// Mentions of people who are online were already handled in the "chatNotifyMentions()" function
+	sessionList := sessions.GetList()
+	offlineMentions := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, mention := range mentions {
+		normalizedMention := strings.ToLower(mention)
+		if _, ok := seen[normalizedMention]; ok {
+			continue
+		}
+		seen[normalizedMention] = struct{}{}
+
+		online := false
+		for _, s2 := range sessionList {
+			if chatMentionMatches(mention, s2.Username) {
+				online = true
+				break
+			}
+		}
+		if !online {
+			offlineMentions = append(offlineMentions, mention)
+		}
+	}
+	if len(offlineMentions) == 0 {
+		return
+	}
+
+	// Looking up the users requires the database, which should not delay the chat message
+	msg := d.Msg
+	go func() {
+		for _, mention := range offlineMentions {
+			recipient, ok := chatMentionGetUser(mention)
+			if !ok || recipient.ID == s.UserID {
+				continue
+			}
+			if playerIDs != nil {
+				if _, ok := playerIDs[recipient.ID]; !ok {
+					continue
+				}
+			}
+			chatMentionQueue(s, msg, recipient, room)
+		}
+	}()
+}
+
+// chatMentionGetUser returns false if the mention does not refer to a user in the database
+// (the same rules as the "chatMentionMatches()" function apply)
+func chatMentionGetUser(mention string) (User, bool) {
+	for _, username := range []string{mention, strings.TrimRight(mention, ".-")} {
+		if username == "" {
+			continue
+		}
+		if exists, user, err := models.Users.GetUserFromNormalizedUsername(
+			normalizeString(username),
+		); err != nil {
+			logger.Error("Failed to validate that \"" + username + "\" exists in the database: " +
+				err.Error())
+			return User{}, false
+		} else if exists {
+			return user, true
+		}
+	}
+
+	return User{}, false
+}
+
+func chatMentionQueue(s *Session, msg string, recipient User, room string) {
+	// Users can opt out of these notifications from the "Settings" tooltip in the lobby
+	if disabled, err := models.UserSettings.IsOfflineMentionsDisabled(recipient.ID); err != nil {
+		logger.Error("Failed to get the \"disable_offline_mentions\" setting for user " +
+			"\"" + recipient.Username + "\": " + err.Error())
+		return
+	} else if disabled {
+		return
+	}
+
+	// Moderators cannot be ignored, which matches the behavior of "chatIsIgnored()"
+	if !s.Moderator {
+		if ignoredMap, err := models.UserIgnores.GetMap(recipient.ID); err != nil {
+			logger.Error("Failed to get the ignored users for user \"" + recipient.Username +
+				"\": " + err.Error())
+			return
+		} else if _, ok := ignoredMap[s.UserID]; ok {
+			return
+		}
+	}
+
+	// Someone who keeps mentioning the same person in the same conversation only results in one
+	// notification
+	if queued, err := models.ChatLogPM.MentionQueued(s.UserID, recipient.ID, room); err != nil {
+		logger.Error("Failed to check for a queued mention for user \"" + recipient.Username +
+			"\": " + err.Error())
+		return
+	} else if queued {
+		return
+	}
+
+	// Notifications count towards the limit of messages that are waiting for a user
+	if count, err := models.ChatLogPM.CountUndelivered(
+		recipient.ID,
+		chatPMQueueExpiration(),
+	); err != nil {
+		logger.Error("Failed to count the undelivered private messages for user " +
+			"\"" + recipient.Username + "\": " + err.Error())
+		return
+	} else if count >= chatPMQueueSize {
+		return
+	}
+
+	if err := models.ChatLogPM.InsertMention(s.UserID, msg, recipient.ID, room); err != nil {
+		logger.Error("Failed to insert a mention notification into the database: " + err.Error())
+	}
+}
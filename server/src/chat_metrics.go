@@ -0,0 +1,164 @@
+// Chat volume is counted so that administrators can size the server and notice spam storms
+// The counters can be read in the Prometheus text format from the "/metrics" localhost route
+// (in "http_localhost_metrics.go")
+
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sasha-s/go-deadlock"
+)
+
+const (
+	// The "messages per second" gauge is averaged over this many seconds
+	ChatMetricsWindowSeconds = 60
+
+	// The reasons that a chat message can be dropped
+	ChatDroppedRateLimit = "rate_limit"
+	ChatDroppedFlood     = "flood"
+	ChatDroppedCooldown  = "cooldown"
+	ChatDroppedSlowMode  = "slow_mode"
+	// Messages that contain a banned word or pattern (in "chat_banned_words.go")
+	ChatDroppedBannedWord = "banned_word"
+	// Messages from non-moderators to a locked room (in "chat_lock_room.go")
+	ChatDroppedRoomLocked = "room_locked"
+	// Identical consecutive messages from the same Discord author (in "discord_dedup.go")
+	ChatDroppedDiscordDuplicate = "discord_duplicate"
+)
+
+var (
+	chatMetrics = NewChatMetrics()
+)
+
+type ChatMetrics struct {
+	// Indexed by room (e.g. "lobby" or "table123")
+	// Tables are removed once they are deleted so that the number of rooms does not grow forever
+	messages map[string]uint64
+	// The length of each message multiplied by the number of people that it was sent to
+	bytes   map[string]uint64
+	dropped map[string]uint64 // Indexed by reason (e.g. "rate_limit")
+	// The number of messages sent in each of the last seconds, for the "messages per second" gauge
+	// (the bucket for a second is reused once it is older than the window)
+	buckets     [ChatMetricsWindowSeconds]uint64
+	bucketTimes [ChatMetricsWindowSeconds]int64 // The Unix time of each bucket
+	mutex       *deadlock.Mutex
+}
+
+func NewChatMetrics() *ChatMetrics {
+	return &ChatMetrics{
+		messages:    make(map[string]uint64),
+		bytes:       make(map[string]uint64),
+		dropped:     make(map[string]uint64),
+		buckets:     [ChatMetricsWindowSeconds]uint64{},
+		bucketTimes: [ChatMetricsWindowSeconds]int64{},
+		mutex:       &deadlock.Mutex{},
+	}
+}
+
+// Sent records a message that was sent to a room
+func (cm *ChatMetrics) Sent(room string, msg string, numRecipients int) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	cm.messages[room]++
+	cm.bytes[room] += uint64(len(msg) * numRecipients)
+
+	now := time.Now().Unix()
+	i := now % ChatMetricsWindowSeconds
+	if cm.bucketTimes[i] != now {
+		cm.bucketTimes[i] = now
+		cm.buckets[i] = 0
+	}
+	cm.buckets[i]++
+}
+
+// Dropped records a message that was not sent because of one of the chat limits
+func (cm *ChatMetrics) Dropped(reason string) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	cm.dropped[reason]++
+}
+
+// DeleteRoom stops reporting the counters for a room
+func (cm *ChatMetrics) DeleteRoom(room string) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	delete(cm.messages, room)
+	delete(cm.bytes, room)
+}
+
+// Prometheus returns all of the metrics in the Prometheus text format
+// https://prometheus.io/docs/instrumenting/exposition_formats/
+func (cm *ChatMetrics) Prometheus() string {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	var sb strings.Builder
+
+	chatMetricsWriteMap(
+		&sb,
+		"hanabi_chat_messages_total",
+		"The number of chat messages sent to each room.",
+		"room",
+		cm.messages,
+	)
+	chatMetricsWriteMap(
+		&sb,
+		"hanabi_chat_broadcast_bytes_total",
+		"The number of bytes of chat messages sent to each room, counting every recipient.",
+		"room",
+		cm.bytes,
+	)
+	chatMetricsWriteMap(
+		&sb,
+		"hanabi_chat_dropped_messages_total",
+		"The number of chat messages that were not sent because of the chat limits.",
+		"reason",
+		cm.dropped,
+	)
+
+	now := time.Now().Unix()
+	var numRecent uint64
+	for i, bucket := range cm.buckets {
+		if now-cm.bucketTimes[i] < ChatMetricsWindowSeconds {
+			numRecent += bucket
+		}
+	}
+	messagesPerSecond := float64(numRecent) / ChatMetricsWindowSeconds
+	sb.WriteString("# HELP hanabi_chat_messages_per_second The average number of chat messages " +
+		"sent per second over the last " + strconv.Itoa(ChatMetricsWindowSeconds) + " seconds.\n")
+	sb.WriteString("# TYPE hanabi_chat_messages_per_second gauge\n")
+	sb.WriteString("hanabi_chat_messages_per_second " +
+		strconv.FormatFloat(messagesPerSecond, 'f', -1, 64) + "\n")
+
+	return sb.String()
+}
+
+func chatMetricsWriteMap(
+	sb *strings.Builder,
+	name string,
+	help string,
+	label string,
+	values map[string]uint64,
+) {
+	sb.WriteString("# HELP " + name + " " + help + "\n")
+	sb.WriteString("# TYPE " + name + " counter\n")
+
+	// Sort the keys so that the output is stable
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		sb.WriteString(name + "{" + label + "=" + strconv.Quote(key) + "} " +
+			strconv.FormatUint(values[key], 10) + "\n")
+	}
+}
@@ -0,0 +1,132 @@
+// The server can periodically broadcast a rotating list of messages to the lobby
+// (e.g. tips and reminders)
+
+package main
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+	"github.com/tevino/abool"
+)
+
+const (
+	// By default, a message is sent every 30 minutes
+	DefaultMOTDIntervalMinutes = 30
+)
+
+var (
+	motdMessages       []string
+	motdInterval       time.Duration
+	motdIndex          int // Only accessed from the "motdLoop()" goroutine
+	motdSaveToDatabase bool
+	motdEnabled        = abool.New()
+)
+
+func motdInit() {
+	// The messages are separated by a pipe character
+	motdMessages = make([]string, 0)
+	for _, msg := range strings.Split(os.Getenv("MOTD_MESSAGES"), "|") {
+		msg = strings.TrimSpace(msg)
+		if msg != "" {
+			motdMessages = append(motdMessages, msg)
+		}
+	}
+	if len(motdMessages) == 0 {
+		logger.Info("The \"MOTD_MESSAGES\" environment variable is blank; " +
+			"aborting MOTD initialization.")
+		return
+	}
+
+	motdIntervalMinutes := getEnvInt("MOTD_INTERVAL_MINUTES", DefaultMOTDIntervalMinutes)
+	if motdIntervalMinutes <= 0 {
+		logger.Info("The \"MOTD_INTERVAL_MINUTES\" environment variable is 0; " +
+			"aborting MOTD initialization.")
+		return
+	}
+	motdInterval = time.Duration(motdIntervalMinutes) * time.Minute
+
+	motdIndex = getEnvInt("MOTD_START_INDEX", 0)
+	if motdIndex < 0 || motdIndex >= len(motdMessages) {
+		logger.Fatal("The \"MOTD_START_INDEX\" environment variable must be between 0 and " +
+			strconv.Itoa(len(motdMessages)-1) + ".")
+		return
+	}
+
+	motdSaveToDatabase = getEnvInt("MOTD_SAVE_TO_DATABASE", 0) != 0
+
+	motdEnabled.Set()
+	go motdLoop()
+}
+
+func motdLoop() {
+	for {
+		time.Sleep(motdInterval)
+
+		// Moderators can pause the rotation with the "/motd" command
+		if motdEnabled.IsNotSet() {
+			continue
+		}
+
+		ctx := NewMiscContext("motd")
+		motdSend(ctx, motdMessages[motdIndex])
+		motdIndex = (motdIndex + 1) % len(motdMessages)
+	}
+}
+
+// motdSend is similar to the "chatServerSend()" function,
+// but the message can optionally be kept out of the database
+func motdSend(ctx context.Context, msg string) {
+	commandChat(ctx, nil, &CommandData{ // nolint: exhaustivestruct
+		Msg:        msg,
+		Room:       "lobby",
+		Server:     true,
+		NoDatabase: !motdSaveToDatabase,
+	})
+}
+
+// /motd [on/off]
+func chatMOTD(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if !s.Moderator {
+		chatServerSendPM(s, ChatMsgNotMod, d.Room)
+		return
+	}
+
+	if len(motdMessages) == 0 || motdInterval <= 0 {
+		msg := "Server messages are not configured. " +
+			"Set the \"MOTD_MESSAGES\" environment variable and restart the server."
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	if len(d.Args) == 0 {
+		msg := "Server messages are currently "
+		if motdEnabled.IsSet() {
+			msg += "on"
+		} else {
+			msg += "off"
+		}
+		msg += ". The format of the /motd command is: /motd [on/off]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	switch strings.ToLower(d.Args[0]) {
+	case "on":
+		motdEnabled.Set()
+	case "off":
+		motdEnabled.UnSet()
+	default:
+		msg := "The format of the /motd command is: /motd [on/off]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	logger.Info("Moderator \"" + s.Username + "\" turned server messages " +
+		strings.ToLower(d.Args[0]) + ".")
+	chatServerSendPM(s, "Server messages are now "+strings.ToLower(d.Args[0])+".", d.Room)
+}
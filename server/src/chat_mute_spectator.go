@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+)
+
+// /mutespectator [username]
+// The spectator can still watch, but their messages are no longer sent to the table
+// (this is separate from the server-wide mutes by IP address)
+func chatMuteSpectator(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if t == nil {
+		chatServerSendPM(s, ChatMsgNotInGame, d.Room)
+		return
+	}
+
+	if s.UserID != t.OwnerID && !s.Moderator {
+		chatServerSendPM(s, "Only the table owner or a moderator can mute spectators.", d.Room)
+		return
+	}
+
+	if len(d.Args) != 1 {
+		msg := "The format of the /mutespectator command is: /mutespectator [username]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	sp := chatMuteSpectatorFind(t, d.Args[0])
+	if sp == nil {
+		chatServerSendPM(s, "\""+d.Args[0]+"\" is not spectating at this table.", d.Room)
+		return
+	}
+
+	if sp.UserID == s.UserID {
+		chatServerSendPM(s, "You cannot mute yourself.", d.Room)
+		return
+	}
+
+	// Only moderators can mute other moderators
+	if sp.Session != nil && sp.Session.Moderator && !s.Moderator {
+		chatServerSendPM(s, "You cannot mute a moderator.", d.Room)
+		return
+	}
+
+	if _, ok := t.MutedSpectators[sp.UserID]; ok {
+		chatServerSendPM(s, "\""+sp.Name+"\" is already muted.", d.Room)
+		return
+	}
+	t.MutedSpectators[sp.UserID] = struct{}{}
+
+	msg := s.Username + " muted " + sp.Name + " for as long as they are spectating this table."
+	chatServerSend(ctx, msg, d.Room, d.NoTablesLock)
+}
+
+// /unmutespectator [username]
+// Moderators can unmute spectators that were muted by the table owner
+func chatUnmuteSpectator(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if t == nil {
+		chatServerSendPM(s, ChatMsgNotInGame, d.Room)
+		return
+	}
+
+	if s.UserID != t.OwnerID && !s.Moderator {
+		chatServerSendPM(s, "Only the table owner or a moderator can unmute spectators.", d.Room)
+		return
+	}
+
+	if len(d.Args) != 1 {
+		msg := "The format of the /unmutespectator command is: /unmutespectator [username]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	sp := chatMuteSpectatorFind(t, d.Args[0])
+	if sp == nil {
+		chatServerSendPM(s, "\""+d.Args[0]+"\" is not spectating at this table.", d.Room)
+		return
+	}
+
+	if _, ok := t.MutedSpectators[sp.UserID]; !ok {
+		chatServerSendPM(s, "\""+sp.Name+"\" is not muted.", d.Room)
+		return
+	}
+	delete(t.MutedSpectators, sp.UserID)
+
+	msg := s.Username + " unmuted " + sp.Name + "."
+	chatServerSend(ctx, msg, d.Room, d.NoTablesLock)
+}
+
+func chatMuteSpectatorFind(t *Table, username string) *Spectator {
+	normalizedUsername := normalizeString(username)
+	for _, sp := range t.Spectators {
+		if normalizeString(sp.Name) == normalizedUsername {
+			return sp
+		}
+	}
+
+	return nil
+}
+
+// chatMutedSpectatorCheck returns false if the user is a spectator that was muted at this table
+// It is assumed that the table mutex is locked when calling this function
+func chatMutedSpectatorCheck(s *Session, d *CommandData, t *Table) bool {
+	if s == nil || d.Server || d.Discord {
+		return true
+	}
+
+	if _, ok := t.MutedSpectators[s.UserID]; !ok {
+		return true
+	}
+
+	chatServerSendPM(s, "You have been muted at this table, so your message was not sent.", d.Room)
+	return false
+}
@@ -0,0 +1,114 @@
+// Clients can attach a random nonce to each chat message that they send,
+// which is echoed back in the "chat" message that is broadcast for it
+// If the client does not see its nonce in time (e.g. because the connection dropped while it was
+// sending the message), it sends the message again with the same nonce
+// The server ignores the copies that it has already received (and acknowledges them with a
+// "chatAck" message instead), so that retrying never results in duplicate messages
+
+package main
+
+import (
+	"time"
+
+	"github.com/sasha-s/go-deadlock"
+)
+
+const (
+	// Clients must stop retrying a message before this long,
+	// since the server forgets the nonces after this
+	ChatNonceExpiration = 2 * time.Minute
+	// Longer nonces are ignored (and the message is treated as if it did not have one)
+	ChatNonceMaxLength = 64
+)
+
+var (
+	chatNonces = NewChatNonces()
+)
+
+type ChatNonces struct {
+	received   map[int]map[string]time.Time // Indexed by user ID and then by nonce
+	lastPurged time.Time
+	mutex      *deadlock.Mutex
+}
+
+// ChatAckMessage is sent to a user when they resend a chat message that was already received
+type ChatAckMessage struct {
+	Nonce string `json:"nonce"`
+	Room  string `json:"room"`
+}
+
+func NewChatNonces() *ChatNonces {
+	return &ChatNonces{
+		received:   make(map[int]map[string]time.Time),
+		lastPurged: time.Now(),
+		mutex:      &deadlock.Mutex{},
+	}
+}
+
+// Add records a nonce for a user
+// It returns false if the user already sent a message with this nonce recently
+func (cn *ChatNonces) Add(userID int, nonce string) bool {
+	cn.mutex.Lock()
+	defer cn.mutex.Unlock()
+
+	now := time.Now()
+	cn.purge(now)
+
+	nonces, ok := cn.received[userID]
+	if !ok {
+		nonces = make(map[string]time.Time)
+		cn.received[userID] = nonces
+	}
+	if datetime, ok := nonces[nonce]; ok && now.Sub(datetime) < ChatNonceExpiration {
+		return false
+	}
+	nonces[nonce] = now
+
+	return true
+}
+
+// purge discards the nonces that have expired (at most once a minute,
+// so that we do not have to iterate over every nonce every time that a message is sent)
+// It is assumed that the mutex is locked when calling this function
+func (cn *ChatNonces) purge(now time.Time) {
+	if now.Sub(cn.lastPurged) < time.Minute {
+		return
+	}
+	cn.lastPurged = now
+
+	for userID, nonces := range cn.received {
+		for nonce, datetime := range nonces {
+			if now.Sub(datetime) >= ChatNonceExpiration {
+				delete(nonces, nonce)
+			}
+		}
+		if len(nonces) == 0 {
+			delete(cn.received, userID)
+		}
+	}
+}
+
+// chatNonceCheck returns false if the message is a copy of one that the user already sent
+// (messages from the server and from Discord never have a nonce)
+func chatNonceCheck(s *Session, d *CommandData) bool {
+	if s == nil || d.Server || d.Discord || d.Nonce == "" {
+		d.Nonce = ""
+		return true
+	}
+	if len(d.Nonce) > ChatNonceMaxLength {
+		d.Nonce = ""
+		return true
+	}
+
+	if chatNonces.Add(s.UserID, d.Nonce) {
+		return true
+	}
+
+	// The original message might have already been rejected (e.g. because of the rate limit),
+	// but either way, the client does not need to send it again
+	s.Emit("chatAck", &ChatAckMessage{
+		Nonce: d.Nonce,
+		Room:  d.Room,
+	})
+	return false
+}
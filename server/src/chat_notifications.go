@@ -0,0 +1,103 @@
+// The chat notification settings are stored in the database (with the rest of the settings),
+// so that they are the same on every computer that a user logs in from
+// They are also stored on the session, since they are checked every time that a chat message is
+// sent to someone
+
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	ChatHighlightWordsMax      = 10
+	ChatHighlightWordMaxLength = 32
+)
+
+type ChatNotificationSettings struct {
+	SoundMention bool
+	SoundPM      bool
+	// Users are notified when someone says one of these words, like when they are mentioned
+	// (they are always lowercase)
+	HighlightWords []string
+}
+
+func NewChatNotificationSettings(settings Settings) ChatNotificationSettings {
+	return ChatNotificationSettings{
+		SoundMention:   settings.SoundMention,
+		SoundPM:        settings.SoundPM,
+		HighlightWords: chatParseHighlightWords(settings.ChatHighlightWords),
+	}
+}
+
+// chatValidateHighlightWords normalizes the "chatHighlightWords" setting (a comma-separated list)
+// so that it can be stored in the database
+// It returns an empty string and false if any of the words are not valid
+func chatValidateHighlightWords(setting string) (string, bool) {
+	words := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, word := range strings.Split(setting, ",") {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" {
+			continue
+		}
+		if _, ok := seen[word]; ok {
+			continue
+		}
+		if utf8.RuneCountInString(word) > ChatHighlightWordMaxLength {
+			return "", false
+		}
+		for _, r := range word {
+			if !chatIsHighlightWordRune(r) {
+				return "", false
+			}
+		}
+
+		seen[word] = struct{}{}
+		words = append(words, word)
+	}
+	if len(words) > ChatHighlightWordsMax {
+		return "", false
+	}
+
+	return strings.Join(words, ","), true
+}
+
+// chatParseHighlightWords converts the "chatHighlightWords" setting to a slice of words
+// The setting has already been validated by the "chatValidateHighlightWords()" function
+func chatParseHighlightWords(setting string) []string {
+	words := make([]string, 0)
+	for _, word := range strings.Split(setting, ",") {
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// chatHighlightMatch returns the first of the words that is in the message as a whole word
+// (case-insensitive), so that e.g. a highlight word of "bob" does not match "bobcat"
+// It returns false if none of the words are in the message
+func chatHighlightMatch(msg string, words []string) (string, bool) {
+	if len(words) == 0 {
+		return "", false
+	}
+
+	msgWords := strings.FieldsFunc(strings.ToLower(msg), func(r rune) bool {
+		return !chatIsHighlightWordRune(r)
+	})
+	for _, msgWord := range msgWords {
+		for _, word := range words {
+			if msgWord == word {
+				return word, true
+			}
+		}
+	}
+	return "", false
+}
+
+func chatIsHighlightWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_' || r == '-'
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+)
+
+// ChatPinMessage is sent to clients when a message has been pinned to the top of the chat
+type ChatPinMessage struct {
+	ID   string `json:"id"`
+	Room string `json:"room"`
+}
+
+// /pin [id]
+func chatPin(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if t == nil {
+		chatServerSendPM(s, ChatMsgNotInGame, d.Room)
+		return
+	}
+
+	if s.UserID != t.OwnerID && !s.Moderator {
+		chatServerSendPM(s, "Only the table owner or a moderator can pin messages.", d.Room)
+		return
+	}
+
+	if len(d.Args) != 1 {
+		msg := "The format of the /pin command is: /pin [id]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+	messageID := d.Args[0]
+
+	found := false
+	for _, chatMsg := range t.Chat {
+		if chatMsg.ID == messageID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		chatServerSendPM(s, ChatMsgMessageNotFound, d.Room, messageID)
+		return
+	}
+
+	// Only one message can be pinned at a time, so this implicitly unpins the previous message
+	t.PinnedMessageID = messageID
+
+	t.NotifyChatPin(&ChatPinMessage{
+		ID:   messageID,
+		Room: d.Room,
+	})
+}
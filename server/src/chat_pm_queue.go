@@ -0,0 +1,178 @@
+// Private messages to users who are offline are stored in the database and delivered when they
+// next log in (instead of being lost)
+
+package main
+
+import (
+	"html"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+const (
+	// By default, users can have up to 50 messages waiting for them,
+	// and messages that are not delivered within 30 days are discarded
+	DefaultChatPMQueueSize = 50
+	DefaultChatPMQueueDays = 30
+)
+
+var (
+	chatPMQueueSize int
+	chatPMQueueDays int
+)
+
+func chatPMQueueInit() {
+	chatPMQueueSize = getEnvInt("CHAT_PM_QUEUE_SIZE", DefaultChatPMQueueSize)
+	chatPMQueueDays = getEnvInt("CHAT_PM_QUEUE_DAYS", DefaultChatPMQueueDays)
+}
+
+// chatPMQueueEnabled returns false if private messages to offline users should be rejected
+func chatPMQueueEnabled() bool {
+	return chatPMQueueSize > 0 && chatPMQueueDays > 0
+}
+
+// chatPMQueueExpiration returns the time before which undelivered messages are discarded
+func chatPMQueueExpiration() time.Time {
+	return time.Now().Add(-time.Duration(chatPMQueueDays) * 24 * time.Hour)
+}
+
+// chatPMQueue stores a private message for a user who is offline
+// It is assumed that the message has already been sanitized and escaped
+func chatPMQueue(s *Session, d *CommandData, recipient User) {
+	// Log the message
+	// (only the metadata is logged, in the same way as in the "chatPM()" function)
+	logger.Info("PM <" + s.Username + "> --> <" + recipient.Username + "> (offline) " +
+		"(" + strconv.Itoa(utf8.RuneCountInString(d.Msg)) + " characters)")
+
+	// Messages from ignored users are recorded, but they are never delivered
+	// (moderators cannot be ignored, which matches the behavior of "chatIsIgnored()")
+	delivered := false
+	if !s.Moderator {
+		if ignoredMap, err := models.UserIgnores.GetMap(recipient.ID); err != nil {
+			logger.Error("Failed to get the ignored users for user \"" + recipient.Username +
+				"\": " + err.Error())
+			s.Error(DefaultErrorMsg)
+			return
+		} else if _, ok := ignoredMap[s.UserID]; ok {
+			delivered = true
+		}
+	}
+
+	if !delivered {
+		if count, err := models.ChatLogPM.CountUndelivered(
+			recipient.ID,
+			chatPMQueueExpiration(),
+		); err != nil {
+			logger.Error("Failed to count the undelivered private messages for user " +
+				"\"" + recipient.Username + "\": " + err.Error())
+			s.Error(DefaultErrorMsg)
+			return
+		} else if count >= chatPMQueueSize {
+			chatServerSendPM(s, ChatMsgPMQueueFull, d.Room, recipient.Username)
+			return
+		}
+	}
+
+	if err := models.ChatLogPM.Insert(s.UserID, d.Msg, recipient.ID, delivered); err != nil {
+		logger.Error("Failed to insert a private message into the database: " + err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	}
+
+	// Echo the private message back to the person who sent it
+	s.Emit("chat", &ChatMessage{
+		ID:        newChatMessageID(),
+		Msg:       d.Msg,
+		Who:       s.Username,
+		Discord:   false,
+		Server:    false,
+		Datetime:  time.Now(),
+		Room:      "",
+		Recipient: recipient.Username,
+		Reactions: make(map[string]int),
+		ReplyTo:   "",
+		Quote:     nil,
+		Action:    false,
+	})
+	chatServerSendPM(s, ChatMsgPMQueued, d.Room, recipient.Username, chatPMQueueDays)
+}
+
+// chatPMDeliverQueued sends a user who just logged in all of the private messages that were sent
+// to them while they were offline (and the mentions that they missed)
+func chatPMDeliverQueued(s *Session) {
+	if !chatPMQueueEnabled() {
+		return
+	}
+
+	var pms []QueuedPM
+	if v, err := models.ChatLogPM.Deliver(s.UserID); err != nil {
+		logger.Error("Failed to get the undelivered private messages for user \"" + s.Username +
+			"\": " + err.Error())
+		return
+	} else {
+		pms = v
+	}
+
+	// Expired messages are still marked as delivered so that they do not count towards the limit
+	expiration := chatPMQueueExpiration()
+	recentPMs := make([]QueuedPM, 0, len(pms))
+	for _, pm := range pms {
+		if pm.Datetime.After(expiration) {
+			recentPMs = append(recentPMs, pm)
+		}
+	}
+	if len(recentPMs) == 0 {
+		return
+	}
+	sort.Slice(recentPMs, func(i, j int) bool {
+		return recentPMs[i].Datetime.Before(recentPMs[j].Datetime)
+	})
+
+	// Notifications for mentions are stored in the same queue (in "chat_mention_offline.go")
+	mentions := make([]QueuedPM, 0)
+	privateMessages := make([]QueuedPM, 0)
+	for _, pm := range recentPMs {
+		if pm.MentionRoom.Valid {
+			mentions = append(mentions, pm)
+		} else {
+			privateMessages = append(privateMessages, pm)
+		}
+	}
+
+	for _, mention := range mentions {
+		if mention.MentionRoom.String == "lobby" {
+			chatServerSendPM(s, ChatMsgMentionedInLobby, "lobby", mention.Username, mention.Message)
+		} else {
+			// Unlike the message, the table name was not escaped before it was stored
+			chatServerSendPM(s, ChatMsgMentionedAtTable, "lobby", mention.Username,
+				html.EscapeString(mention.MentionRoom.String), mention.Message)
+		}
+	}
+	if len(privateMessages) == 0 {
+		return
+	}
+
+	chatServerSendPM(s, ChatMsgPMsWhileAway, "lobby", len(privateMessages))
+	for _, pm := range privateMessages {
+		messageID := newChatMessageID()
+		pmReceipts.Add(messageID, pm.UserID, s.UserID)
+		s.Emit("chat", &ChatMessage{
+			ID:        messageID,
+			Msg:       pm.Message,
+			Who:       pm.Username,
+			Discord:   false,
+			Server:    false,
+			Datetime:  pm.Datetime,
+			Room:      "",
+			Recipient: s.Username,
+			Reactions: make(map[string]int),
+			ReplyTo:   "",
+			Quote:     nil,
+			Action:    false,
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// When a client renders a private message that was sent to them, it sends a "chatRead" command
+// with the ID of the message, which is relayed back to the sender as a "chatReceipt" command
+// Only private messages are tracked, so public messages can never have a read receipt
+
+package main
+
+import (
+	"time"
+
+	"github.com/sasha-s/go-deadlock"
+)
+
+const (
+	// Private messages that have not been read after this long are forgotten
+	PMReceiptExpiration = 24 * time.Hour
+)
+
+var (
+	pmReceipts = NewPMReceipts()
+)
+
+type PMReceipts struct {
+	receipts   map[string]*PMReceipt // Indexed by message ID
+	lastPurged time.Time
+	mutex      *deadlock.Mutex
+}
+
+type PMReceipt struct {
+	SenderID    int
+	RecipientID int
+	Datetime    time.Time
+}
+
+// ChatReceiptMessage is sent to the sender of a private message when the recipient has read it
+type ChatReceiptMessage struct {
+	ID        string    `json:"id"`
+	Recipient string    `json:"recipient"`
+	Datetime  time.Time `json:"datetime"`
+}
+
+func NewPMReceipts() *PMReceipts {
+	return &PMReceipts{
+		receipts:   make(map[string]*PMReceipt),
+		lastPurged: time.Now(),
+		mutex:      &deadlock.Mutex{},
+	}
+}
+
+// Add starts tracking a private message that was delivered to the recipient
+func (pr *PMReceipts) Add(messageID string, senderID int, recipientID int) {
+	pr.mutex.Lock()
+	defer pr.mutex.Unlock()
+
+	now := time.Now()
+	pr.receipts[messageID] = &PMReceipt{
+		SenderID:    senderID,
+		RecipientID: recipientID,
+		Datetime:    now,
+	}
+
+	// Discard the messages that were never read (at most once an hour,
+	// so that we do not have to iterate over every message every time that a message is sent)
+	if now.Sub(pr.lastPurged) < time.Hour {
+		return
+	}
+	pr.lastPurged = now
+	for messageID2, receipt := range pr.receipts {
+		if now.Sub(receipt.Datetime) >= PMReceiptExpiration {
+			delete(pr.receipts, messageID2)
+		}
+	}
+}
+
+// Read stops tracking a private message and returns the user ID of the sender
+// It returns false if the message is not tracked or if it was not sent to this recipient,
+// so each message can only be acknowledged once
+func (pr *PMReceipts) Read(messageID string, recipientID int) (int, bool) {
+	pr.mutex.Lock()
+	defer pr.mutex.Unlock()
+
+	receipt, ok := pr.receipts[messageID]
+	if !ok || receipt.RecipientID != recipientID {
+		return 0, false
+	}
+	delete(pr.receipts, messageID)
+
+	if time.Since(receipt.Datetime) >= PMReceiptExpiration {
+		return 0, false
+	}
+	return receipt.SenderID, true
+}
@@ -0,0 +1,235 @@
+// In a replay (e.g. a teaching session with hypotheticals), the table owner can ask the spectators
+// to vote on the best play with the "/poll" command
+// Votes are sent with the "chatVote" command (or the "/vote" chat command) and the tallies are sent
+// to everyone in a "chatPoll" message every time that they change
+// Votes are anonymous, unless the poll was started with the "/publicpoll" command,
+// in which case the names of the voters are shown in the final results
+
+package main
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	ChatPollMinOptions = 2
+	ChatPollMaxOptions = 10
+)
+
+type ChatPoll struct {
+	ID       string
+	Question string         // HTML-escaped
+	Options  []string       // HTML-escaped
+	Votes    map[int]int    // Indexed by user ID, the values are the option indexes
+	Voters   map[int]string // Indexed by user ID, the values are the usernames
+	Public   bool
+}
+
+// ChatPollMessage is sent to clients when a poll is started, when the tallies change,
+// and when it is closed
+type ChatPollMessage struct {
+	ID       string   `json:"id"`
+	Room     string   `json:"room"`
+	Question string   `json:"question"`
+	Options  []string `json:"options"`
+	Tallies  []int    `json:"tallies"` // The number of votes for each option
+	Public   bool     `json:"public"`
+	Closed   bool     `json:"closed"`
+}
+
+// /poll [question] | [option 1] | [option 2]
+func chatPoll(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	chatPollStart(ctx, s, d, t, false)
+}
+
+// /publicpoll [question] | [option 1] | [option 2]
+func chatPublicPoll(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	chatPollStart(ctx, s, d, t, true)
+}
+
+func chatPollStart(ctx context.Context, s *Session, d *CommandData, t *Table, public bool) {
+	if t == nil || !t.Replay {
+		chatServerSendPM(s, "You can only start a poll in a replay.", d.Room)
+		return
+	}
+
+	if s.UserID != t.OwnerID && !s.Moderator {
+		chatServerSendPM(s, "Only the table owner or a moderator can start a poll.", d.Room)
+		return
+	}
+
+	if t.Poll != nil {
+		chatServerSendPM(s, "There is already a poll in progress. Use /closepoll to close it.",
+			d.Room)
+		return
+	}
+
+	// The question and the options were already escaped in the "commandChat()" function
+	parts := strings.Split(strings.Join(d.Args, " "), "|")
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	question := parts[0]
+	options := parts[1:]
+	valid := question != "" &&
+		len(options) >= ChatPollMinOptions &&
+		len(options) <= ChatPollMaxOptions
+	for _, option := range options {
+		if option == "" {
+			valid = false
+		}
+	}
+	if !valid {
+		msg := "The format of the /poll command is: /poll [question] | [option 1] | [option 2] " +
+			"(with up to " + strconv.Itoa(ChatPollMaxOptions) + " options)"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	t.Poll = &ChatPoll{
+		ID:       newChatMessageID(),
+		Question: question,
+		Options:  options,
+		Votes:    make(map[int]int),
+		Voters:   make(map[int]string),
+		Public:   public,
+	}
+	t.NotifyChatPoll(t.Poll.ToMessage(d.Room, false))
+
+	msg := s.Username + " started a poll: " + question + " "
+	for i, option := range options {
+		msg += "[" + strconv.Itoa(i+1) + "] " + option + " "
+	}
+	msg += "(vote with: /vote [number]"
+	if public {
+		msg += "; the votes are public"
+	}
+	msg += ")"
+	chatServerSend(ctx, msg, d.Room, d.NoTablesLock)
+}
+
+// /closepoll
+// Close the poll and post the final results
+func chatClosePoll(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if t == nil || t.Poll == nil {
+		chatServerSendPM(s, "There is no poll in progress.", d.Room)
+		return
+	}
+
+	if s.UserID != t.OwnerID && !s.Moderator {
+		chatServerSendPM(s, "Only the table owner or a moderator can close the poll.", d.Room)
+		return
+	}
+
+	poll := t.Poll
+	t.Poll = nil
+	t.NotifyChatPoll(poll.ToMessage(d.Room, true))
+	chatServerSend(ctx, poll.GetResults(), d.Room, d.NoTablesLock)
+}
+
+// /vote [number]
+// This is the same as the "chatVote" command (for clients that do not show the poll)
+func chatVote(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if t == nil || t.Poll == nil {
+		chatServerSendPM(s, "There is no poll in progress.", d.Room)
+		return
+	}
+
+	if len(d.Args) != 1 {
+		chatServerSendPM(s, "The format of the /vote command is: /vote [number]", d.Room)
+		return
+	}
+
+	option, err := strconv.Atoi(d.Args[0])
+	if err != nil || option < 1 || option > len(t.Poll.Options) {
+		msg := "The number must be between 1 and " + strconv.Itoa(len(t.Poll.Options)) + "."
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	// The options are 0-indexed in the "chatVote" command
+	t.Poll.Vote(s.UserID, s.Username, option-1)
+	t.NotifyChatPoll(t.Poll.ToMessage(d.Room, false))
+}
+
+// Vote records the vote of a user, replacing their previous vote (if any)
+// The option must have already been validated
+func (poll *ChatPoll) Vote(userID int, username string, option int) {
+	poll.Votes[userID] = option
+	poll.Voters[userID] = username
+}
+
+func (poll *ChatPoll) GetTallies() []int {
+	tallies := make([]int, len(poll.Options))
+	for _, option := range poll.Votes {
+		tallies[option]++
+	}
+	return tallies
+}
+
+func (poll *ChatPoll) ToMessage(room string, closed bool) *ChatPollMessage {
+	return &ChatPollMessage{
+		ID:       poll.ID,
+		Room:     room,
+		Question: poll.Question,
+		Options:  poll.Options,
+		Tallies:  poll.GetTallies(),
+		Public:   poll.Public,
+		Closed:   closed,
+	}
+}
+
+// GetResults describes the final tallies and the option with the most votes
+// (the question and the options are already escaped, so this can be sent as a server message)
+func (poll *ChatPoll) GetResults() string {
+	tallies := poll.GetTallies()
+
+	// The voters are listed in alphabetical order, so that the order does not reveal anything
+	votersByOption := make([][]string, len(poll.Options))
+	if poll.Public {
+		for userID, option := range poll.Votes {
+			votersByOption[option] = append(votersByOption[option], poll.Voters[userID])
+		}
+		for _, voters := range votersByOption {
+			sort.Strings(voters)
+		}
+	}
+
+	results := make([]string, 0, len(poll.Options))
+	maxVotes := 0
+	for i, option := range poll.Options {
+		result := option + ": " + strconv.Itoa(tallies[i]) + " "
+		if tallies[i] == 1 {
+			result += "vote"
+		} else {
+			result += "votes"
+		}
+		if len(votersByOption[i]) > 0 {
+			result += " (" + strings.Join(votersByOption[i], ", ") + ")"
+		}
+		results = append(results, result)
+
+		if tallies[i] > maxVotes {
+			maxVotes = tallies[i]
+		}
+	}
+
+	msg := "The poll has closed: " + poll.Question + " " + strings.Join(results, ", ") + ". "
+	if maxVotes == 0 {
+		return msg + "No-one voted."
+	}
+
+	winners := make([]string, 0)
+	for i, option := range poll.Options {
+		if tallies[i] == maxVotes {
+			winners = append(winners, option)
+		}
+	}
+	if len(winners) == 1 {
+		return msg + "The group's choice is: " + winners[0]
+	}
+	return msg + "It is a tie between: " + strings.Join(winners, ", ")
+}
@@ -11,6 +11,19 @@ import (
 	"github.com/Hanabi-Live/hanabi-live/logger"
 )
 
+var (
+	// The number of seconds before an automatic start (from the "/startin" command) that a
+	// countdown is sent to the table (0 disables the countdown)
+	startInCountdownSeconds int
+)
+
+func startInCountdownInit() {
+	startInCountdownSeconds = getEnvInt("STARTIN_COUNTDOWN_SECONDS", 3)
+	if startInCountdownSeconds < 0 {
+		logger.Fatal("The \"STARTIN_COUNTDOWN_SECONDS\" environment variable cannot be negative.")
+	}
+}
+
 /*
 	Pregame chat commands
 */
@@ -329,26 +342,25 @@ func startIn(
 	timeToWait time.Duration,
 	datetimePlannedStart time.Time,
 ) {
-	// Sleep until it is time to automatically start
-	time.Sleep(timeToWait)
-
-	// Check to see if the table still exists
-	t2, exists := getTableAndLock(ctx, nil, t.ID, false, false)
-	if !exists || t != t2 {
-		return
+	// Sleep until it is time to begin the countdown
+	// (the countdown cannot be longer than the time to wait)
+	countdownSeconds := startInCountdownSeconds
+	if waitSeconds := int(timeToWait.Seconds()); countdownSeconds > waitSeconds {
+		countdownSeconds = waitSeconds
 	}
-	t.Lock(ctx)
-	defer t.Unlock(ctx)
+	time.Sleep(timeToWait - time.Duration(countdownSeconds)*time.Second)
 
-	// Check to see if the game has already started
-	if t.Running {
-		return
+	for i := countdownSeconds; i > 0; i-- {
+		if !startInCountdown(ctx, t, datetimePlannedStart, i) {
+			return
+		}
+		time.Sleep(time.Second)
 	}
 
-	// Check to see if the planned start time has changed
-	if datetimePlannedStart != t.DatetimePlannedStart {
+	if !startInLock(ctx, t, datetimePlannedStart) {
 		return
 	}
+	defer t.Unlock(ctx)
 
 	// Check to see if the owner is present
 	for _, p := range t.Players {
@@ -371,6 +383,57 @@ func startIn(
 	logger.Error("Failed to find the owner of the game when attempting to automatically start it.")
 }
 
+// startInCountdown returns false if the automatic start was aborted
+func startInCountdown(
+	ctx context.Context,
+	t *Table,
+	datetimePlannedStart time.Time,
+	secondsLeft int,
+) bool {
+	if !startInLock(ctx, t, datetimePlannedStart) {
+		return false
+	}
+	defer t.Unlock(ctx)
+
+	// The countdown is not written to the database or kept in the chat history,
+	// since it is only relevant at the moment that it is sent
+	commandChat(ctx, nil, &CommandData{ // nolint: exhaustivestruct
+		Msg:         "The game starts in " + strconv.Itoa(secondsLeft) + "...",
+		Room:        t.GetRoomName(),
+		Server:      true,
+		NoTableLock: true,
+		NoDatabase:  true,
+	})
+
+	return true
+}
+
+// startInLock locks the table and returns true if the automatic start should still happen
+// (e.g. it returns false if someone joined or left the table since the "/startin" command,
+// which cancels the automatic start)
+func startInLock(ctx context.Context, t *Table, datetimePlannedStart time.Time) bool {
+	// Check to see if the table still exists
+	t2, exists := getTableAndLock(ctx, nil, t.ID, false, false)
+	if !exists || t != t2 {
+		return false
+	}
+	t.Lock(ctx)
+
+	// Check to see if the game has already started
+	if t.Running {
+		t.Unlock(ctx)
+		return false
+	}
+
+	// Check to see if the planned start time has changed
+	if datetimePlannedStart != t.DatetimePlannedStart {
+		t.Unlock(ctx)
+		return false
+	}
+
+	return true
+}
+
 func chatImpostor(ctx context.Context, s *Session, d *CommandData, t *Table) {
 	if t == nil || d.Room == "lobby" {
 		chatServerSend(ctx, NotInGameFail, d.Room, d.NoTablesLock)
@@ -403,6 +466,7 @@ func chatImpostor(ctx context.Context, s *Session, d *CommandData, t *Table) {
 		}
 
 		chatMessage := &ChatMessage{
+			ID:        newChatMessageID(),
 			Msg:       msg,
 			Who:       WebsiteName,
 			Discord:   false,
@@ -410,6 +474,10 @@ func chatImpostor(ctx context.Context, s *Session, d *CommandData, t *Table) {
 			Datetime:  time.Now(),
 			Room:      d.Room,
 			Recipient: p.Session.Username,
+			Reactions: make(map[string]int),
+			ReplyTo:   "",
+			Quote:     nil,
+			Action:    false,
 		}
 		p.Session.Emit("chat", chatMessage)
 	}
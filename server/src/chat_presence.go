@@ -0,0 +1,31 @@
+// Mobile clients show a presence dot next to the sender of each chat message
+// The presence is sent with the message so that they do not have to look up the sender in the
+// user list (which they might not have downloaded)
+
+package main
+
+const (
+	ChatPresenceInGame     = "inGame"
+	ChatPresenceSpectating = "spectating"
+	ChatPresenceIdle       = "idle"
+)
+
+// chatGetPresence returns the presence of the sender of a chat message, based on their status
+// It is blank when it is not known (e.g. for server messages and messages from Discord),
+// so that it is omitted from the message
+func chatGetPresence(s *Session, d *CommandData) string {
+	if s == nil || d.Server || d.Discord {
+		return ""
+	}
+
+	switch s.Status() {
+	case StatusPregame, StatusPlaying:
+		return ChatPresenceInGame
+	case StatusSpectating, StatusReplay, StatusSharedReplay:
+		return ChatPresenceSpectating
+	case StatusLobby:
+		return ChatPresenceIdle
+	default:
+		return ""
+	}
+}
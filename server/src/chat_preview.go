@@ -0,0 +1,297 @@
+// Link previews (e.g. the title of a YouTube video) for URLs that are posted in chat
+// They are disabled unless the "CHAT_PREVIEW_HOSTS" environment variable is set
+// The preview is fetched in the background and sent to clients in a separate "chatPreview" message,
+// so that it does not delay the original chat message
+
+package main
+
+import (
+	"context"
+	"errors"
+	"html"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+const (
+	ChatPreviewTimeout = 3 * time.Second
+	// Only the beginning of the page is read, since the metadata is in the "<head>" element
+	ChatPreviewMaxBytes = 256 * 1024
+	// Long descriptions are truncated
+	ChatPreviewMaxLength = 300
+)
+
+type ChatPreviewMessage struct {
+	ID          string `json:"id"` // The ID of the chat message that contains the URL
+	Room        string `json:"room"`
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
+}
+
+var (
+	// The hosts that we are allowed to fetch previews from (which prevents SSRF attacks);
+	// nil if link previews are disabled
+	// Subdomains are also allowed (e.g. "youtube.com" also allows "www.youtube.com")
+	chatPreviewHosts []string
+
+	chatPreviewURLRegExp   = regexp.MustCompile(`https?://[^\s<>"']+`)
+	chatPreviewMetaRegExp  = regexp.MustCompile(`(?i)<meta\s[^>]*>`)
+	chatPreviewAttrRegExp  = regexp.MustCompile(`(?i)([a-z:-]+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	chatPreviewTitleRegExp = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+	// Even if a host is on the allowlist, it should never resolve to an internal address
+	chatPreviewPrivateNetworks = []string{
+		"10.0.0.0/8",
+		"100.64.0.0/10",
+		"172.16.0.0/12",
+		"192.168.0.0/16",
+		"fc00::/7",
+	}
+	chatPreviewPrivateIPNets []*net.IPNet
+
+	chatPreviewClient = &http.Client{ // nolint: exhaustivestruct
+		Timeout: ChatPreviewTimeout,
+		Transport: &http.Transport{ // nolint: exhaustivestruct
+			DialContext: (&net.Dialer{ // nolint: exhaustivestruct
+				Timeout: ChatPreviewTimeout,
+				Control: chatPreviewDialControl,
+			}).DialContext,
+			TLSHandshakeTimeout:   ChatPreviewTimeout,
+			ResponseHeaderTimeout: ChatPreviewTimeout,
+		},
+		// Redirects must also point to a host on the allowlist
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 3 {
+				return errors.New("too many redirects")
+			}
+			if !chatPreviewHostAllowed(req.URL) {
+				return errors.New("the redirect to \"" + req.URL.Host + "\" is not allowed")
+			}
+			return nil
+		},
+	}
+)
+
+func chatPreviewInit() {
+	hostsString := os.Getenv("CHAT_PREVIEW_HOSTS")
+	if len(hostsString) == 0 {
+		return
+	}
+
+	chatPreviewHosts = make([]string, 0)
+	for _, host := range strings.Split(hostsString, ",") {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host != "" {
+			chatPreviewHosts = append(chatPreviewHosts, host)
+		}
+	}
+
+	for _, network := range chatPreviewPrivateNetworks {
+		if _, ipNet, err := net.ParseCIDR(network); err != nil {
+			logger.Fatal("Failed to parse the network of \"" + network + "\": " + err.Error())
+			return
+		} else {
+			chatPreviewPrivateIPNets = append(chatPreviewPrivateIPNets, ipNet)
+		}
+	}
+}
+
+// chatPreviewStart looks for a URL in a chat message and, if it is from an allowed host,
+// fetches the preview in a new goroutine and sends it to the provided sessions
+// The recipients must be determined by the caller, since the table might not exist anymore
+// by the time that the preview is fetched
+func chatPreviewStart(messageID string, room string, msg string, recipients []*Session) {
+	if chatPreviewHosts == nil || messageID == "" || len(recipients) == 0 {
+		return
+	}
+
+	// The message has already been HTML-escaped (e.g. "&" is now "&amp;")
+	match := chatPreviewURLRegExp.FindString(html.UnescapeString(msg))
+	if match == "" {
+		return
+	}
+
+	var u *url.URL
+	if v, err := url.Parse(match); err != nil {
+		return
+	} else {
+		u = v
+	}
+	if !chatPreviewHostAllowed(u) {
+		return
+	}
+
+	go chatPreviewFetch(messageID, room, u, recipients)
+}
+
+func chatPreviewFetch(messageID string, room string, u *url.URL, recipients []*Session) {
+	var preview *ChatPreviewMessage
+	if v, err := chatPreviewGet(u); err != nil {
+		// Broken links are common, so this is not an error
+		logger.Info("Failed to get the link preview for \"" + u.String() + "\": " + err.Error())
+		return
+	} else {
+		preview = v
+	}
+	if preview.Title == "" && preview.Description == "" {
+		return
+	}
+	preview.ID = messageID
+	preview.Room = room
+
+	for _, s := range recipients {
+		s.Emit("chatPreview", preview)
+	}
+}
+
+func chatPreviewGet(u *url.URL) (*ChatPreviewMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ChatPreviewTimeout)
+	defer cancel()
+
+	var req *http.Request
+	if v, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil); err != nil {
+		return nil, err
+	} else {
+		req = v
+	}
+	req.Header.Set("User-Agent", WebsiteName+" link preview")
+	req.Header.Set("Accept", "text/html")
+
+	var resp *http.Response
+	if v, err := chatPreviewClient.Do(req); err != nil {
+		return nil, err
+	} else {
+		resp = v
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("the status code was " + resp.Status)
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		return nil, errors.New("the content type was \"" + resp.Header.Get("Content-Type") + "\"")
+	}
+
+	var body []byte
+	if v, err := ioutil.ReadAll(io.LimitReader(resp.Body, ChatPreviewMaxBytes)); err != nil {
+		return nil, err
+	} else {
+		body = v
+	}
+	page := string(body)
+
+	// Parse the OpenGraph tags, e.g. <meta property="og:title" content="Foo">
+	// https://ogp.me/
+	tags := make(map[string]string)
+	for _, meta := range chatPreviewMetaRegExp.FindAllString(page, -1) {
+		var name, content string
+		for _, attr := range chatPreviewAttrRegExp.FindAllStringSubmatch(meta, -1) {
+			value := attr[2] + attr[3] // Only one of the two quote styles will match
+			switch strings.ToLower(attr[1]) {
+			case "property", "name":
+				name = strings.ToLower(value)
+			case "content":
+				content = value
+			}
+		}
+		if name != "" && content != "" {
+			if _, ok := tags[name]; !ok {
+				tags[name] = content
+			}
+		}
+	}
+
+	title := tags["og:title"]
+	if title == "" {
+		if match := chatPreviewTitleRegExp.FindStringSubmatch(page); match != nil {
+			title = match[1]
+		}
+	}
+	description := tags["og:description"]
+	if description == "" {
+		description = tags["description"]
+	}
+	image := tags["og:image"]
+	if !strings.HasPrefix(image, "https://") && !strings.HasPrefix(image, "http://") {
+		image = ""
+	}
+
+	return &ChatPreviewMessage{
+		ID:          "",
+		Room:        "",
+		URL:         html.EscapeString(u.String()),
+		Title:       chatPreviewCleanText(title),
+		Description: chatPreviewCleanText(description),
+		Image:       html.EscapeString(image),
+	}, nil
+}
+
+// chatPreviewCleanText decodes a value from a web page and re-escapes it
+// (since the client renders chat messages as HTML)
+func chatPreviewCleanText(s string) string {
+	s = html.UnescapeString(s)
+	s = htmlTagRegExp.ReplaceAllString(s, "")
+	s = strings.Join(strings.Fields(s), " ") // Collapse all whitespace
+	if utf8.RuneCountInString(s) > ChatPreviewMaxLength {
+		s = string([]rune(s)[:ChatPreviewMaxLength]) + "..."
+	}
+	return html.EscapeString(s)
+}
+
+func chatPreviewHostAllowed(u *url.URL) bool {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, allowedHost := range chatPreviewHosts {
+		if host == allowedHost || strings.HasSuffix(host, "."+allowedHost) {
+			return true
+		}
+	}
+	return false
+}
+
+// chatPreviewDialControl is run before every connection is made (including for redirects),
+// after the host has been resolved to an IP address
+func chatPreviewDialControl(network string, address string, c syscall.RawConn) error {
+	var host string
+	if v, _, err := net.SplitHostPort(address); err != nil {
+		return err
+	} else {
+		host = v
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return errors.New("failed to parse the IP address of \"" + host + "\"")
+	}
+	if ip.IsLoopback() ||
+		ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() {
+		return errors.New("the IP address of \"" + host + "\" is not allowed")
+	}
+	for _, ipNet := range chatPreviewPrivateIPNets {
+		if ipNet.Contains(ip) {
+			return errors.New("the IP address of \"" + host + "\" is not allowed")
+		}
+	}
+
+	return nil
+}
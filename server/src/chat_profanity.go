@@ -0,0 +1,112 @@
+// An optional server-side profanity filter for family-friendly deployments
+// It is disabled unless the "PROFANITY_FILTER_WORD_LIST" environment variable is set
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+var (
+	// The set of (lowercase) words to filter out; nil if the filter is disabled
+	profanityWords map[string]struct{}
+	// Whether or not common character substitutions (e.g. "a55") should also be caught
+	profanityLeetspeak bool
+
+	// We only look at whole words so that e.g. "classic" is left alone
+	profanityWordRegExp          = regexp.MustCompile(`[\p{L}\p{N}_]+`)
+	profanityWordLeetspeakRegExp = regexp.MustCompile(`[\p{L}\p{N}_@$]+`)
+	profanityLeetspeakReplacer   = strings.NewReplacer(
+		"4", "a",
+		"@", "a",
+		"8", "b",
+		"3", "e",
+		"6", "g",
+		"1", "i",
+		"0", "o",
+		"5", "s",
+		"$", "s",
+		"7", "t",
+	)
+)
+
+func profanityFilterInit() {
+	wordListPath := os.Getenv("PROFANITY_FILTER_WORD_LIST")
+	if len(wordListPath) == 0 {
+		return
+	}
+	if !filepath.IsAbs(wordListPath) {
+		wordListPath = path.Join(projectPath, wordListPath)
+	}
+
+	var fileContents []byte
+	if v, err := ioutil.ReadFile(wordListPath); err != nil {
+		logger.Fatal("Failed to read the \"" + wordListPath + "\" file: " + err.Error())
+		return
+	} else {
+		fileContents = v
+	}
+
+	// The file has one word per line; blank lines and lines starting with "#" are ignored
+	profanityWords = make(map[string]struct{})
+	for _, line := range strings.Split(string(fileContents), "\n") {
+		word := strings.ToLower(strings.TrimSpace(line))
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		profanityWords[word] = struct{}{}
+	}
+
+	profanityLeetspeak = getEnvInt("PROFANITY_FILTER_LEETSPEAK", 0) != 0
+
+	logger.Info("Loaded " + strconv.Itoa(len(profanityWords)) + " words for the profanity filter.")
+}
+
+// chatFilterProfanity replaces every word that is on the word list with asterisks
+func chatFilterProfanity(msg string) string {
+	if profanityWords == nil {
+		return msg
+	}
+
+	wordRegExp := profanityWordRegExp
+	if profanityLeetspeak {
+		wordRegExp = profanityWordLeetspeakRegExp
+	}
+
+	return wordRegExp.ReplaceAllStringFunc(msg, func(word string) string {
+		if !isProfanity(word) {
+			return word
+		}
+		return strings.Repeat("*", utf8.RuneCountInString(word))
+	})
+}
+
+func isProfanity(word string) bool {
+	word = strings.ToLower(word)
+	if _, ok := profanityWords[word]; ok {
+		return true
+	}
+
+	if profanityLeetspeak {
+		// "1" can be used for both "i" and "l"
+		normalizedWord := profanityLeetspeakReplacer.Replace(word)
+		if _, ok := profanityWords[normalizedWord]; ok {
+			return true
+		}
+		normalizedWord = profanityLeetspeakReplacer.Replace(strings.ReplaceAll(word, "1", "l"))
+		if _, ok := profanityWords[normalizedWord]; ok {
+			return true
+		}
+	}
+
+	return false
+}
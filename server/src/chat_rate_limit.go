@@ -0,0 +1,86 @@
+// Chat messages are rate-limited per user and per room to prevent someone from spamming a room
+// faster than a moderator can react
+// (this is separate from the WebSocket rate-limiting in "websocket_message.go",
+// which is meant to prevent the server itself from being flooded)
+
+package main
+
+import (
+	"time"
+
+	"github.com/sasha-s/go-deadlock"
+)
+
+const (
+	// By default, users can send up to 10 messages every 10 seconds in a single room
+	DefaultChatRateLimitMessages = 10
+	DefaultChatRateLimitSeconds  = 10
+)
+
+var (
+	chatRateLimitMessages int
+	chatRateLimitWindow   time.Duration
+	chatRateLimiter       = NewChatRateLimiter()
+)
+
+type ChatRateLimiter struct {
+	// Indexed by user ID, then by room
+	// The values are the times that each recent message was sent
+	history map[int]map[string][]time.Time
+	mutex   *deadlock.Mutex
+}
+
+func NewChatRateLimiter() *ChatRateLimiter {
+	return &ChatRateLimiter{
+		history: make(map[int]map[string][]time.Time),
+		mutex:   &deadlock.Mutex{},
+	}
+}
+
+func chatRateLimitInit() {
+	chatRateLimitMessages = getEnvInt("CHAT_RATE_LIMIT_MESSAGES", DefaultChatRateLimitMessages)
+	chatRateLimitSeconds := getEnvInt("CHAT_RATE_LIMIT_SECONDS", DefaultChatRateLimitSeconds)
+	chatRateLimitWindow = time.Duration(chatRateLimitSeconds) * time.Second
+}
+
+// Check uses a sliding window to see if the user is allowed to send another message to the room
+// If they are, the message is recorded and true is returned
+func (rl *ChatRateLimiter) Check(userID int, room string) bool {
+	// A value of 0 or less disables rate-limiting
+	if chatRateLimitMessages <= 0 || chatRateLimitWindow <= 0 {
+		return true
+	}
+
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	rooms, ok := rl.history[userID]
+	if !ok {
+		rooms = make(map[string][]time.Time)
+		rl.history[userID] = rooms
+	}
+
+	// Discard any messages that have fallen outside of the window
+	// (for every room, so that the map does not grow forever)
+	now := time.Now()
+	for roomName, datetimesSent := range rooms {
+		recentMessages := make([]time.Time, 0, len(datetimesSent))
+		for _, datetimeSent := range datetimesSent {
+			if now.Sub(datetimeSent) < chatRateLimitWindow {
+				recentMessages = append(recentMessages, datetimeSent)
+			}
+		}
+		if len(recentMessages) == 0 {
+			delete(rooms, roomName)
+		} else {
+			rooms[roomName] = recentMessages
+		}
+	}
+
+	if len(rooms[room]) >= chatRateLimitMessages {
+		return false
+	}
+
+	rooms[room] = append(rooms[room], now)
+	return true
+}
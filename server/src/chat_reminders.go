@@ -0,0 +1,401 @@
+// Recurring reminders can be posted to the lobby on a schedule (e.g. for a weekly tournament)
+// They are defined in the file specified by the "CHAT_REMINDERS_FILE" environment variable,
+// which is read again when the server restarts (or when the reminders are reloaded),
+// so the schedules do not have to be stored anywhere else
+// Disabling a reminder only lasts until the next reload; to disable it permanently,
+// set "disabled" to true in the file
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+	"github.com/sasha-s/go-deadlock"
+)
+
+const (
+	// Schedules that do not match any time in this many years are considered to be invalid
+	// (e.g. "0 0 31 2 *")
+	CronMaxYears = 5
+)
+
+var (
+	chatReminders = NewChatReminders()
+)
+
+type ChatReminders struct {
+	reminders map[string]*ChatReminder // Indexed by the (lowercase) name of the reminder
+	// Incremented every time that the reminders are reloaded,
+	// so that the goroutines for the old reminders know to stop
+	generation int
+	mutex      *deadlock.Mutex
+}
+
+// ChatReminder is an entry in the reminders file, e.g.
+// {"name": "tournament", "msg": "Weekly tournament in 1 hour!", "schedule": "0 19 * * 6",
+// "timezone": "America/New_York"}
+type ChatReminder struct {
+	Name string `json:"name"`
+	Msg  string `json:"msg"`
+	// In the same format as cron: "minute hour day-of-month month day-of-week"
+	Schedule string `json:"schedule"`
+	// A name from the tz database; blank for UTC
+	Timezone string `json:"timezone"`
+	Disabled bool   `json:"disabled"`
+
+	cron     *CronSchedule
+	location *time.Location
+	next     time.Time
+}
+
+func NewChatReminders() *ChatReminders {
+	return &ChatReminders{
+		reminders:  make(map[string]*ChatReminder),
+		generation: 0,
+		mutex:      &deadlock.Mutex{},
+	}
+}
+
+func chatRemindersInit() {
+	if err := chatReminders.Load(); err != nil {
+		logger.Fatal("Failed to load the chat reminders: " + err.Error())
+	}
+}
+
+// Load reads the reminders file (if any) and replaces the current set of reminders
+// If the file cannot be read or parsed, the current set of reminders is left untouched
+func (cr *ChatReminders) Load() error {
+	reminders := make(map[string]*ChatReminder)
+
+	remindersPath := os.Getenv("CHAT_REMINDERS_FILE")
+	if len(remindersPath) > 0 {
+		if !filepath.IsAbs(remindersPath) {
+			remindersPath = path.Join(projectPath, remindersPath)
+		}
+
+		var fileContents []byte
+		if v, err := ioutil.ReadFile(remindersPath); err != nil {
+			return fmt.Errorf("failed to read the \"%v\" file: %w", remindersPath, err)
+		} else {
+			fileContents = v
+		}
+
+		// The file is a JSON array of reminders
+		var fileReminders []*ChatReminder
+		if err := json.Unmarshal(fileContents, &fileReminders); err != nil {
+			return fmt.Errorf("failed to unmarshal the \"%v\" file: %w", remindersPath, err)
+		}
+
+		for _, reminder := range fileReminders {
+			reminder.Name = strings.TrimSpace(reminder.Name)
+			if reminder.Name == "" || strings.Contains(reminder.Name, " ") {
+				return fmt.Errorf("the reminder name of \"%v\" is not valid", reminder.Name)
+			}
+			name := strings.ToLower(reminder.Name)
+			if _, ok := reminders[name]; ok {
+				return fmt.Errorf("there is more than one reminder named \"%v\"", reminder.Name)
+			}
+			if strings.TrimSpace(reminder.Msg) == "" {
+				return fmt.Errorf("the reminder \"%v\" does not have a message", reminder.Name)
+			}
+
+			if v, err := parseCronSchedule(reminder.Schedule); err != nil {
+				return fmt.Errorf(
+					"the schedule of \"%v\" for the reminder \"%v\" is not valid: %w",
+					reminder.Schedule,
+					reminder.Name,
+					err,
+				)
+			} else {
+				reminder.cron = v
+			}
+
+			if v, ok := chatParseTimezone(reminder.Timezone); !ok {
+				return fmt.Errorf(
+					"the timezone of \"%v\" for the reminder \"%v\" is not valid",
+					reminder.Timezone,
+					reminder.Name,
+				)
+			} else {
+				reminder.location = v
+			}
+
+			if _, ok := reminder.cron.Next(time.Now().In(reminder.location)); !ok {
+				return fmt.Errorf(
+					"the schedule of \"%v\" for the reminder \"%v\" never happens",
+					reminder.Schedule,
+					reminder.Name,
+				)
+			}
+
+			reminders[name] = reminder
+		}
+	}
+
+	cr.mutex.Lock()
+	cr.generation++
+	generation := cr.generation
+	cr.reminders = reminders
+	for _, reminder := range reminders {
+		if !reminder.Disabled {
+			go cr.wait(generation, reminder)
+		}
+	}
+	cr.mutex.Unlock()
+
+	logger.Info("Loaded " + strconv.Itoa(len(reminders)) + " chat reminders.")
+	return nil
+}
+
+func (cr *ChatReminders) wait(generation int, reminder *ChatReminder) {
+	from := time.Now()
+	for {
+		next, ok := reminder.cron.Next(from.In(reminder.location))
+		if !ok {
+			// This was already checked when the reminders were loaded
+			logger.Error("Failed to get the next time for reminder \"" + reminder.Name + "\".")
+			return
+		}
+
+		cr.mutex.Lock()
+		reminder.next = next
+		cr.mutex.Unlock()
+
+		time.Sleep(time.Until(next))
+
+		// Do nothing if the reminders were reloaded or if this one was disabled while we were
+		// sleeping
+		if !cr.isActive(generation, reminder) {
+			return
+		}
+
+		// The next time is calculated from the time that was just used,
+		// so that waking up early cannot send the same reminder twice
+		// (but if the server was asleep for a while, the missed reminders are skipped)
+		from = next
+		if now := time.Now(); now.After(from) {
+			from = now
+		}
+
+		logger.Info("Sending chat reminder \"" + reminder.Name + "\".")
+		ctx := NewMiscContext("chatReminder")
+		chatServerSend(ctx, reminder.Msg, "lobby", false)
+	}
+}
+
+func (cr *ChatReminders) isActive(generation int, reminder *ChatReminder) bool {
+	cr.mutex.Lock()
+	defer cr.mutex.Unlock()
+
+	return generation == cr.generation && !reminder.Disabled
+}
+
+// Disable returns false if there is no enabled reminder with the given name
+func (cr *ChatReminders) Disable(name string) bool {
+	cr.mutex.Lock()
+	defer cr.mutex.Unlock()
+
+	reminder, ok := cr.reminders[strings.ToLower(name)]
+	if !ok || reminder.Disabled {
+		return false
+	}
+	reminder.Disabled = true
+
+	logger.Info("Disabled chat reminder \"" + reminder.Name + "\".")
+	return true
+}
+
+// List returns a description of every reminder, sorted by name
+func (cr *ChatReminders) List() []string {
+	cr.mutex.Lock()
+	defer cr.mutex.Unlock()
+
+	names := make([]string, 0, len(cr.reminders))
+	for name := range cr.reminders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		reminder := cr.reminders[name]
+		line := reminder.Name + " - \"" + reminder.Schedule + "\" (" + reminder.location.String() +
+			") - "
+		if reminder.Disabled {
+			line += "disabled"
+		} else if reminder.next.IsZero() {
+			line += "pending"
+		} else {
+			line += "next at " + reminder.next.Format(time.RFC3339)
+		}
+		line += " - " + reminder.Msg
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// CronSchedule is a parsed cron expression, where each field is a bit mask of the allowed values
+type CronSchedule struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+	// Like in cron, if both the day of the month and the day of the week are restricted,
+	// a day matches if either of them match
+	anyDay     bool
+	anyWeekday bool
+}
+
+// parseCronSchedule supports "*", numbers, lists (e.g. "1,15"), ranges (e.g. "1-5"),
+// and steps (e.g. "*/15" or "0-30/10")
+// Sunday is both 0 and 7 in the day of the week field
+func parseCronSchedule(schedule string) (*CronSchedule, error) {
+	fields := strings.Fields(schedule)
+	if len(fields) != 5 {
+		return nil, errors.New("it must have 5 fields")
+	}
+
+	cs := &CronSchedule{} // nolint: exhaustivestruct
+	type cronField struct {
+		name string
+		mask *uint64
+		min  int
+		max  int
+	}
+	cronFields := []cronField{
+		{name: "minute", mask: &cs.minutes, min: 0, max: 59},
+		{name: "hour", mask: &cs.hours, min: 0, max: 23},
+		{name: "day of the month", mask: &cs.days, min: 1, max: 31},
+		{name: "month", mask: &cs.months, min: 1, max: 12},
+		{name: "day of the week", mask: &cs.weekdays, min: 0, max: 7},
+	}
+	for i, field := range cronFields {
+		if v, err := parseCronField(fields[i], field.min, field.max); err != nil {
+			return nil, fmt.Errorf("the %v field of \"%v\" is not valid: %w", field.name, fields[i],
+				err)
+		} else {
+			*field.mask = v
+		}
+	}
+
+	// Sunday can be written as 7
+	if cs.weekdays&(1<<7) != 0 {
+		cs.weekdays = (cs.weekdays &^ (1 << 7)) | 1
+	}
+	cs.anyDay = fields[2] == "*"
+	cs.anyWeekday = fields[4] == "*"
+
+	return cs, nil
+}
+
+func parseCronField(field string, min int, max int) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i != -1 {
+			if v, err := strconv.Atoi(part[i+1:]); err != nil || v < 1 {
+				return 0, errors.New("the step must be a positive number")
+			} else {
+				step = v
+			}
+			part = part[:i]
+		}
+
+		start := min
+		end := max
+		if part != "*" {
+			bounds := strings.Split(part, "-")
+			if len(bounds) > 2 {
+				return 0, errors.New("a range must have a start and an end")
+			}
+			if v, err := strconv.Atoi(bounds[0]); err != nil {
+				return 0, errors.New("\"" + bounds[0] + "\" is not a number")
+			} else {
+				start = v
+			}
+			end = start
+			if len(bounds) == 2 {
+				if v, err := strconv.Atoi(bounds[1]); err != nil {
+					return 0, errors.New("\"" + bounds[1] + "\" is not a number")
+				} else {
+					end = v
+				}
+			}
+			if start < min || end > max || start > end {
+				return 0, errors.New("the values must be between " + strconv.Itoa(min) + " and " +
+					strconv.Itoa(max))
+			}
+		}
+
+		for i := start; i <= end; i += step {
+			mask |= 1 << uint(i)
+		}
+	}
+
+	return mask, nil
+}
+
+func (cs *CronSchedule) dayMatches(t time.Time) bool {
+	dayMatches := cs.days&(1<<uint(t.Day())) != 0
+	weekdayMatches := cs.weekdays&(1<<uint(t.Weekday())) != 0
+	if cs.anyDay || cs.anyWeekday {
+		return dayMatches && weekdayMatches
+	}
+	return dayMatches || weekdayMatches
+}
+
+// Next returns the first time that matches the schedule after the given time,
+// in the location of the given time
+// It returns false if the schedule does not match any time in the next few years
+func (cs *CronSchedule) Next(from time.Time) (time.Time, bool) {
+	loc := from.Location()
+	t := from.Truncate(time.Minute).Add(time.Minute)
+	end := t.AddDate(CronMaxYears, 0, 0)
+
+	// Whenever a field does not match, we skip ahead to the start of the next value of that field
+	for t.Before(end) {
+		if cs.months&(1<<uint(t.Month())) == 0 {
+			t = cronAdvance(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc))
+			continue
+		}
+		if !cs.dayMatches(t) {
+			t = cronAdvance(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc))
+			continue
+		}
+		if cs.hours&(1<<uint(t.Hour())) == 0 {
+			t = cronAdvance(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc))
+			continue
+		}
+		if cs.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t, true
+	}
+
+	return time.Time{}, false
+}
+
+// cronAdvance returns the start of the next value of a field if it is after the current time
+// A wall time inside of a daylight saving time gap (e.g. 02:00 when the clocks go forward) can be
+// normalized by "time.Date()" to a time before the gap, so in that case we step forward to the
+// next hour by absolute time instead (which always makes progress)
+func cronAdvance(t time.Time, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+	return t.Add(time.Duration(60-t.Minute()) * time.Minute)
+}
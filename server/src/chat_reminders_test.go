@@ -0,0 +1,141 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronScheduleInvalid(t *testing.T) {
+	schedules := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"a * * * *",
+		"1-2-3 * * * *",
+		"5-1 * * * *",
+	}
+	for _, schedule := range schedules {
+		if _, err := parseCronSchedule(schedule); err == nil {
+			t.Errorf("parseCronSchedule(%q) did not return an error", schedule)
+		}
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("the tz database is not available: " + err.Error())
+	}
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skip("the tz database is not available: " + err.Error())
+	}
+
+	tests := []struct {
+		name     string
+		schedule string
+		from     time.Time
+		expected time.Time
+	}{
+		{
+			name:     "weekly",
+			schedule: "0 19 * * 6",
+			from:     time.Date(2026, 10, 14, 12, 0, 0, 0, newYork), // A Wednesday
+			expected: time.Date(2026, 10, 17, 19, 0, 0, 0, newYork),
+		},
+		{
+			name:     "strictly after the given time",
+			schedule: "0 19 * * 6",
+			from:     time.Date(2026, 10, 17, 19, 0, 0, 0, newYork),
+			expected: time.Date(2026, 10, 24, 19, 0, 0, 0, newYork),
+		},
+		{
+			name:     "seconds are ignored",
+			schedule: "* * * * *",
+			from:     time.Date(2026, 1, 1, 0, 0, 30, 0, time.UTC),
+			expected: time.Date(2026, 1, 1, 0, 1, 0, 0, time.UTC),
+		},
+		{
+			name:     "steps and ranges",
+			schedule: "*/15 9-17 * * *",
+			from:     time.Date(2026, 1, 1, 17, 50, 0, 0, time.UTC),
+			expected: time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "day of the month or day of the week",
+			schedule: "0 0 15 * 1",
+			from:     time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC), // A Wednesday
+			expected: time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Sunday as 7",
+			schedule: "0 0 * * 7",
+			from:     time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC),
+			expected: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "month",
+			schedule: "0 12 1 3 *",
+			from:     time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC),
+			expected: time.Date(2027, 3, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "timezone with a half-hour offset",
+			schedule: "0 20 * * *",
+			from:     time.Date(2026, 10, 14, 12, 0, 0, 0, kolkata),
+			expected: time.Date(2026, 10, 14, 20, 0, 0, 0, kolkata),
+		},
+		{
+			// 02:00 to 02:59 does not exist on this day, so the next match is on the day after
+			name:     "daylight saving time gap",
+			schedule: "30 2 * * *",
+			from:     time.Date(2026, 3, 8, 0, 0, 0, 0, newYork),
+			expected: time.Date(2026, 3, 9, 2, 30, 0, 0, newYork),
+		},
+		{
+			name:     "after a daylight saving time gap",
+			schedule: "0 19 * * *",
+			from:     time.Date(2026, 3, 8, 0, 0, 0, 0, newYork),
+			expected: time.Date(2026, 3, 8, 19, 0, 0, 0, newYork),
+		},
+		{
+			name:     "daylight saving time end",
+			schedule: "0 19 * * *",
+			from:     time.Date(2026, 11, 1, 0, 0, 0, 0, newYork),
+			expected: time.Date(2026, 11, 1, 19, 0, 0, 0, newYork),
+		},
+	}
+
+	for _, test := range tests {
+		cs, err := parseCronSchedule(test.schedule)
+		if err != nil {
+			t.Errorf("%v: failed to parse %q: %v", test.name, test.schedule, err)
+			continue
+		}
+
+		next, ok := cs.Next(test.from)
+		if !ok {
+			t.Errorf("%v: Next(%v) did not find a time", test.name, test.from)
+			continue
+		}
+		if !next.Equal(test.expected) {
+			t.Errorf("%v: Next(%v) = %v, expected %v", test.name, test.from, next, test.expected)
+		}
+	}
+}
+
+func TestCronScheduleNextNever(t *testing.T) {
+	cs, err := parseCronSchedule("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next, ok := cs.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); ok {
+		t.Errorf("Next() = %v, expected no time", next)
+	}
+}
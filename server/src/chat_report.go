@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"html"
+	"strings"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+// /report [id] [reason]
+// The message is copied to the "chat_reports" table so that the moderators can still see it
+// after it is deleted
+// Reports are rate-limited by the "/report" entry in "chat_cooldown.go"
+func chatReport(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if len(d.Args) < 1 {
+		msg := "The format of the /report command is: /report [id] [reason]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+	messageID := d.Args[0]
+	reason := strings.Join(d.Args[1:], " ")
+
+	// Look for the message in the in-memory chat first
+	// (table messages are also written to the database as they are sent, but some are only in
+	// memory, e.g. the messages from the server in a replay)
+	found := false
+	var userID int
+	var name string
+	var message string
+	var server bool
+	if t != nil {
+		for _, chatMsg := range t.Chat {
+			if chatMsg.ID == messageID {
+				found = true
+				userID = chatMsg.UserID
+				name = chatMsg.Username
+				message = chatMsg.Msg
+				server = chatMsg.Server
+				break
+			}
+		}
+	}
+	if !found {
+		// The review comments in a replay are stored in a different room, in "chat_review.go"
+		room := d.Room
+		if t != nil && t.GetReviewRoomName() != "" {
+			room = t.GetReviewRoomName()
+		}
+		if v1, v2, v3, v4, err := models.ChatLog.GetMessage(messageID, room); err != nil {
+			logger.Error("Failed to get chat message \"" + messageID + "\": " + err.Error())
+			s.Error(DefaultErrorMsg)
+			return
+		} else {
+			found = v1
+			userID = v2
+			name = v3
+			message = v4
+			server = name == "__server"
+		}
+	}
+
+	if !found {
+		chatServerSendPM(s, ChatMsgMessageNotFound, d.Room, messageID)
+		return
+	}
+
+	if server {
+		chatServerSendPM(s, "You cannot report messages from the server.", d.Room)
+		return
+	}
+
+	if userID == s.UserID {
+		chatServerSendPM(s, "You cannot report your own messages.", d.Room)
+		return
+	}
+
+	if exists, err := models.ChatReports.Exists(s.UserID, messageID); err != nil {
+		logger.Error("Failed to check for an existing report of chat message \"" + messageID +
+			"\": " + err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else if exists {
+		chatServerSendPM(s, "You have already reported that message.", d.Room)
+		return
+	}
+
+	if err := models.ChatReports.Insert(
+		s.UserID,
+		messageID,
+		d.Room,
+		userID,
+		name,
+		message,
+		reason,
+	); err != nil {
+		logger.Error("Failed to insert a report for chat message \"" + messageID + "\": " +
+			err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	}
+
+	logger.Info("User \"" + s.Username + "\" reported chat message \"" + messageID + "\" " +
+		"from room \"" + d.Room + "\" (sent by \"" + name + "\"): " + message)
+
+	// Let the moderators who are online know about the report in the lobby
+	// (the text of the message and the reason were already escaped when they were sent)
+	roomName := "the lobby"
+	if t != nil {
+		roomName = "table \"" + html.EscapeString(t.Name) + "\""
+	}
+	msg := s.Username + " reported a message from " + name + " in " + roomName + " " +
+		"(" + messageID + "): " + message
+	if reason != "" {
+		msg += " (reason: " + reason + ")"
+	}
+	for _, s2 := range sessions.GetList() {
+		if s2.Moderator && s2.UserID != s.UserID {
+			chatServerSendPM(s2, msg, "lobby")
+		}
+	}
+
+	chatServerSendPM(s, "Thank you. The message has been reported to the moderators.", d.Room)
+}
@@ -0,0 +1,93 @@
+// The chat log would otherwise grow forever, so servers can optionally purge old messages
+// Lobby messages and table messages have separate retention windows,
+// since the table messages are part of the replays and are usually worth keeping for longer
+
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+const (
+	DefaultChatRetentionIntervalHours = 24
+
+	// Messages are deleted in batches so that the database is never locked for a long time
+	ChatRetentionBatchSize  = 1000
+	ChatRetentionBatchDelay = time.Second
+)
+
+var (
+	// 0 means that the messages are kept forever
+	chatRetentionLobbyDays int
+	chatRetentionTableDays int
+	chatRetentionInterval  time.Duration
+)
+
+func chatRetentionInit() {
+	chatRetentionLobbyDays = getEnvInt("CHAT_RETENTION_LOBBY_DAYS", 0)
+	chatRetentionTableDays = getEnvInt("CHAT_RETENTION_TABLE_DAYS", 0)
+	if chatRetentionLobbyDays < 0 || chatRetentionTableDays < 0 {
+		logger.Fatal("The \"CHAT_RETENTION_LOBBY_DAYS\" and \"CHAT_RETENTION_TABLE_DAYS\" " +
+			"environment variables cannot be negative.")
+		return
+	}
+	if chatRetentionLobbyDays == 0 && chatRetentionTableDays == 0 {
+		logger.Info("The chat retention windows are not set; keeping the full chat history.")
+		return
+	}
+
+	intervalHours := getEnvInt("CHAT_RETENTION_INTERVAL_HOURS", DefaultChatRetentionIntervalHours)
+	if intervalHours <= 0 {
+		logger.Fatal("The \"CHAT_RETENTION_INTERVAL_HOURS\" environment variable must be " +
+			"greater than 0.")
+		return
+	}
+	chatRetentionInterval = time.Duration(intervalHours) * time.Hour
+
+	go chatRetentionLoop()
+}
+
+func chatRetentionLoop() {
+	for {
+		chatRetentionPurge(true, chatRetentionLobbyDays)
+		chatRetentionPurge(false, chatRetentionTableDays)
+		time.Sleep(chatRetentionInterval)
+	}
+}
+
+func chatRetentionPurge(lobby bool, days int) {
+	if days == 0 {
+		return
+	}
+
+	description := "table"
+	if lobby {
+		description = "lobby"
+	}
+	cutoff := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+
+	var total int64
+	for {
+		numDeleted, err := models.ChatLog.DeleteBefore(lobby, cutoff, ChatRetentionBatchSize)
+		if err != nil {
+			logger.Error("Failed to delete the old " + description + " chat messages: " +
+				err.Error())
+			break
+		}
+		total += numDeleted
+		if numDeleted < ChatRetentionBatchSize {
+			break
+		}
+
+		// Give the other queries a chance to run
+		time.Sleep(ChatRetentionBatchDelay)
+	}
+
+	if total > 0 {
+		logger.Info("Deleted " + strconv.FormatInt(total, 10) + " " + description +
+			" chat messages that were older than " + strconv.Itoa(days) + " days.")
+	}
+}
@@ -0,0 +1,64 @@
+// The chat in a replay is made up of review comments about the game
+// Unlike the chat of the original game, the comments are stored against the database ID of the
+// game (instead of the table), so that they accumulate over time as people review the game again
+// The chat of the original game is never modified by this
+
+package main
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+const (
+	// e.g. "replay123" for the comments on game #123
+	// (this is only used in the database; clients still use the room of the table)
+	ReviewRoomPrefix = "replay"
+)
+
+// chatReviewIs returns true if a chat message at a table should be stored as a review comment
+// (messages from the server and from Discord are not, since they are never about the game)
+func chatReviewIs(d *CommandData, t *Table) bool {
+	return !d.Server && !d.Discord && t.GetReviewRoomName() != ""
+}
+
+// chatReviewRestoreFromDatabase adds the review comments from the previous times that this replay
+// was opened to the in-memory chat history
+// It is assumed that the table mutex is locked when calling this function
+func chatReviewRestoreFromDatabase(t *Table) {
+	t.ReviewChatRestored = true
+
+	room := t.GetReviewRoomName()
+	if room == "" {
+		return
+	}
+
+	// The comments from this time are already in memory
+	var rawMsgs []DBChatMessage
+	if v, err := models.ChatLog.Get(room, chatLimitTable, time.Time{}, t.DatetimeCreated); err != nil {
+		logger.Error("Failed to get the review comments for game " +
+			strconv.Itoa(t.ExtraOptions.DatabaseID) + ": " + err.Error())
+		return
+	} else {
+		rawMsgs = v
+	}
+	if len(rawMsgs) == 0 {
+		return
+	}
+
+	merged := make([]*TableChatMessage, 0, len(t.Chat)+len(rawMsgs))
+	merged = append(merged, t.Chat...)
+	for _, chatMsg := range tableChatMessagesFromDatabase(rawMsgs) {
+		chatMsg.Review = true
+		merged = append(merged, chatMsg)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Datetime.Before(merged[j].Datetime)
+	})
+	t.Chat = merged
+
+	t.ReconcileChatRead()
+}
@@ -0,0 +1,40 @@
+// Clients style the names of people with a role differently in the chat
+// The role is always decided by the server (from the privileges of the sender),
+// so a client cannot give itself a role by sending a crafted chat message
+// (moderators are currently the only privileged users; new roles go here)
+
+package main
+
+const (
+	ChatRoleModerator = "moderator"
+)
+
+// chatGetRole returns the role of the sender of a chat message
+// Server messages and messages from Discord already have their own distinct styling,
+// so they never have a role
+func chatGetRole(s *Session, d *CommandData) string {
+	if s == nil || d.Server || d.Discord {
+		return ""
+	}
+
+	if s.Moderator {
+		return ChatRoleModerator
+	}
+
+	return ""
+}
+
+// chatGetRoleFromDatabase is the same as the "chatGetRole()" function,
+// but for messages that are loaded from the chat history
+// (this uses the current privileges of the sender, not the privileges that they had at the time)
+func chatGetRoleFromDatabase(rawMsg DBChatMessage, server bool, discord bool) string {
+	if server || discord {
+		return ""
+	}
+
+	if rawMsg.Moderator {
+		return ChatRoleModerator
+	}
+
+	return ""
+}
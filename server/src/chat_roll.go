@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	RollMaxDice  = 100
+	RollMaxSides = 1000
+
+	// If more dice than this are rolled, only the total is shown
+	// (so that the result fits in a single chat message)
+	RollMaxDiceShown = 20
+)
+
+var (
+	// e.g. "2d6" or "d20"
+	rollRegExp = regexp.MustCompile(`^(\d*)d(\d+)$`)
+)
+
+// /roll [NdM]
+// The dice are rolled on the server so that no-one can fake a result
+func chatRoll(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if t == nil || d.Room == "lobby" {
+		chatServerSend(ctx, NotInGameFail, d.Room, d.NoTablesLock)
+		return
+	}
+
+	// By default, roll a single six-sided die
+	dice := "1d6"
+	if len(d.Args) > 1 {
+		msg := "The format of the /roll command is: /roll [NdM] (e.g. /roll 2d6)"
+		chatServerSend(ctx, msg, d.Room, d.NoTablesLock)
+		return
+	} else if len(d.Args) == 1 {
+		dice = strings.ToLower(d.Args[0])
+	}
+
+	match := rollRegExp.FindStringSubmatch(dice)
+	if match == nil {
+		msg := "\"" + d.Args[0] + "\" is not valid dice notation. " +
+			"The format of the /roll command is: /roll [NdM] (e.g. /roll 2d6)"
+		chatServerSend(ctx, msg, d.Room, d.NoTablesLock)
+		return
+	}
+
+	numDice := 1
+	if match[1] != "" {
+		if v, err := strconv.Atoi(match[1]); err != nil || v < 1 || v > RollMaxDice {
+			msg := "You can only roll between 1 and " + strconv.Itoa(RollMaxDice) + " dice."
+			chatServerSend(ctx, msg, d.Room, d.NoTablesLock)
+			return
+		} else {
+			numDice = v
+		}
+	}
+
+	var numSides int
+	if v, err := strconv.Atoi(match[2]); err != nil || v < 2 || v > RollMaxSides {
+		msg := "Dice must have between 2 and " + strconv.Itoa(RollMaxSides) + " sides."
+		chatServerSend(ctx, msg, d.Room, d.NoTablesLock)
+		return
+	} else {
+		numSides = v
+	}
+
+	total := 0
+	rolls := make([]string, 0, numDice)
+	for i := 0; i < numDice; i++ {
+		roll := rand.Intn(numSides) + 1 // nolint: gosec
+		total += roll
+		rolls = append(rolls, strconv.Itoa(roll))
+	}
+
+	msg := d.Username + " rolled " + strconv.Itoa(numDice) + "d" + strconv.Itoa(numSides) + ": "
+	if numDice > 1 && numDice <= RollMaxDiceShown {
+		msg += strings.Join(rolls, " + ") + " = "
+	}
+	msg += strconv.Itoa(total)
+	chatServerSend(ctx, msg, d.Room, d.NoTablesLock)
+}
@@ -0,0 +1,100 @@
+// Server announcements can be scheduled to go out at a specific time
+// (e.g. a warning before a maintenance window)
+// Pending announcements are only kept in memory, so they are lost if the server restarts
+
+package main
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+	"github.com/sasha-s/go-deadlock"
+)
+
+var (
+	scheduledAnnouncements = NewScheduledAnnouncements()
+)
+
+type ScheduledAnnouncement struct {
+	ID  int
+	Msg string
+	At  time.Time
+}
+
+type ScheduledAnnouncements struct {
+	announcements map[int]*ScheduledAnnouncement // Indexed by announcement ID
+	idCounter     int
+	mutex         *deadlock.Mutex // For handling concurrent access
+}
+
+func NewScheduledAnnouncements() *ScheduledAnnouncements {
+	return &ScheduledAnnouncements{
+		announcements: make(map[int]*ScheduledAnnouncement),
+		idCounter:     0,
+		mutex:         &deadlock.Mutex{},
+	}
+}
+
+// chatServerSendScheduled queues a message to be sent to the lobby and every table at the given
+// time and returns the ID of the announcement (which can be used to cancel it)
+// The tables are looked up when the announcement fires, so it does not matter if tables are
+// created or destroyed in the meantime
+func chatServerSendScheduled(ctx context.Context, msg string, at time.Time) int {
+	sa := scheduledAnnouncements
+	sa.mutex.Lock()
+	sa.idCounter++
+	announcement := &ScheduledAnnouncement{
+		ID:  sa.idCounter,
+		Msg: msg,
+		At:  at,
+	}
+	sa.announcements[announcement.ID] = announcement
+	sa.mutex.Unlock()
+
+	logger.Info("Scheduled announcement #" + strconv.Itoa(announcement.ID) + " for " +
+		at.Format(time.RFC3339) + ": " + msg)
+
+	go chatServerSendScheduledWait(announcement)
+
+	return announcement.ID
+}
+
+func chatServerSendScheduledWait(announcement *ScheduledAnnouncement) {
+	time.Sleep(time.Until(announcement.At))
+
+	// Do nothing if the announcement was canceled while we were sleeping
+	sa := scheduledAnnouncements
+	sa.mutex.Lock()
+	_, ok := sa.announcements[announcement.ID]
+	delete(sa.announcements, announcement.ID)
+	sa.mutex.Unlock()
+	if !ok {
+		return
+	}
+
+	// The context of the original request is long gone by now, so we make a new one
+	ctx := NewMiscContext("scheduledAnnouncement")
+
+	// We must acquire the tables lock before entering the "chatServerSendAll()" function
+	tables.Lock(ctx)
+	defer tables.Unlock(ctx)
+
+	chatServerSendAll(ctx, announcement.Msg)
+}
+
+// chatServerCancelScheduled returns false if there is no pending announcement with the given ID
+func chatServerCancelScheduled(id int) bool {
+	sa := scheduledAnnouncements
+	sa.mutex.Lock()
+	defer sa.mutex.Unlock()
+
+	if _, ok := sa.announcements[id]; !ok {
+		return false
+	}
+	delete(sa.announcements, id)
+
+	logger.Info("Canceled scheduled announcement #" + strconv.Itoa(id) + ".")
+	return true
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+const (
+	SearchMaxResults = 10
+)
+
+// /search [terms]
+// Every term must be found in the message; use double quotes to search for an exact phrase
+// (e.g. /search "bad clue" red)
+func chatSearch(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	// The message was already escaped in the "commandChat()" function
+	query := html.UnescapeString(strings.Join(d.Args, " "))
+	terms := chatSearchParseTerms(query)
+	if len(terms) == 0 {
+		msg := "The format of the /search command is: /search [terms]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	// Messages are stored escaped, so the terms must be escaped in the same way
+	for i, term := range terms {
+		terms[i] = html.EscapeString(term)
+	}
+
+	var results []SearchChatMessage
+	if t == nil {
+		if v, err := models.ChatLog.Search(d.Room, terms, SearchMaxResults); err != nil {
+			logger.Error("Failed to search the chat in room \"" + d.Room + "\": " + err.Error())
+			s.Error(DefaultErrorMsg)
+			return
+		} else {
+			results = v
+		}
+
+		// The mentions and formatting are filled in when the messages are read
+		for i := range results {
+			results[i].Message = chatFillAll(results[i].Message)
+		}
+	} else {
+		// Table chat is kept in memory (since it might have been cleared from the database)
+		results = chatSearchTable(t, terms)
+	}
+
+	if len(results) == 0 {
+		chatServerSendPM(s, "No messages in this room match \""+html.EscapeString(query)+"\".",
+			d.Room)
+		return
+	}
+
+	chatServerSendPM(s, "The last "+strconv.Itoa(len(results))+" messages that match "+
+		"\""+html.EscapeString(query)+"\":", d.Room)
+
+	// The results are from newest to oldest, but we want to show the newest at the bottom
+	for i := len(results) - 1; i >= 0; i-- {
+		result := results[i]
+		name := result.Name
+		if name == "" || name == "__server" {
+			name = WebsiteName
+		}
+		chatServerSendPM(s, "["+s.FormatTimestamp(result.Datetime)+"] <"+name+"> "+
+			result.Message, d.Room)
+	}
+}
+
+// chatSearchParseTerms splits a search query into words, keeping quoted phrases together
+// The terms are lowercase, since the search is case-insensitive
+func chatSearchParseTerms(query string) []string {
+	terms := make([]string, 0)
+	for i, part := range strings.Split(query, "\"") {
+		if i%2 == 1 {
+			// This part was inside of quotes
+			// (an unclosed quote is treated as a phrase that goes until the end of the query)
+			if phrase := strings.TrimSpace(part); phrase != "" {
+				terms = append(terms, strings.ToLower(phrase))
+			}
+			continue
+		}
+		for _, word := range strings.Fields(part) {
+			terms = append(terms, strings.ToLower(word))
+		}
+	}
+
+	return terms
+}
+
+// chatSearchTable gets the last messages at a table that contain all of the terms,
+// from newest to oldest
+// It is assumed that the table mutex is locked when calling this function
+func chatSearchTable(t *Table, terms []string) []SearchChatMessage {
+	if len(t.Chat) == 0 && !t.ChatRestored && !t.Replay {
+		chatRestoreFromDatabase(t)
+	}
+	if t.Replay && !t.ReviewChatRestored {
+		chatReviewRestoreFromDatabase(t)
+	}
+
+	results := make([]SearchChatMessage, 0)
+	for i := len(t.Chat) - 1; i >= 0 && len(results) < SearchMaxResults; i-- {
+		chatMsg := t.Chat[i]
+		msg := strings.ToLower(chatMsg.Msg)
+		matches := true
+		for _, term := range terms {
+			if !strings.Contains(msg, term) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			results = append(results, SearchChatMessage{
+				Name:     chatMsg.Username,
+				Message:  chatMsg.GetFilledMsg(),
+				Datetime: chatMsg.Datetime,
+			})
+		}
+	}
+
+	return results
+}
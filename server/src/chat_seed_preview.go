@@ -0,0 +1,52 @@
+// Seeds can be referenced in chat with "!seed [variant]:[seed]" (e.g. "!seed No Variant:abc123")
+// The metadata for the variant is sent to clients in a separate "chatSeedPreview" message,
+// so that they can show a button to create a table that plays the same seed
+
+package main
+
+const (
+	// Only the first few seeds in a message get a preview
+	ChatSeedPreviewMaxPerMessage = 3
+)
+
+type ChatSeedPreviewMessage struct {
+	ID        string   `json:"id"` // The ID of the chat message that contains the seed
+	Room      string   `json:"room"`
+	Seed      string   `json:"seed"`
+	Variant   string   `json:"variant"`
+	VariantID int      `json:"variantID"`
+	Suits     []string `json:"suits"`
+	MaxScore  int      `json:"maxScore"`
+}
+
+// chatSeedPreviewSend sends a preview for every seed that is referenced in a chat message
+// (the parsing is done in the "chatParseSeedReferences()" function)
+func chatSeedPreviewSend(messageID string, room string, msg string, recipients []*Session) {
+	if messageID == "" || len(recipients) == 0 {
+		return
+	}
+
+	references := chatParseSeedReferences(msg)
+	if len(references) > ChatSeedPreviewMaxPerMessage {
+		references = references[:ChatSeedPreviewMaxPerMessage]
+	}
+
+	for _, reference := range references {
+		suits := make([]string, 0, len(reference.Variant.Suits))
+		for _, suit := range reference.Variant.Suits {
+			suits = append(suits, suit.Name)
+		}
+		seedPreviewMessage := &ChatSeedPreviewMessage{
+			ID:        messageID,
+			Room:      room,
+			Seed:      reference.Seed,
+			Variant:   reference.Variant.Name,
+			VariantID: reference.Variant.ID,
+			Suits:     suits,
+			MaxScore:  reference.Variant.MaxScore,
+		}
+		for _, s := range recipients {
+			s.Emit("chatSeedPreview", seedPreviewMessage)
+		}
+	}
+}
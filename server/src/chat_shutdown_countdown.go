@@ -0,0 +1,160 @@
+// Before a deploy, the server can count down to a graceful shutdown so that everyone is warned at
+// regular intervals (instead of an administrator having to warn them manually)
+// New tables cannot be created once the countdown begins,
+// and the normal shutdown (in "shutdown.go") begins once the countdown ends
+
+package main
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+	"github.com/sasha-s/go-deadlock"
+	"github.com/tevino/abool"
+)
+
+var (
+	// A warning is sent when each of these amounts of time are left
+	// (in addition to the warning when the countdown begins)
+	shutdownCountdownWarnings = []time.Duration{
+		10 * time.Minute,
+		5 * time.Minute,
+		time.Minute,
+		30 * time.Second,
+	}
+
+	shutdownCountdownActive = abool.New()
+	// Incremented every time that a countdown is started or canceled,
+	// so that the goroutine for a canceled countdown knows to stop
+	shutdownCountdownID    int
+	shutdownCountdownEnd   time.Time
+	shutdownCountdownMutex = &deadlock.Mutex{}
+)
+
+// chatServerShutdownCountdown returns false if a countdown is already in progress
+func chatServerShutdownCountdown(ctx context.Context, duration time.Duration) bool {
+	shutdownCountdownMutex.Lock()
+	if shutdownCountdownActive.IsSet() {
+		shutdownCountdownMutex.Unlock()
+		return false
+	}
+	shutdownCountdownActive.Set()
+	shutdownCountdownID++
+	id := shutdownCountdownID
+	shutdownCountdownEnd = time.Now().Add(duration)
+	end := shutdownCountdownEnd
+	shutdownCountdownMutex.Unlock()
+
+	logger.Info("Starting a shutdown countdown of " + duration.String() + ".")
+	chatServerShutdownCountdownWarn(ctx, duration)
+
+	go chatServerShutdownCountdownWait(id, end)
+
+	return true
+}
+
+func chatServerShutdownCountdownWait(id int, end time.Time) {
+	// The context of the original request is long gone by now, so we make a new one
+	ctx := NewMiscContext("shutdownCountdown")
+
+	for _, warning := range shutdownCountdownWarnings {
+		// Warnings for more time than the length of the countdown are skipped
+		if time.Until(end) <= warning {
+			continue
+		}
+		time.Sleep(time.Until(end.Add(-warning)))
+		if !chatServerShutdownCountdownIsCurrent(id) {
+			return
+		}
+		chatServerShutdownCountdownWarn(ctx, warning)
+	}
+
+	time.Sleep(time.Until(end))
+
+	// Do nothing if the countdown was canceled while we were sleeping
+	shutdownCountdownMutex.Lock()
+	if shutdownCountdownActive.IsNotSet() || id != shutdownCountdownID {
+		shutdownCountdownMutex.Unlock()
+		return
+	}
+	shutdownCountdownActive.UnSet()
+	shutdownCountdownMutex.Unlock()
+
+	if shuttingDown.IsSet() {
+		// An administrator already started the shutdown manually
+		return
+	}
+	shutdown(ctx)
+}
+
+func chatServerShutdownCountdownIsCurrent(id int) bool {
+	shutdownCountdownMutex.Lock()
+	defer shutdownCountdownMutex.Unlock()
+
+	return shutdownCountdownActive.IsSet() && id == shutdownCountdownID
+}
+
+func chatServerShutdownCountdownWarn(ctx context.Context, timeLeft time.Duration) {
+	var timeLeftString string
+	if v, err := secondsToDurationString(int(timeLeft.Round(time.Second).Seconds())); err != nil {
+		logger.Error("Failed to parse the duration string: " + err.Error())
+		return
+	} else {
+		timeLeftString = v
+	}
+
+	msg := "The server will begin shutting down in " + timeLeftString + ". " +
+		"You cannot create any new tables for the time being."
+
+	// We must acquire the tables lock before entering the "chatServerSendAll()" function
+	tables.Lock(ctx)
+	defer tables.Unlock(ctx)
+
+	chatServerSendAll(ctx, msg)
+}
+
+// chatServerCancelShutdownCountdown returns false if there is no countdown in progress
+func chatServerCancelShutdownCountdown(ctx context.Context) bool {
+	shutdownCountdownMutex.Lock()
+	if shutdownCountdownActive.IsNotSet() {
+		shutdownCountdownMutex.Unlock()
+		return false
+	}
+	shutdownCountdownActive.UnSet()
+	shutdownCountdownID++
+	shutdownCountdownMutex.Unlock()
+
+	logger.Info("Canceled the shutdown countdown.")
+
+	// We must acquire the tables lock before entering the "chatServerSendAll()" function
+	tables.Lock(ctx)
+	defer tables.Unlock(ctx)
+
+	chatServerSendAll(ctx, "The shutdown has been canceled. You can create new tables again.")
+	return true
+}
+
+// chatServerShutdownCountdownTimeLeft returns false if there is no countdown in progress
+func chatServerShutdownCountdownTimeLeft() (time.Duration, bool) {
+	shutdownCountdownMutex.Lock()
+	defer shutdownCountdownMutex.Unlock()
+
+	if shutdownCountdownActive.IsNotSet() {
+		return 0, false
+	}
+	return time.Until(shutdownCountdownEnd), true
+}
+
+// chatServerShutdownCountdownString is used for the warning when someone tries to create a table
+func chatServerShutdownCountdownString(timeLeft time.Duration) string {
+	seconds := int(timeLeft.Round(time.Second).Seconds())
+	if seconds < 1 {
+		return "momentarily"
+	}
+	if v, err := secondsToDurationString(seconds); err == nil {
+		return "in " + v
+	}
+	return "in " + strconv.Itoa(seconds) + " seconds"
+}
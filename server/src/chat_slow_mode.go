@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	SlowModeMaxSeconds = 600
+)
+
+// /slowmode [seconds]
+// Everyone at the table (including the table owner) can only send one message every X seconds;
+// 0 disables it
+func chatSlowMode(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if t == nil {
+		chatServerSendPM(s, ChatMsgNotInGame, d.Room)
+		return
+	}
+
+	if s.UserID != t.OwnerID && !s.Moderator {
+		chatServerSendPM(s, "Only the table owner or a moderator can change the slow mode.", d.Room)
+		return
+	}
+
+	if len(d.Args) != 1 {
+		msg := "The format of the /slowmode command is: /slowmode [seconds]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	var seconds int
+	if v, err := strconv.Atoi(d.Args[0]); err != nil || v < 0 || v > SlowModeMaxSeconds {
+		msg := "The number of seconds must be between 0 and " + strconv.Itoa(SlowModeMaxSeconds) +
+			"."
+		chatServerSendPM(s, msg, d.Room)
+		return
+	} else {
+		seconds = v
+	}
+
+	if seconds == t.SlowMode {
+		chatServerSendPM(s, "The slow mode is already set to that value.", d.Room)
+		return
+	}
+	t.SlowMode = seconds
+	t.ChatLastSent = make(map[int]time.Time)
+
+	var msg string
+	if seconds == 0 {
+		msg = s.Username + " disabled slow mode."
+	} else {
+		msg = s.Username + " enabled slow mode: everyone can send one message every " +
+			strconv.Itoa(seconds) + " seconds."
+	}
+	chatServerSend(ctx, msg, d.Room, d.NoTablesLock)
+}
+
+// chatSlowModeCheck returns false if the user has to wait before sending another message to the
+// table
+// If they are allowed to send it, the time is recorded
+// It is assumed that the table mutex is locked when calling this function
+func chatSlowModeCheck(s *Session, d *CommandData, t *Table) bool {
+	// Server messages and messages from Discord are exempt
+	if t.SlowMode == 0 || s == nil || d.Server || d.Discord {
+		return true
+	}
+
+	interval := time.Duration(t.SlowMode) * time.Second
+	now := time.Now()
+	if lastSent, ok := t.ChatLastSent[s.UserID]; ok {
+		if remaining := interval - now.Sub(lastSent); remaining > 0 {
+			seconds := int(math.Ceil(remaining.Seconds()))
+			if seconds == 1 {
+				chatServerSendPM(s, ChatMsgSlowModeOne, d.Room)
+			} else {
+				chatServerSendPM(s, ChatMsgSlowMode, d.Room, seconds)
+			}
+			return false
+		}
+	}
+
+	t.ChatLastSent[s.UserID] = now
+	return true
+}
@@ -0,0 +1,232 @@
+// The players can optionally be told when spectators come and go with the "/spectatornotices"
+// command
+// Spectators often join and leave in quick succession (e.g. when they refresh the page),
+// so the changes are collected for a while and then summarized in a single message
+// The notices are only sent to the people at the table at the time (like game events that are not
+// saved in "chat_game_events.go"), since they are not important after the fact
+
+package main
+
+import (
+	"context"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+const (
+	// By default, the changes over 10 seconds are summarized in one message
+	DefaultChatSpectatorNoticesSeconds = 10
+
+	// Any more names than this are summarized as "and X others"
+	ChatSpectatorNoticesMaxNames = 5
+)
+
+var (
+	chatSpectatorNoticesWindow time.Duration
+)
+
+type ChatSpectatorNoticesQueue struct {
+	Changes map[int]*ChatSpectatorNoticeChange // Indexed by user ID
+	// The user IDs in the order that they first changed, so that the message is deterministic
+	Order     []int
+	Scheduled bool
+}
+
+type ChatSpectatorNoticeChange struct {
+	Name string
+	// A spectator who joins and then leaves (or vice versa) within the same window cancels out
+	FirstJoined bool
+	LastJoined  bool
+}
+
+func chatSpectatorNoticesInit() {
+	seconds := getEnvInt("CHAT_SPECTATOR_NOTICES_SECONDS", DefaultChatSpectatorNoticesSeconds)
+	chatSpectatorNoticesWindow = time.Duration(seconds) * time.Second
+}
+
+// chatSpectatorNoticesDefault is used when a table is created
+// Competitive games (i.e. timed or speedrun games) do not have the notices by default,
+// since the players should not be distracted
+func chatSpectatorNoticesDefault(options *Options) bool {
+	return !options.Timed && !options.Speedrun
+}
+
+// /spectatornotices [on/off]
+func chatSpectatorNotices(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if t == nil {
+		chatServerSendPM(s, ChatMsgNotInGame, d.Room)
+		return
+	}
+
+	if s.UserID != t.OwnerID && !s.Moderator {
+		msg := "Only the table owner or a moderator can change whether spectator notices are " +
+			"shown."
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	if chatSpectatorNoticesWindow <= 0 {
+		chatServerSendPM(s, "Spectator notices are disabled on this server.", d.Room)
+		return
+	}
+
+	if len(d.Args) == 0 {
+		msg := "Spectator notices are currently "
+		if t.ChatSpectatorNotices {
+			msg += "on"
+		} else {
+			msg += "off"
+		}
+		msg += ". The format of the /spectatornotices command is: /spectatornotices [on/off]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	switch strings.ToLower(d.Args[0]) {
+	case "on":
+		t.ChatSpectatorNotices = true
+	case "off":
+		t.ChatSpectatorNotices = false
+		t.SpectatorNoticesQueue = nil
+	default:
+		msg := "The format of the /spectatornotices command is: /spectatornotices [on/off]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	msg := s.Username + " turned spectator notices " + strings.ToLower(d.Args[0]) + "."
+	chatServerSend(ctx, msg, d.Room, d.NoTablesLock)
+}
+
+// chatSpectatorNoticeAdd is called when a spectator joins or leaves an ongoing game
+// It is assumed that the table mutex is locked when calling this function
+func chatSpectatorNoticeAdd(t *Table, userID int, name string, joined bool) {
+	if !t.ChatSpectatorNotices || !t.Running || t.Replay || chatSpectatorNoticesWindow <= 0 {
+		return
+	}
+
+	if t.SpectatorNoticesQueue == nil {
+		t.SpectatorNoticesQueue = &ChatSpectatorNoticesQueue{
+			Changes:   make(map[int]*ChatSpectatorNoticeChange),
+			Order:     make([]int, 0),
+			Scheduled: false,
+		}
+	}
+	queue := t.SpectatorNoticesQueue
+
+	if change, ok := queue.Changes[userID]; ok {
+		change.LastJoined = joined
+	} else {
+		queue.Changes[userID] = &ChatSpectatorNoticeChange{
+			Name:        name,
+			FirstJoined: joined,
+			LastJoined:  joined,
+		}
+		queue.Order = append(queue.Order, userID)
+	}
+
+	if !queue.Scheduled {
+		queue.Scheduled = true
+		go chatSpectatorNoticesFlush(t, queue)
+	}
+}
+
+func chatSpectatorNoticesFlush(t *Table, queue *ChatSpectatorNoticesQueue) {
+	time.Sleep(chatSpectatorNoticesWindow)
+
+	// The context of the original request is long gone by now, so we make a new one
+	ctx := NewMiscContext("spectatorNotices")
+
+	// Check to see if the table still exists
+	t2, exists := getTableAndLock(ctx, nil, t.ID, false, false)
+	if !exists || t != t2 {
+		return
+	}
+	t.Lock(ctx)
+	defer t.Unlock(ctx)
+
+	// The notices might have been turned off (or back on) in the meantime
+	if t.SpectatorNoticesQueue != queue {
+		return
+	}
+	t.SpectatorNoticesQueue = nil
+
+	if t.Replay {
+		return
+	}
+
+	joined := make([]string, 0)
+	left := make([]string, 0)
+	for _, userID := range queue.Order {
+		change := queue.Changes[userID]
+		if change.FirstJoined != change.LastJoined {
+			continue
+		}
+		if change.LastJoined {
+			joined = append(joined, change.Name)
+		} else {
+			left = append(left, change.Name)
+		}
+	}
+
+	parts := make([]string, 0)
+	if len(joined) > 0 {
+		parts = append(parts, chatSpectatorNoticesFormatNames(joined)+" started spectating.")
+	}
+	if len(left) > 0 {
+		parts = append(parts, chatSpectatorNoticesFormatNames(left)+" stopped spectating.")
+	}
+	if len(parts) == 0 {
+		return
+	}
+	msg := strings.Join(parts, " ")
+
+	// The notices are never written to the database or kept in the chat history
+	chatMsg := &TableChatMessage{
+		ID:        newChatMessageID(),
+		UserID:    0,
+		Username:  "",
+		Msg:       msg,
+		Datetime:  time.Now(),
+		Server:    true,
+		Discord:   false,
+		Reactions: make(map[string][]int),
+		ReplyTo:   "",
+		Role:      "",
+	}
+	t.NotifyChat(chatMsg.ToChatMessage(t))
+
+	logger.Info(t.GetName() + msg)
+}
+
+// chatSpectatorNoticesFormatNames returns e.g. "Alice, Bob, and Carol"
+// Server messages are not escaped, so the names are escaped here
+func chatSpectatorNoticesFormatNames(names []string) string {
+	escapedNames := make([]string, 0, len(names))
+	for i, name := range names {
+		if i == ChatSpectatorNoticesMaxNames {
+			numOthers := len(names) - ChatSpectatorNoticesMaxNames
+			other := strconv.Itoa(numOthers) + " others"
+			if numOthers == 1 {
+				other = "1 other"
+			}
+			escapedNames = append(escapedNames, other)
+			break
+		}
+		escapedNames = append(escapedNames, html.EscapeString(name))
+	}
+
+	switch len(escapedNames) {
+	case 1:
+		return escapedNames[0]
+	case 2:
+		return escapedNames[0] + " and " + escapedNames[1]
+	default:
+		last := len(escapedNames) - 1
+		return strings.Join(escapedNames[:last], ", ") + ", and " + escapedNames[last]
+	}
+}
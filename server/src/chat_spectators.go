@@ -0,0 +1,223 @@
+// During a game, the spectators have a side channel that the players cannot see,
+// so that they can discuss the game without giving the players any hints
+// It is a separate room (e.g. "table123-spectators"), so it is also stored separately in the
+// database
+// When the game ends and the table becomes a shared replay, the side channel is merged into the
+// normal table chat so that everyone can see it
+
+package main
+
+import (
+	"context"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+const (
+	SpectatorRoomSuffix = "-spectators"
+)
+
+func (t *Table) GetSpectatorRoomName() string {
+	return t.GetRoomName() + SpectatorRoomSuffix
+}
+
+// /spectators [msg]
+func chatSpectators(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if t == nil {
+		chatServerSendPM(s, ChatMsgNotInGame, d.Room)
+		return
+	}
+
+	if !chatSpectatorsValidate(s, d, t) {
+		return
+	}
+
+	if len(d.Args) == 0 {
+		msg := "The format of the /spectators command is: /spectators [msg]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	// The message was already sanitized and escaped,
+	// but it will be sanitized and escaped again when it is sent to the other room
+	commandChat(ctx, s, &CommandData{ // nolint: exhaustivestruct
+		Msg:  html.UnescapeString(strings.Join(d.Args, " ")),
+		Room: t.GetSpectatorRoomName(),
+		// The table is already locked
+		NoTableLock:  true,
+		NoTablesLock: d.NoTablesLock,
+	})
+}
+
+// chatSpectatorsValidate returns false if the user is not allowed to chat in the side channel
+func chatSpectatorsValidate(s *Session, d *CommandData, t *Table) bool {
+	if !t.Running || t.Replay {
+		msg := "The spectator chat is only available while a game is in progress."
+		chatServerSendPM(s, msg, d.Room)
+		return false
+	}
+
+	if t.GetPlayerIndexFromID(s.UserID) != -1 {
+		chatServerSendPM(s, "Only the spectators can use the spectator chat.", d.Room)
+		return false
+	}
+
+	return true
+}
+
+// commandChatSpectators is called from the "commandChatTable()" function for messages to the side
+// channel
+// It is assumed that the table mutex is locked when calling this function
+func commandChatSpectators(s *Session, d *CommandData, t *Table) {
+	// Discord channels are bridged to the normal table chat, so they cannot see the side channel
+	// (and the side channel no longer exists once the game has ended)
+	if d.Discord || (d.Server && t.Replay) {
+		return
+	}
+
+	// Server messages in the side channel are replies to the commands that the spectators used
+	userID := 0
+	if !d.Server {
+		// (the same restrictions as for the normal table chat were already checked in the
+		// "commandChatTable()" function, e.g. slow mode)
+		if !chatSpectatorsValidate(s, d, t) {
+			return
+		}
+
+		userID = s.UserID
+	}
+
+	// Replies are not supported, since the quote is looked up from the normal table chat
+	chatMsg := &TableChatMessage{
+		ID:        newChatMessageID(),
+		UserID:    userID,
+		Username:  d.Username,
+		Msg:       d.Msg,
+		Datetime:  time.Now(),
+		Server:    d.Server,
+		Discord:   false,
+		Reactions: make(map[string][]int),
+		ReplyTo:   "",
+		Role:      chatGetRole(s, d),
+	}
+	if !d.NoDatabase {
+		t.SpectatorChat = append(t.SpectatorChat, chatMsg)
+
+		// Also store the chat in the database under its own room,
+		// so that it will survive a server restart but will not be mixed in with the normal chat
+		if err := models.ChatLog.Insert(chatMsg.ID, userID, d.Msg, d.Room, ""); err != nil {
+			logger.Error("Failed to insert a spectator chat message into the database: " +
+				err.Error())
+			// Do not return on failed chat insertion,
+			// since the message is still stored in memory
+		}
+	}
+
+	chatMessage := chatMsg.ToSpectatorChatMessage(t)
+	recipients := make([]*Session, 0)
+	for _, sp := range t.Spectators {
+		if sp.Session != nil && !chatIsIgnored(sp.Session, s) {
+			sp.Session.Emit("chat", chatMessage)
+			recipients = append(recipients, sp.Session)
+		}
+	}
+	chatMetrics.Sent(d.Room, d.Msg, len(recipients))
+}
+
+// ToSpectatorChatMessage is the same as the "ToChatMessage()" function,
+// but for a message in the side channel
+func (gcm *TableChatMessage) ToSpectatorChatMessage(t *Table) *ChatMessage {
+	chatMessage := gcm.ToChatMessage(t)
+	chatMessage.Room = t.GetSpectatorRoomName()
+	chatMessage.Quote = nil
+	return chatMessage
+}
+
+// chatSpectatorsAddToList adds the side channel to the chat history that is sent to a spectator
+// (in the "chatSendPastFromTable()" function)
+// The pinned message (if any) stays at the top
+func chatSpectatorsAddToList(t *Table, chatList []*ChatMessage) []*ChatMessage {
+	if len(t.SpectatorChat) == 0 {
+		return chatList
+	}
+
+	start := 0
+	if len(chatList) > 0 && t.PinnedMessageID != "" && chatList[0].ID == t.PinnedMessageID {
+		start = 1
+	}
+
+	i := 0
+	if len(t.SpectatorChat) > chatLimitTable {
+		i = len(t.SpectatorChat) - chatLimitTable
+	}
+	for ; i < len(t.SpectatorChat); i++ {
+		chatList = append(chatList, t.SpectatorChat[i].ToSpectatorChatMessage(t))
+	}
+
+	history := chatList[start:]
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Datetime.Before(history[j].Datetime)
+	})
+
+	return chatList
+}
+
+// chatSpectatorsRestoreFromDatabase is the same as the "chatRestoreFromDatabase()" function,
+// but for the side channel
+func chatSpectatorsRestoreFromDatabase(t *Table, since time.Time) {
+	if len(t.SpectatorChat) > 0 {
+		return
+	}
+
+	var rawMsgs []DBChatMessage
+	if v, err := models.ChatLog.GetSince(t.GetSpectatorRoomName(), since); err != nil {
+		logger.Error("Failed to get the spectator chat history for table " +
+			strconv.FormatUint(t.ID, 10) + ": " + err.Error())
+		return
+	} else {
+		rawMsgs = v
+	}
+
+	t.SpectatorChat = append(t.SpectatorChat, tableChatMessagesFromDatabase(rawMsgs)...)
+}
+
+// chatSpectatorsReveal merges the side channel into the normal table chat once the game is over
+// It is called from the "ConvertToSharedReplay()" function after the players have been turned into
+// spectators
+// It is assumed that the tables mutex and the table mutex are locked when calling this function
+func chatSpectatorsReveal(ctx context.Context, t *Table) {
+	if len(t.SpectatorChat) == 0 {
+		return
+	}
+
+	// The people who were playing have never seen these messages
+	// (they are spectators now, since this is called after they have been converted)
+	for _, sp := range t.Spectators {
+		if sp.Session == nil || t.GetPlayerIndexFromID(sp.UserID) == -1 {
+			continue
+		}
+		for _, gcm := range t.SpectatorChat {
+			if s2, ok := sessions.Get(gcm.UserID); ok && chatIsIgnored(sp.Session, s2) {
+				continue
+			}
+			sp.Session.Emit("chat", gcm.ToSpectatorChatMessage(t))
+		}
+	}
+
+	merged := append(make([]*TableChatMessage, 0, len(t.Chat)+len(t.SpectatorChat)), t.Chat...)
+	merged = append(merged, t.SpectatorChat...)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Datetime.Before(merged[j].Datetime)
+	})
+	t.Chat = merged
+	t.SpectatorChat = make([]*TableChatMessage, 0)
+	t.ReconcileChatRead()
+
+	msg := "The game is over, so the spectator chat has been revealed to everyone."
+	chatServerSend(ctx, msg, t.GetRoomName(), true)
+}
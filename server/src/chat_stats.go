@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+const (
+	// Only the variants that someone has played the most are shown
+	ChatStatsNumVariants = 3
+)
+
+// /stats [username]
+// Show the same stats as the profile page (with the most played variants) in a private message
+func chatStats(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if len(d.Args) > 1 {
+		msg := "The format of the /stats command is: /stats [username]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	// By default, they are looking up their own stats
+	username := s.Username
+	if len(d.Args) == 1 {
+		username = d.Args[0]
+	}
+	normalizedUsername := normalizeString(username)
+
+	// Validate that this person exists in the database
+	var user User
+	if exists, v, err := models.Users.GetUserFromNormalizedUsername(
+		normalizedUsername,
+	); err != nil {
+		logger.Error("Failed to validate that \"" + normalizedUsername + "\" " +
+			"exists in the database: " + err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else if !exists {
+		chatServerSendPM(s, ChatMsgUserNotFound, d.Room, username)
+		return
+	} else {
+		user = v
+	}
+
+	// Users can opt out of being queried from the "Settings" tooltip in the lobby
+	// (but they can always look up their own stats)
+	if user.ID != s.UserID {
+		if hidden, err := models.UserSettings.IsStatsHidden(user.ID); err != nil {
+			logger.Error("Failed to get the \"hide_stats\" setting for user " +
+				"\"" + user.Username + "\": " + err.Error())
+			s.Error(DefaultErrorMsg)
+			return
+		} else if hidden {
+			chatServerSendPM(s, ChatMsgStatsHidden, d.Room, user.Username)
+			return
+		}
+	}
+
+	var profileStats Stats
+	if v, err := models.Games.GetProfileStats(user.ID); err != nil {
+		logger.Error("Failed to get the profile stats for user \"" + user.Username + "\": " +
+			err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else {
+		profileStats = v
+	}
+
+	numGames := profileStats.NumGamesNormal + profileStats.NumGamesOther
+	if numGames == 0 && profileStats.NumGamesSpeedrun == 0 {
+		chatServerSendPM(s, ChatMsgStatsNoGames, d.Room, user.Username)
+		return
+	}
+
+	var statsMap map[int]*UserStatsRow
+	if v, err := models.UserStats.GetAll(user.ID); err != nil {
+		logger.Error("Failed to get all of the variant-specific stats for user " +
+			"\"" + user.Username + "\": " + err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else {
+		statsMap = v
+	}
+
+	msg := "Stats for \"" + user.Username + "\": " + strconv.Itoa(numGames) + " games played (" +
+		strconv.Itoa(profileStats.NumGamesNormal) + " completed, " +
+		strconv.Itoa(profileStats.NumGamesOther) + " other)"
+	if profileStats.TimePlayed > 0 {
+		if v, err := secondsToDurationString(profileStats.TimePlayed); err == nil {
+			msg += " over " + v
+		}
+	}
+	if profileStats.NumGamesSpeedrun > 0 {
+		msg += ", " + strconv.Itoa(profileStats.NumGamesSpeedrun) + " speedrun games"
+	}
+
+	numMaxScores, _, _ := httpGetVariantStatsList(statsMap)
+	totalMaxScores := len(variantNames) * 5 // For 2 to 6 players
+	percentageMaxScores, _ := httpGetPercentageMaxScores(numMaxScores, make([]int, 0))
+	msg += ". Max scores: " + strconv.Itoa(numMaxScores) + " / " + strconv.Itoa(totalMaxScores) +
+		" (" + percentageMaxScores + "%)."
+
+	if topVariants := chatStatsTopVariants(statsMap); len(topVariants) > 0 {
+		msg += " Most played variants: " + strings.Join(topVariants, ", ") + "."
+	}
+
+	chatServerSendPM(s, msg, d.Room)
+}
+
+// chatStatsTopVariants returns a description of the variants with the most games
+func chatStatsTopVariants(statsMap map[int]*UserStatsRow) []string {
+	variantIDs := make([]int, 0, len(statsMap))
+	for variantID, stats := range statsMap {
+		if _, ok := variantIDMap[variantID]; ok && stats.NumGames > 0 {
+			variantIDs = append(variantIDs, variantID)
+		}
+	}
+	sort.Slice(variantIDs, func(i, j int) bool {
+		numGames1 := statsMap[variantIDs[i]].NumGames
+		numGames2 := statsMap[variantIDs[j]].NumGames
+		if numGames1 != numGames2 {
+			return numGames1 > numGames2
+		}
+		return variantIDs[i] < variantIDs[j]
+	})
+	if len(variantIDs) > ChatStatsNumVariants {
+		variantIDs = variantIDs[:ChatStatsNumVariants]
+	}
+
+	descriptions := make([]string, 0, len(variantIDs))
+	for _, variantID := range variantIDs {
+		variantName := variantIDMap[variantID]
+		stats := statsMap[variantID]
+
+		bestScore := 0
+		for _, score := range stats.BestScores {
+			if score.Score > bestScore {
+				bestScore = score.Score
+			}
+		}
+		maxScore := len(variants[variantName].Suits) * PointsPerSuit
+
+		descriptions = append(descriptions, variantName+" ("+strconv.Itoa(stats.NumGames)+
+			" games, best "+strconv.Itoa(bestScore)+"/"+strconv.Itoa(maxScore)+", average "+
+			fmt.Sprintf("%.1f", stats.AverageScore)+")")
+	}
+
+	return descriptions
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// chatTestConnectDiscord pretends that the server is connected to Discord, since the Discord fill
+// functions do nothing otherwise
+// It returns a function that restores the connection
+func chatTestConnectDiscord() func() {
+	oldDiscord := discord
+	discord = &discordgo.Session{} // nolint: exhaustivestruct
+	return func() {
+		discord = oldDiscord
+	}
+}
+
+func TestChatFillAllTwice(t *testing.T) {
+	// Lobby messages that were stored by older versions of the server were already filled in,
+	// so filling them in again must not change them
+	msgs := []string{
+		"see game #12345",
+		"see /replay/12345 and game 678",
+		"**bold** and *italic* in game #42",
+	}
+	for _, msg := range msgs {
+		filled := chatFillAll(msg)
+		if filledTwice := chatFillAll(filled); filledTwice != filled {
+			t.Errorf("chatFillAll(chatFillAll(%q)) = %q, expected %q", msg, filledTwice, filled)
+		}
+	}
+}
+
+func TestChatFillMentionsPathological(t *testing.T) {
+	defer chatTestConnectDiscord()()
+
+	// A nickname that is itself a mention (of its own user or of another user)
+	// must be inserted as-is, instead of being filled in again
+	discordNicknames.Set("111111111111111111", "&lt;@111111111111111111&gt;", true)
+	discordNicknames.Set("222222222222222222", "&lt;@!333333333333333333&gt;", true)
+	discordNicknames.Set("333333333333333333", "Alice", true)
+	discordNicknames.Set("444444444444444444", "", false)
+	discordRoles.Set("555555555555555555", "&lt;@&amp;555555555555555555&gt;", true)
+	discordChannels.Set("666666666666666666", "&lt;#666666666666666666&gt;", true)
+
+	tests := []struct {
+		name     string
+		fill     func(string) string
+		msg      string
+		expected string
+	}{
+		{
+			name:     "nickname that mentions itself",
+			fill:     chatFillMentions,
+			msg:      "hi &lt;@111111111111111111&gt;",
+			expected: "hi @&lt;@111111111111111111&gt;",
+		},
+		{
+			name:     "nickname that mentions another user",
+			fill:     chatFillMentions,
+			msg:      "&lt;@222222222222222222&gt; and &lt;@333333333333333333&gt;",
+			expected: "@&lt;@!333333333333333333&gt; and @Alice",
+		},
+		{
+			name:     "the same mention twice",
+			fill:     chatFillMentions,
+			msg:      "&lt;@!333333333333333333&gt; &lt;@333333333333333333&gt;",
+			expected: "@Alice @Alice",
+		},
+		{
+			name:     "deleted user",
+			fill:     chatFillMentions,
+			msg:      "&lt;@444444444444444444&gt;",
+			expected: "@" + UnknownDiscordUser,
+		},
+		{
+			name:     "role that mentions itself",
+			fill:     chatFillRoles,
+			msg:      "&lt;@&amp;555555555555555555&gt;",
+			expected: "@&lt;@&amp;555555555555555555&gt;",
+		},
+		{
+			name:     "channel that mentions itself",
+			fill:     chatFillChannels,
+			msg:      "&lt;#666666666666666666&gt;",
+			expected: "#&lt;#666666666666666666&gt;",
+		},
+	}
+
+	for _, test := range tests {
+		done := make(chan string)
+		go func(fill func(string) string, msg string) {
+			done <- fill(msg)
+		}(test.fill, test.msg)
+
+		select {
+		case filled := <-done:
+			if filled != test.expected {
+				t.Errorf("%v: filled %q to %q, expected %q", test.name, test.msg, filled,
+					test.expected)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%v: filling %q did not finish", test.name, test.msg)
+		}
+	}
+}
+
+func TestChatReplaceSpoilers(t *testing.T) {
+	defer chatTestConnectDiscord()()
+
+	tests := []struct {
+		name     string
+		msg      string
+		expected string
+	}{
+		{
+			name:     "a single spoiler",
+			msg:      "the answer is ||42|| ok",
+			expected: `the answer is <span class="spoiler">42</span> ok`,
+		},
+		{
+			name:     "a spoiler at the start and the end",
+			msg:      "||a||",
+			expected: `<span class="spoiler">a</span>`,
+		},
+		{
+			name:     "back-to-back spoilers",
+			msg:      "||a|| ||b||",
+			expected: `<span class="spoiler">a</span> <span class="spoiler">b</span>`,
+		},
+		{
+			name: "three back-to-back spoilers",
+			msg:  "x ||a|| ||b|| ||c|| y",
+			expected: `x <span class="spoiler">a</span> <span class="spoiler">b</span> ` +
+				`<span class="spoiler">c</span> y`,
+		},
+		{
+			name:     "the spaces around a spoiler are kept",
+			msg:      "a  ||b||  c",
+			expected: `a  <span class="spoiler">b</span>  c`,
+		},
+		{
+			name:     "spoilers at line boundaries",
+			msg:      "||a||\n||b||",
+			expected: "<span class=\"spoiler\">a</span>\n<span class=\"spoiler\">b</span>",
+		},
+		{
+			name:     "pipes inside of a word are not a spoiler",
+			msg:      "a||b||c",
+			expected: "a||b||c",
+		},
+	}
+
+	for _, test := range tests {
+		if replaced := chatReplaceSpoilers(test.msg); replaced != test.expected {
+			t.Errorf("%v: chatReplaceSpoilers(%q) = %q, expected %q", test.name, test.msg,
+				replaced, test.expected)
+		}
+	}
+}
+
+func TestChatSnippet(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      string
+		maxRunes int
+		expected string
+	}{
+		{
+			name:     "a short message",
+			msg:      "hello",
+			maxRunes: 10,
+			expected: "hello",
+		},
+		{
+			name:     "exactly the maximum length",
+			msg:      "hello",
+			maxRunes: 5,
+			expected: "hello",
+		},
+		{
+			name:     "a long message",
+			msg:      "hello world",
+			maxRunes: 5,
+			expected: "hello...",
+		},
+		{
+			name:     "accented characters at the cut",
+			msg:      "héllo wörld",
+			maxRunes: 8,
+			expected: "héllo wö...",
+		},
+		{
+			name:     "emojis at the cut",
+			msg:      "😀😃😄😁",
+			maxRunes: 2,
+			expected: "😀😃...",
+		},
+		{
+			name:     "a link",
+			msg:      `see <a href="/replay/123" target="_blank" rel="noopener noreferrer">#123</a>`,
+			maxRunes: 100,
+			expected: "see #123",
+		},
+		{
+			name:     "formatting",
+			msg:      "<strong>bold</strong> and <em>italic</em>",
+			maxRunes: 100,
+			expected: "bold and italic",
+		},
+		{
+			name:     "tags do not count towards the length",
+			msg:      "<strong>abc</strong>def",
+			maxRunes: 4,
+			expected: "abcd...",
+		},
+		{
+			name:     "half of an entity at the cut",
+			msg:      "a &amp; b",
+			maxRunes: 5,
+			expected: "a ...",
+		},
+		{
+			name:     "a whole entity at the cut",
+			msg:      "a &amp; b",
+			maxRunes: 7,
+			expected: "a &amp;...",
+		},
+		{
+			name:     "a spoiler",
+			msg:      `the answer is <span class="spoiler">42</span>`,
+			maxRunes: 100,
+			expected: "the answer is [spoiler]",
+		},
+		{
+			name: "a spoiler with a mention inside of it",
+			msg: `<span class="spoiler">hi <span class="mention">@Alice</span> there</span> ` +
+				`and <span class="spoiler">bye</span>`,
+			maxRunes: 100,
+			expected: "[spoiler] and [spoiler]",
+		},
+		{
+			name:     "a long spoiler is not revealed by the cut",
+			msg:      `<span class="spoiler">a very long secret</span> after`,
+			maxRunes: 12,
+			expected: "[spoiler] af...",
+		},
+	}
+
+	for _, test := range tests {
+		snippet := chatSnippet(test.msg, test.maxRunes)
+		if snippet != test.expected {
+			t.Errorf("%v: chatSnippet(%q, %v) = %q, expected %q", test.name, test.msg,
+				test.maxRunes, snippet, test.expected)
+		}
+		if !utf8.ValidString(snippet) {
+			t.Errorf("%v: chatSnippet(%q, %v) = %q, which is not valid UTF-8", test.name,
+				test.msg, test.maxRunes, snippet)
+		}
+	}
+}
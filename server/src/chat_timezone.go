@@ -0,0 +1,95 @@
+// The "Datetime" of every message is in UTC, so the times in the strings that the server generates
+// for a specific user (e.g. "/history" and "/search") are shown in their timezone instead
+// The timezone is stored in the "timezone" setting, which is blank for UTC
+
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+// /tz [timezone]
+func chatTZ(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if len(d.Args) == 0 {
+		msg := "Your timezone is currently set to: " + s.Timezone().String() + " " +
+			"(the format of the /tz command is: /tz [timezone], e.g. /tz America/New_York)"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	if len(d.Args) != 1 {
+		msg := "The format of the /tz command is: /tz [timezone]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	var location *time.Location
+	if v, ok := chatParseTimezone(d.Args[0]); !ok {
+		msg := "\"" + d.Args[0] + "\" is not a valid timezone. Please use a name from the tz " +
+			"database, such as \"Europe/Paris\" or \"UTC\"."
+		chatServerSendPM(s, msg, d.Room)
+		return
+	} else {
+		location = v
+	}
+
+	if !setting(s, &CommandData{ // nolint: exhaustivestruct
+		Name:    "timezone",
+		Setting: chatTimezoneSetting(location),
+	}) {
+		return
+	}
+
+	now := s.FormatTimestamp(time.Now())
+	msg := "Your timezone is now set to: " + location.String() + " (the current time is " + now +
+		")"
+	chatServerSendPM(s, msg, d.Room)
+}
+
+// chatParseTimezone returns false if the name is not in the tz database
+// A blank name is the same as UTC
+func chatParseTimezone(name string) (*time.Location, bool) {
+	// "Local" is accepted by the "LoadLocation()" function,
+	// but it refers to the timezone of the server, which might change
+	if name == "" || strings.EqualFold(name, "UTC") {
+		return time.UTC, true
+	}
+	if name == "Local" {
+		return nil, false
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, false
+	}
+	return location, true
+}
+
+// chatTimezoneSetting converts a timezone to the value that is stored in the "timezone" setting
+func chatTimezoneSetting(location *time.Location) string {
+	if location == time.UTC {
+		return ""
+	}
+	return location.String()
+}
+
+// chatGetTimezone is used when a user connects
+// Invalid settings fall back to UTC (e.g. if a timezone was removed from the tz database)
+func chatGetTimezone(setting string) *time.Location {
+	location, ok := chatParseTimezone(setting)
+	if !ok {
+		logger.Warn("Failed to load the timezone of \"" + setting + "\", so using UTC instead.")
+		return time.UTC
+	}
+	return location
+}
+
+// FormatTimestamp is the same as the "formatTimestampUnix()" function,
+// but it uses the timezone of the user
+func (s *Session) FormatTimestamp(datetime time.Time) string {
+	return formatTimestampUnix(datetime.In(s.Timezone()))
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sasha-s/go-deadlock"
+)
+
+// ChatTopicMessage is sent to clients when the topic of a room has changed
+// (and the current topic is also sent with the chat history in the "chatList" message)
+type ChatTopicMessage struct {
+	Topic string `json:"topic"` // Blank if the topic was cleared
+	Room  string `json:"room"`
+}
+
+var (
+	// The topic of the lobby is only kept in memory, so it is reset when the server restarts
+	// (the topic of a table is stored on the table)
+	lobbyTopic      string
+	lobbyTopicMutex = &deadlock.RWMutex{}
+)
+
+// /topic [text]
+// Set a blurb for the room (e.g. the house rules of a table); with no text, the topic is cleared
+func chatTopic(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if t == nil {
+		if !s.Moderator {
+			chatServerSendPM(s, ChatMsgNotMod, d.Room)
+			return
+		}
+	} else if s.UserID != t.OwnerID {
+		chatServerSendPM(s, "Only the table owner can change the topic.", d.Room)
+		return
+	}
+
+	// The topic was already escaped in the "commandChat()" function
+	topic := strings.TrimSpace(strings.Join(d.Args, " "))
+	if t == nil {
+		if topic == lobbyGetTopic() {
+			chatServerSendPM(s, "The topic is already set to that.", d.Room)
+			return
+		}
+		lobbyTopicMutex.Lock()
+		lobbyTopic = topic
+		lobbyTopicMutex.Unlock()
+	} else {
+		if topic == t.Topic {
+			chatServerSendPM(s, "The topic is already set to that.", d.Room)
+			return
+		}
+		t.Topic = topic
+	}
+
+	chatTopicMessage := &ChatTopicMessage{
+		Topic: chatFillAll(topic),
+		Room:  d.Room,
+	}
+	if t == nil {
+		// Lobby messages go to everyone
+		for _, s2 := range sessions.GetList() {
+			s2.Emit("chatTopic", chatTopicMessage)
+		}
+	} else {
+		t.NotifyChatTopic(chatTopicMessage)
+	}
+
+	var msg string
+	if topic == "" {
+		msg = s.Username + " cleared the topic."
+	} else {
+		msg = s.Username + " changed the topic to: " + chatTopicMessage.Topic
+	}
+	chatServerSend(ctx, msg, d.Room, d.NoTablesLock)
+}
+
+// lobbyGetTopic returns the topic of the lobby, which is still escaped
+func lobbyGetTopic() string {
+	lobbyTopicMutex.RLock()
+	defer lobbyTopicMutex.RUnlock()
+
+	return lobbyTopic
+}
@@ -0,0 +1,273 @@
+// Chat messages can be translated on demand with the "/translate" command
+// The translation is done by a LibreTranslate-compatible API, which is disabled unless the
+// "CHAT_TRANSLATE_URL" and "CHAT_TRANSLATE_API_KEY" environment variables are set
+// Since every request to the API costs money, translations are cached per message and the command
+// is rate-limited by the "/translate" entry in "chat_cooldown.go"
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"html"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+	"github.com/sasha-s/go-deadlock"
+)
+
+const (
+	ChatTranslateTimeout = 5 * time.Second
+	// The oldest translations are forgotten once there are this many
+	ChatTranslateCacheSize = 1000
+	ChatTranslateMaxBytes  = 64 * 1024
+)
+
+var (
+	chatTranslateURL    string
+	chatTranslateAPIKey string
+
+	chatTranslateClient = &http.Client{ // nolint: exhaustivestruct
+		Timeout: ChatTranslateTimeout,
+	}
+
+	chatTranslations = NewChatTranslations()
+)
+
+type ChatTranslation struct {
+	SourceLanguage string
+	Msg            string // HTML-escaped
+}
+
+type ChatTranslations struct {
+	translations map[string]*ChatTranslation // Indexed by message ID and target language
+	keys         []string                    // In the order that they were added
+	mutex        *deadlock.Mutex
+}
+
+func NewChatTranslations() *ChatTranslations {
+	return &ChatTranslations{
+		translations: make(map[string]*ChatTranslation),
+		keys:         make([]string, 0),
+		mutex:        &deadlock.Mutex{},
+	}
+}
+
+func (ct *ChatTranslations) Get(messageID string, lang string) (*ChatTranslation, bool) {
+	ct.mutex.Lock()
+	defer ct.mutex.Unlock()
+
+	translation, ok := ct.translations[messageID+":"+lang]
+	return translation, ok
+}
+
+func (ct *ChatTranslations) Set(messageID string, lang string, translation *ChatTranslation) {
+	ct.mutex.Lock()
+	defer ct.mutex.Unlock()
+
+	key := messageID + ":" + lang
+	if _, ok := ct.translations[key]; !ok {
+		ct.keys = append(ct.keys, key)
+	}
+	ct.translations[key] = translation
+
+	for len(ct.keys) > ChatTranslateCacheSize {
+		delete(ct.translations, ct.keys[0])
+		ct.keys = ct.keys[1:]
+	}
+}
+
+// The request and response formats of the "/translate" endpoint of LibreTranslate
+// https://libretranslate.com/docs/
+type ChatTranslateRequest struct {
+	Q      string `json:"q"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Format string `json:"format"`
+	APIKey string `json:"api_key"`
+}
+
+type ChatTranslateResponse struct {
+	TranslatedText   string `json:"translatedText"`
+	DetectedLanguage struct {
+		Language string `json:"language"`
+	} `json:"detectedLanguage"`
+	Error string `json:"error"`
+}
+
+func chatTranslateInit() {
+	chatTranslateURL = os.Getenv("CHAT_TRANSLATE_URL")
+	chatTranslateAPIKey = os.Getenv("CHAT_TRANSLATE_API_KEY")
+	if chatTranslateURL == "" || chatTranslateAPIKey == "" {
+		chatTranslateURL = ""
+		chatTranslateAPIKey = ""
+	}
+}
+
+// /translate [id]
+// Translate a message into the language of the user and send it to them privately
+func chatTranslate(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if chatTranslateURL == "" {
+		chatServerSendPM(s, "Translation is not enabled on this server.", d.Room)
+		return
+	}
+
+	if len(d.Args) != 1 {
+		msg := "The format of the /translate command is: /translate [id]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+	messageID := d.Args[0]
+
+	lang := s.Language
+	if lang == "" {
+		lang = DefaultLanguage
+	}
+
+	// Each message only needs to be translated once into each language
+	if translation, ok := chatTranslations.Get(messageID, lang); ok {
+		chatTranslateSend(s, translation, d.Room)
+		return
+	}
+
+	// Look for the message in the in-memory chat first, in the same way as in "chat_report.go"
+	found := false
+	var message string
+	if t != nil {
+		for _, chatMsg := range t.Chat {
+			if chatMsg.ID == messageID {
+				found = true
+				message = chatMsg.Msg
+				break
+			}
+		}
+	}
+	if !found {
+		room := d.Room
+		if t != nil && t.GetReviewRoomName() != "" {
+			room = t.GetReviewRoomName()
+		}
+		if v1, _, _, v2, err := models.ChatLog.GetMessage(messageID, room); err != nil {
+			logger.Error("Failed to get chat message \"" + messageID + "\": " + err.Error())
+			s.Error(DefaultErrorMsg)
+			return
+		} else {
+			found = v1
+			message = v2
+		}
+	}
+
+	if !found {
+		chatServerSendPM(s, ChatMsgMessageNotFound, d.Room, messageID)
+		return
+	}
+
+	// The message is HTML-escaped and it might contain formatting (e.g. links),
+	// but the text that is sent to the API should be plain
+	// (the text of spoilers is not revealed)
+	message = chatRemoveSpoilers(message)
+	message = htmlTagRegExp.ReplaceAllString(message, "")
+	message = strings.TrimSpace(html.UnescapeString(message))
+	if message == "" {
+		chatServerSendPM(s, "There is no text in that message to translate.", d.Room)
+		return
+	}
+
+	// The request can take a while, so it is performed in a new goroutine
+	// (since the table might be locked right now)
+	room := d.Room
+	go func() {
+		var translation *ChatTranslation
+		if v, err := chatTranslateGet(message, lang); err != nil {
+			logger.Error("Failed to translate chat message \"" + messageID + "\": " + err.Error())
+			chatServerSendPM(s, ChatMsgTranslateFailed, room)
+			return
+		} else {
+			translation = v
+		}
+
+		chatTranslations.Set(messageID, lang, translation)
+		chatTranslateSend(s, translation, room)
+	}()
+}
+
+func chatTranslateSend(s *Session, translation *ChatTranslation, room string) {
+	chatServerSendPM(s, ChatMsgTranslated, room, translation.SourceLanguage, translation.Msg)
+}
+
+func chatTranslateGet(msg string, lang string) (*ChatTranslation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ChatTranslateTimeout)
+	defer cancel()
+
+	var body []byte
+	if v, err := json.Marshal(&ChatTranslateRequest{
+		Q:      msg,
+		Source: "auto",
+		Target: lang,
+		Format: "text",
+		APIKey: chatTranslateAPIKey,
+	}); err != nil {
+		return nil, err
+	} else {
+		body = v
+	}
+
+	var req *http.Request
+	if v, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		chatTranslateURL,
+		bytes.NewReader(body),
+	); err != nil {
+		return nil, err
+	} else {
+		req = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp *http.Response
+	if v, err := chatTranslateClient.Do(req); err != nil {
+		return nil, err
+	} else {
+		resp = v
+	}
+	defer resp.Body.Close()
+
+	var respBody []byte
+	if v, err := ioutil.ReadAll(io.LimitReader(resp.Body, ChatTranslateMaxBytes)); err != nil {
+		return nil, err
+	} else {
+		respBody = v
+	}
+
+	var translateResponse ChatTranslateResponse
+	if err := json.Unmarshal(respBody, &translateResponse); err != nil {
+		return nil, errors.New("the response was not valid JSON (with a status code of " +
+			resp.Status + ")")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("the status code was " + resp.Status + ": " +
+			translateResponse.Error)
+	}
+	if translateResponse.TranslatedText == "" {
+		return nil, errors.New("the response did not contain a translation")
+	}
+
+	sourceLanguage := translateResponse.DetectedLanguage.Language
+	if sourceLanguage == "" {
+		sourceLanguage = "?"
+	}
+
+	// The translation is shown in a message from the server, which is not escaped
+	return &ChatTranslation{
+		SourceLanguage: html.EscapeString(sourceLanguage),
+		Msg:            html.EscapeString(translateResponse.TranslatedText),
+	}, nil
+}
@@ -0,0 +1,84 @@
+// Turns can be referenced in the chat of a game or a replay with "!turn [turn]" (e.g. "!turn 14")
+// The turn is sent to clients in a separate "chatTurnLink" message,
+// so that they can show a button that goes to that turn in the replay
+// Plain numbers are left alone, since most of the numbers in chat are not turns
+
+package main
+
+import (
+	"regexp"
+	"strconv"
+)
+
+const (
+	// Only the first few turns in a message get a link
+	ChatTurnLinkMaxPerMessage = 3
+)
+
+type ChatTurnLinkMessage struct {
+	ID   string `json:"id"` // The ID of the chat message that contains the turn
+	Room string `json:"room"`
+	// Starts at 1 (like the turns that are shown to the user)
+	Turn int `json:"turn"`
+}
+
+var (
+	// e.g. "!turn 14"
+	turnReferenceRegExp = regexp.MustCompile(`(?i)(?:^|\s)!turn (\d+)\b`)
+)
+
+// chatTurnLinkSend sends a link for every valid turn that is referenced in a chat message
+// It is assumed that the table mutex is locked when calling this function
+func chatTurnLinkSend(messageID string, room string, msg string, t *Table, recipients []*Session) {
+	if messageID == "" || len(recipients) == 0 {
+		return
+	}
+
+	for _, turn := range chatParseTurnReferences(msg, t) {
+		turnLinkMessage := &ChatTurnLinkMessage{
+			ID:   messageID,
+			Room: room,
+			Turn: turn,
+		}
+		for _, s := range recipients {
+			s.Emit("chatTurnLink", turnLinkMessage)
+		}
+	}
+}
+
+// chatParseTurnReferences finds the "!turn [turn]" tokens in a message
+// Tokens with a turn that is not in the game are ignored (and are left in the message as normal
+// text)
+func chatParseTurnReferences(msg string, t *Table) []int {
+	// Turns only exist once the game has started
+	if t == nil || t.Game == nil || (!t.Running && !t.Replay) {
+		return nil
+	}
+
+	// Internally, turns start at 0
+	// In a replay, the final turn is stored separately, since the current turn moves around
+	finalTurn := t.Game.Turn + 1
+	if t.Replay {
+		finalTurn = t.Game.EndTurn + 1
+	}
+
+	turns := make([]int, 0)
+	seen := make(map[int]struct{})
+	for _, match := range turnReferenceRegExp.FindAllStringSubmatch(msg, -1) {
+		turn, err := strconv.Atoi(match[1])
+		if err != nil || turn < 1 || turn > finalTurn {
+			continue
+		}
+		if _, ok := seen[turn]; ok {
+			continue
+		}
+		seen[turn] = struct{}{}
+
+		turns = append(turns, turn)
+		if len(turns) >= ChatTurnLinkMaxPerMessage {
+			break
+		}
+	}
+
+	return turns
+}
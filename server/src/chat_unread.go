@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type UnreadTable struct {
+	ID     uint64
+	Name   string
+	Unread int
+}
+
+// /unread
+// Lists the tables that the user is playing at or spectating that have unread chat messages
+func chatUnread(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	// The table that the command was sent from (if any) is already locked,
+	// and the other tables cannot be locked while it is locked, since another command could be
+	// holding one of them while waiting for this one (which would deadlock)
+	// Thus, the tables are checked in a new goroutine that does not hold any locks
+	go chatUnreadSend(ctx, s, d.Room)
+}
+
+func chatUnreadSend(ctx context.Context, s *Session, room string) {
+	tables.RLock()
+	tableIDs := make([]uint64, 0)
+	tableIDs = append(tableIDs, tables.GetTablesUserPlaying(s.UserID)...)
+	tableIDs = append(tableIDs, tables.GetTablesUserSpectating(s.UserID)...)
+	tables.RUnlock()
+
+	unreadTables := make([]*UnreadTable, 0)
+	seen := make(map[uint64]struct{})
+	for _, tableID := range tableIDs {
+		if _, ok := seen[tableID]; ok {
+			continue
+		}
+		seen[tableID] = struct{}{}
+
+		t, exists := getTableAndLock(ctx, nil, tableID, true, true)
+		if !exists {
+			continue
+		}
+		unreadTable, ok := chatUnreadGet(t, s.UserID)
+		t.Unlock(ctx)
+		if ok {
+			unreadTables = append(unreadTables, unreadTable)
+		}
+	}
+
+	if len(unreadTables) == 0 {
+		chatServerSendPM(s, "You do not have any unread messages at your tables.", room)
+		return
+	}
+
+	// The tables with the most unread messages are listed first
+	sort.Slice(unreadTables, func(i, j int) bool {
+		if unreadTables[i].Unread != unreadTables[j].Unread {
+			return unreadTables[i].Unread > unreadTables[j].Unread
+		}
+		return unreadTables[i].ID < unreadTables[j].ID
+	})
+
+	entries := make([]string, 0, len(unreadTables))
+	for _, unreadTable := range unreadTables {
+		entry := html.EscapeString(unreadTable.Name) + " (#" +
+			strconv.FormatUint(unreadTable.ID, 10) + "): " + strconv.Itoa(unreadTable.Unread)
+		entries = append(entries, entry)
+	}
+
+	msg := "You have unread messages at "
+	if len(unreadTables) == 1 {
+		msg += "1 table: "
+	} else {
+		msg += strconv.Itoa(len(unreadTables)) + " tables: "
+	}
+	msg += strings.Join(entries, ", ")
+	chatServerSendPM(s, msg, room)
+}
+
+// chatUnreadGet returns false if the table has no unread messages
+// It is assumed that the table mutex is locked when calling this function
+func chatUnreadGet(t *Table, userID int) (*UnreadTable, bool) {
+	unread := t.GetChatUnread(userID)
+	if unread == 0 {
+		return nil, false
+	}
+
+	return &UnreadTable{
+		ID:     t.ID,
+		Name:   t.Name,
+		Unread: unread,
+	}, true
+}
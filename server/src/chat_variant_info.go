@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// When a search matches more variants than this, only the closest ones are suggested
+	VariantInfoMaxSuggestions = 5
+)
+
+// /variantinfo [variant name]
+func chatVariantInfo(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if len(d.Args) == 0 {
+		msg := "The format of the /variantinfo command is: /variantinfo [variant name]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	// The arguments were escaped in the "commandChat()" function,
+	// but the variant names contain characters like "&"
+	query := html.UnescapeString(strings.Join(d.Args, " "))
+
+	matches := chatVariantInfoSearch(query)
+	if len(matches) == 0 {
+		msg := "There are no variants that match \"" + html.EscapeString(query) + "\"."
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	if len(matches) > 1 {
+		suggestions := matches
+		if len(suggestions) > VariantInfoMaxSuggestions {
+			suggestions = suggestions[:VariantInfoMaxSuggestions]
+		}
+		escapedSuggestions := make([]string, 0, len(suggestions))
+		for _, variant := range suggestions {
+			escapedSuggestions = append(escapedSuggestions, html.EscapeString(variant.Name))
+		}
+		msg := "There are " + strconv.Itoa(len(matches)) + " variants that match \"" +
+			html.EscapeString(query) + "\". Did you mean: " +
+			strings.Join(escapedSuggestions, ", ")
+		if len(matches) > len(suggestions) {
+			msg += ", ..."
+		}
+		msg += "?"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	chatServerSendPM(s, chatVariantInfoSummary(matches[0]), d.Room)
+}
+
+// chatVariantInfoSearch returns the variants that contain every word of the query,
+// with the closest matches first
+// An exact match (ignoring case) is always the only result,
+// since otherwise a variant like "Black" could never be chosen over "Black & Rainbow"
+func chatVariantInfoSearch(query string) []*Variant {
+	if variant, ok := chatGetVariantByName(query); ok {
+		return []*Variant{variant}
+	}
+
+	words := strings.Fields(strings.ToLower(query))
+	normalizedQuery := strings.Join(words, " ")
+	matches := make([]*Variant, 0)
+	for _, name := range variantNames {
+		normalizedName := strings.ToLower(name)
+		allWordsMatch := true
+		for _, word := range words {
+			if !strings.Contains(normalizedName, word) {
+				allWordsMatch = false
+				break
+			}
+		}
+		if allWordsMatch {
+			matches = append(matches, variants[name])
+		}
+	}
+
+	// Variants that start with the query are the closest, and then shorter names are closer
+	sort.SliceStable(matches, func(i, j int) bool {
+		iPrefix := strings.HasPrefix(strings.ToLower(matches[i].Name), normalizedQuery)
+		jPrefix := strings.HasPrefix(strings.ToLower(matches[j].Name), normalizedQuery)
+		if iPrefix != jPrefix {
+			return iPrefix
+		}
+		return len(matches[i].Name) < len(matches[j].Name)
+	})
+
+	return matches
+}
+
+func chatVariantInfoSummary(variant *Variant) string {
+	suitNames := make([]string, 0, len(variant.Suits))
+	for _, suit := range variant.Suits {
+		suitNames = append(suitNames, suit.Name)
+	}
+	colorClues := "none"
+	if len(variant.ClueColors) > 0 {
+		colorClues = strings.Join(variant.ClueColors, ", ")
+	}
+	rankClues := "none"
+	if len(variant.ClueRanks) > 0 {
+		ranks := make([]string, 0, len(variant.ClueRanks))
+		for _, rank := range variant.ClueRanks {
+			ranks = append(ranks, strconv.Itoa(rank))
+		}
+		rankClues = strings.Join(ranks, ", ")
+	}
+
+	rules := []string{
+		strconv.Itoa(len(variant.Suits)) + " suits (" + strings.Join(suitNames, ", ") + ")",
+		"color clues: " + colorClues,
+		"rank clues: " + rankClues,
+		"max score: " + strconv.Itoa(variant.MaxScore),
+	}
+	rules = append(rules, chatVariantInfoSuitRules(variant)...)
+	rules = append(rules, chatVariantInfoSpecialRules(variant)...)
+
+	url := getURLFromPath("/variant/" + strconv.Itoa(variant.ID))
+	link := "<a href=\"" + url + "\" target=\"_blank\" rel=\"noopener noreferrer\">" +
+		"variant stats</a>"
+
+	return "<strong>" + html.EscapeString(variant.Name) + "</strong>: " +
+		html.EscapeString(strings.Join(rules, "; ")) + " (" + link + ")"
+}
+
+// chatVariantInfoSuitRules describes the suits that do not behave like a normal suit
+func chatVariantInfoSuitRules(variant *Variant) []string {
+	rules := make([]string, 0)
+	for _, suit := range variant.Suits {
+		behaviors := make([]string, 0)
+		if suit.OneOfEach {
+			behaviors = append(behaviors, "has one of each card")
+		}
+		if suit.Reversed {
+			behaviors = append(behaviors, "is played in reverse (from 5 to 1)")
+		}
+		if suit.AllClueColors {
+			behaviors = append(behaviors, "is touched by every color clue")
+		} else if suit.NoClueColors {
+			behaviors = append(behaviors, "is not touched by any color clue")
+		} else if suit.Prism {
+			behaviors = append(behaviors, "is touched by a different color clue for each rank")
+		}
+		if suit.AllClueRanks {
+			behaviors = append(behaviors, "is touched by every rank clue")
+		} else if suit.NoClueRanks {
+			behaviors = append(behaviors, "is not touched by any rank clue")
+		}
+		if len(behaviors) > 0 {
+			rules = append(rules, suit.Name+" "+strings.Join(behaviors, " and "))
+		}
+	}
+
+	return rules
+}
+
+// chatVariantInfoSpecialRules describes the rules that apply to the entire variant
+func chatVariantInfoSpecialRules(variant *Variant) []string {
+	rules := make([]string, 0)
+
+	if variant.ColorCluesTouchNothing {
+		rules = append(rules, "color clues do not touch any cards")
+	}
+	if variant.RankCluesTouchNothing {
+		rules = append(rules, "rank clues do not touch any cards")
+	}
+	if variant.OddsAndEvens {
+		rules = append(rules, "rank clues are either odd or even")
+	}
+
+	if variant.SpecialRank != -1 && variant.SpecialRank != 0 {
+		specialRank := strconv.Itoa(variant.SpecialRank) + "s"
+		if variant.SpecialAllClueColors {
+			rules = append(rules, specialRank+" are touched by every color clue")
+		} else if variant.SpecialNoClueColors {
+			rules = append(rules, specialRank+" are not touched by any color clue")
+		}
+		if variant.SpecialAllClueRanks {
+			rules = append(rules, specialRank+" are touched by every rank clue")
+		} else if variant.SpecialNoClueRanks {
+			rules = append(rules, specialRank+" are not touched by any rank clue")
+		} else if variant.SpecialDeceptive {
+			rules = append(rules, specialRank+" are touched by a different rank clue for each suit")
+		}
+	}
+
+	if variant.IsUpOrDown() {
+		rules = append(rules, "every suit can be played from 1 up to 5 or from 5 down to 1")
+	}
+	if variant.IsAlternatingClues() {
+		rules = append(rules, "color clues and rank clues must alternate")
+	}
+	if variant.IsClueStarved() {
+		rules = append(rules, "discarding only gives back half of a clue")
+	}
+	if variant.IsThrowItInAHole() {
+		rules = append(rules, "played cards stay face down until the end of the game")
+	}
+	if variant.IsDuck() {
+		rules = append(rules, "clues do not say which color or rank was chosen")
+	}
+	if variant.IsCowAndPig() {
+		rules = append(rules, "clues only say whether they are color clues or rank clues")
+	}
+	if variant.IsSynesthesia() {
+		rules = append(
+			rules,
+			"each rank also counts as one of the colors (1s are the first color and so forth)",
+		)
+	}
+	if variant.IsCriticalFours() {
+		rules = append(rules, "there is only one copy of each 4")
+	}
+
+	return rules
+}
@@ -0,0 +1,49 @@
+// New users are privately sent a welcome message the first time that they log in
+// The message is read from the file specified by the "WELCOME_MESSAGE_FILE" environment variable
+// every time that it is sent, so admins can change it without restarting the server
+// (like the "motd.txt" file)
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+func chatWelcomeSend(s *Session) {
+	welcomePath := os.Getenv("WELCOME_MESSAGE_FILE")
+	if welcomePath == "" {
+		return
+	}
+	if !filepath.IsAbs(welcomePath) {
+		welcomePath = path.Join(projectPath, welcomePath)
+	}
+
+	var msg string
+	if fileContents, err := ioutil.ReadFile(welcomePath); err != nil {
+		logger.Error("Failed to read the \"" + welcomePath + "\" file: " + err.Error())
+		return
+	} else {
+		msg = strings.TrimSpace(string(fileContents))
+	}
+
+	// Users are only marked as welcomed once there is a message to send them
+	if msg == "" {
+		return
+	}
+
+	if firstTime, err := models.Users.SetWelcomed(s.UserID); err != nil {
+		logger.Error("Failed to set the welcomed flag for user \"" + s.Username + "\": " +
+			err.Error())
+		return
+	} else if !firstTime {
+		return
+	}
+
+	chatServerSendPM(s, msg, "lobby")
+}
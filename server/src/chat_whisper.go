@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// /whisper [username] [msg]
+// Whispers are only delivered to the sender and the recipient and are never written to the database
+func chatWhisper(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	if t == nil {
+		msg := "You can only whisper to someone at a table. " +
+			"Use the /pm command to send a private message in the lobby."
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+
+	// Muted spectators cannot get around the mute by whispering
+	if !chatMutedSpectatorCheck(s, d, t) {
+		return
+	}
+
+	if len(d.Args) < 2 {
+		msg := "The format of the /whisper command is: /whisper [username] [msg]"
+		chatServerSendPM(s, msg, d.Room)
+		return
+	}
+	recipient := d.Args[0]
+	// The message was already sanitized and escaped in the "commandChat()" function
+	msg := strings.Join(d.Args[1:], " ")
+
+	if strings.EqualFold(recipient, s.Username) {
+		chatServerSendPM(s, ChatMsgWhisperSelf, d.Room)
+		return
+	}
+
+	// Find the session of the recipient
+	// (it must be someone who is currently playing or spectating at this table)
+	var recipientSession *Session
+	if !t.Replay {
+		for _, p := range t.Players {
+			if strings.EqualFold(p.Name, recipient) && p.Present && p.Session != nil {
+				recipientSession = p.Session
+				break
+			}
+		}
+	}
+	if recipientSession == nil {
+		for _, sp := range t.Spectators {
+			if strings.EqualFold(sp.Name, recipient) && sp.Session != nil {
+				recipientSession = sp.Session
+				break
+			}
+		}
+	}
+	if recipientSession == nil {
+		chatServerSendPM(s, "User \""+recipient+"\" is not playing or spectating at this table.",
+			d.Room)
+		return
+	}
+
+	chatMessage := &ChatMessage{
+		ID:        newChatMessageID(),
+		Msg:       msg,
+		Who:       s.Username,
+		Discord:   false,
+		Server:    false,
+		Datetime:  time.Now(),
+		Room:      d.Room,
+		Recipient: recipientSession.Username,
+		Reactions: make(map[string]int),
+		ReplyTo:   "",
+		Quote:     nil,
+		Action:    false,
+	}
+	if !chatIsIgnored(recipientSession, s) {
+		recipientSession.Emit("chat", chatMessage)
+	}
+	s.Emit("chat", chatMessage)
+
+	if recipientSession.AFK() {
+		chatServerSendPM(s, chatAFKMsg(recipientSession), d.Room)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+const (
+	// On busy servers, a list of names would fill up the chat, so only the counts are shown
+	WhoMaxNames = 30
+)
+
+// /who
+func chatWho(ctx context.Context, s *Session, d *CommandData, t *Table) {
+	sessionList := sessions.GetList()
+
+	numInLobby := 0
+	userIDs := make([]int, 0, len(sessionList))
+	for _, s2 := range sessionList {
+		if s2.Status() == StatusLobby {
+			numInLobby++
+		}
+		userIDs = append(userIDs, s2.UserID)
+	}
+	numAtTables := len(sessionList) - numInLobby
+
+	var msg string
+	if len(sessionList) == 1 {
+		msg = "There is 1 user online"
+	} else {
+		msg = "There are " + strconv.Itoa(len(sessionList)) + " users online"
+	}
+	msg += " (" + strconv.Itoa(numInLobby) + " in the lobby and " + strconv.Itoa(numAtTables) +
+		" at a table)"
+
+	if len(sessionList) > WhoMaxNames {
+		chatServerSendPM(s, msg+".", d.Room)
+		return
+	}
+
+	// Users can opt out of being listed from the "Settings" tooltip in the lobby
+	var appearOfflineMap map[int]struct{}
+	if v, err := models.UserSettings.GetAppearOffline(userIDs); err != nil {
+		logger.Error("Failed to get the \"appear_offline\" setting for the online users: " +
+			err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else {
+		appearOfflineMap = v
+	}
+
+	usernames := make([]string, 0, len(sessionList))
+	for _, s2 := range sessionList {
+		if _, ok := appearOfflineMap[s2.UserID]; !ok {
+			usernames = append(usernames, s2.Username)
+		}
+	}
+	sort.Slice(usernames, func(i, j int) bool {
+		return strings.ToLower(usernames[i]) < strings.ToLower(usernames[j])
+	})
+
+	if len(usernames) == 0 {
+		chatServerSendPM(s, msg+".", d.Room)
+		return
+	}
+	msg += ": " + strings.Join(usernames, ", ")
+	if numHidden := len(sessionList) - len(usernames); numHidden > 0 {
+		msg += " (and " + strconv.Itoa(numHidden) + " more)"
+	}
+	chatServerSendPM(s, msg, d.Room)
+}
@@ -16,6 +16,17 @@ type CommandData struct {
 	Msg       string `json:"msg"`
 	Room      string `json:"room"`
 	Recipient string `json:"recipient"`
+	ReplyTo   string `json:"replyTo"`
+	// Optional; a random string from the client to detect resent messages (in "chat_nonce.go")
+	Nonce string `json:"nonce"`
+
+	// chatReact and chatRead
+	MessageID string `json:"messageID"`
+	Emoji     string `json:"emoji"`
+
+	// chatVote
+	PollID string `json:"pollID"`
+	Option int    `json:"option"`
 
 	// tableCreate
 	Name       string   `json:"name"`
@@ -77,12 +88,20 @@ type CommandData struct {
 	OnlyDiscord          bool   `json:"-"`
 	DiscordID            string `json:"-"` // Used when echoing a message from Discord to the lobby
 	DiscordDiscriminator string `json:"-"` // Used when echoing a message from Discord to the lobby
+	// Used to keep track of which chat message a Discord message became (in "discord_messages.go")
+	DiscordMessageID string `json:"-"`
 	// Used to pass chat command arguments to a chat command handler
 	Args []string `json:"-"`
 	// Used when a command handler calls another command handler
 	// (e.g. the mutex lock is already acquired and does not need to be acquired again)
 	NoTableLock  bool `json:"-"` // To avoid "t.Lock()"
 	NoTablesLock bool `json:"-"` // To avoid "tables.Lock()"
+	// True if this is a chat message that should not be written to the database
+	NoDatabase bool `json:"-"`
+	// True if this is a chat message from the "/everyone" command
+	HighlightAll bool `json:"-"`
+	// Used when the game is paused from the "/pause" command (this is not escaped)
+	PauseReason string `json:"-"`
 }
 
 var (
@@ -113,6 +132,8 @@ func commandInit() {
 	commandMap["chatPM"] = commandChatPM
 	commandMap["chatRead"] = commandChatRead
 	commandMap["chatTyping"] = commandChatTyping
+	commandMap["chatReact"] = commandChatReact
+	commandMap["chatVote"] = commandChatVote
 	commandMap["chatFriend"] = commandChatFriend
 	commandMap["chatUnfriend"] = commandChatUnfriend
 	commandMap["chatPlayerInfo"] = commandChatPlayerInfo
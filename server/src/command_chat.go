@@ -14,14 +14,28 @@ import (
 )
 
 const (
-	MaxChatLength       = 300
-	MaxChatLengthServer = 600
+	// By default, chat messages can be up to 1000 characters long
+	// (this is measured in runes so that multibyte characters are not unfairly penalized)
+	DefaultMaxChatLength = 1000
+	MaxChatLengthServer  = 600
+
+	// Multi-line messages are allowed, but lines after this are joined together
+	// (to prevent people from pushing other messages off of the screen)
+	ChatMaxLines = 10
+
+	ReplyNotFoundFail = "The message that you are replying to does not exist in this room."
 )
 
 var (
 	lobbyRoomRegExp = regexp.MustCompile(`table(\d+)`)
+
+	maxChatLength int
 )
 
+func chatMaxLengthInit() {
+	maxChatLength = getEnvInt("CHAT_MAX_LENGTH", DefaultMaxChatLength)
+}
+
 // commandChat is sent when the user presses enter after typing a text message
 // It is also used by the server to send chat messages
 // Unlike many other commands, this command can be called with a nil session (by the server),
@@ -31,6 +45,8 @@ var (
 // {
 //   msg: 'hi',
 //   room: 'lobby', // Room can also be "table1", "table1234", etc.
+//   replyTo: 'c2f1...', // Optional; the ID of the message being replied to
+//   nonce: '5f0c...', // Optional; echoed back so that the client knows that it was received
 // }
 func commandChat(ctx context.Context, s *Session, d *CommandData) {
 	// Local variables
@@ -48,12 +64,62 @@ func commandChat(ctx context.Context, s *Session, d *CommandData) {
 		d.Username = s.Username
 	}
 
+	// Check to see if this is a message that the client already sent, but is sending again
+	// because it did not see it in time (in "chat_nonce.go")
+	if !chatNonceCheck(s, d) {
+		return
+	}
+
 	// Check to see if their IP has been muted
 	if s != nil && s.Muted {
 		s.Warning("You have been muted by an administrator.")
 		return
 	}
 
+	// Check to see if they were temporarily muted for spamming (in "chat_auto_mute.go")
+	if !d.Server && !d.Discord && !chatAutoMuteCheck(s, d.Room) {
+		return
+	}
+
+	// Expand text macros (e.g. "/shrug")
+	// (this must be before the message is sanitized and escaped so that a macro cannot inject HTML)
+	if s != nil && !d.Server && !d.Discord {
+		d.Msg = chatMacros.Expand(d.Msg)
+	}
+
+	// Check to see if the message is too long
+	// (this must be before anything else is done with the message, like sending it to Discord)
+	tooLong := maxChatLength > 0 && utf8.RuneCountInString(d.Msg) > maxChatLength
+	if s != nil && !d.Server && !d.Discord && tooLong {
+		chatServerSendPM(s, ChatMsgTooLong, d.Room, maxChatLength)
+		return
+	}
+
+	// Check to see if they are sending messages to this room too quickly
+	// (server messages and messages from Discord are exempt)
+	if !d.Server && !d.Discord && !chatRateLimiter.Check(userID, d.Room) {
+		chatMetrics.Dropped(ChatDroppedRateLimit)
+		chatServerSendPM(s, ChatMsgRateLimited, d.Room, chatRateLimitMessages,
+			int(chatRateLimitWindow.Seconds()))
+		chatAutoMuteStrike(s, d)
+		return
+	}
+
+	// Check to see if they are sending the same message over and over
+	if !d.Server && !d.Discord && !chatFloodDetector.Check(userID, d.Msg) {
+		chatMetrics.Dropped(ChatDroppedFlood)
+		chatServerSendPM(s, ChatMsgFlooded, d.Room, chatFloodRepeats,
+			int(chatFloodWindow.Seconds()))
+		return
+	}
+
+	// Check to see if they are using a command that is still on cooldown
+	// (this is in addition to the rate-limiting above)
+	if !chatCommandCooldownCheck(s, d, userID) {
+		chatMetrics.Dropped(ChatDroppedCooldown)
+		return
+	}
+
 	// Sanitize and validate the chat message
 	if v, valid := sanitizeChatInput(s, d.Msg, d.Server); !valid {
 		return
@@ -65,12 +131,28 @@ func commandChat(ctx context.Context, s *Session, d *CommandData) {
 	// because we do not want to send HTML-escaped text to Discord
 	rawMsg := d.Msg
 
+	// Check to see if the message contains a banned word or pattern
+	// (this must be before the message is sent anywhere, including Discord)
+	if !chatBannedWordsCheck(s, d, rawMsg) {
+		return
+	}
+
 	// Escape all HTML special characters to stop XSS attacks and so forth
 	// (but make an exception for server messages so that the server can properly send links)
 	if !d.Server || d.Discord {
 		d.Msg = html.EscapeString(d.Msg)
 	}
 
+	// Check to see if they are mentioning too many people at once
+	// (this must be after the message is escaped and before the mentions are filled in)
+	// (server messages and messages from Discord are exempt)
+	if s != nil && !d.Server && !d.Discord && chatMaxMentions > 0 &&
+		chatCountMentions(d.Msg) > chatMaxMentions {
+
+		chatServerSendPM(s, ChatMsgTooManyMentions, d.Room, chatMaxMentions)
+		return
+	}
+
 	// Validate the room
 	if d.Room != "lobby" && !strings.HasPrefix(d.Room, "table") {
 		if s != nil {
@@ -79,6 +161,12 @@ func commandChat(ctx context.Context, s *Session, d *CommandData) {
 		return
 	}
 
+	// Give the Discord cache a chance to look up the mentions before any locks are acquired
+	// (server messages do not contain mentions, and they might be sent while a table is locked)
+	if !d.Server || d.Discord {
+		chatPrefetchDiscordMentions(d.Msg)
+	}
+
 	chat(ctx, s, d, userID, rawMsg)
 }
 
@@ -92,27 +180,70 @@ func chat(ctx context.Context, s *Session, d *CommandData, userID int, rawMsg st
 		}
 		text += "> "
 	}
-	text += d.Msg
+	text += chatLogRedact(d.Msg) // The bodies of whispers are not logged
 	logger.Info(text)
 
 	// Handle in-game chat in a different function; the rest of this function will be for lobby chat
 	if strings.HasPrefix(d.Room, "table") {
-		commandChatTable(ctx, s, d)
+		commandChatTable(ctx, s, d, rawMsg)
 		return
 	}
 
+	// Check to see if a moderator has made the lobby read-only
+	// (this must be before the silent commands, since some of them change the chat, e.g. "/edit";
+	// moderators are exempt, so the moderator commands still work)
+	if !chatRoomLockCheck(s, d) {
+		chatMetrics.Dropped(ChatDroppedRoomLocked)
+		return
+	}
+
+	// Check for commands that should not be echoed to the lobby
+	if chatCommandSilent(ctx, s, d, nil) { // We pass nil because there is no associated table
+		return
+	}
+
+	// Sending a normal message means that they are no longer away
+	chatAFKClear(ctx, s, d, nil)
+	chatAFKCheckMentions(s, d)
+
+	// Validate that the message being replied to exists in this room
+	var quote *ChatQuote
+	if d.ReplyTo != "" {
+		if exists, v, err := models.ChatLog.GetQuote(d.ReplyTo, d.Room); err != nil {
+			logger.Error("Failed to get chat message \"" + d.ReplyTo + "\": " + err.Error())
+			s.Error(DefaultErrorMsg)
+			return
+		} else if !exists {
+			chatServerSendPM(s, ChatMsgReplyNotFound, d.Room)
+			return
+		} else {
+			quote = v
+		}
+	}
+
+	// The message is stored in the database before the mentions and formatting are filled in,
+	// since they are filled in whenever a message is read from the database (in the same way as
+	// the messages at a table)
+	// (filling in a message twice would e.g. put a link inside of another link)
+	storedMsg := d.Msg
 	d.Msg = chatFillAll(d.Msg) // Convert Discord mentions from number to username, role or channel
+	messageID := newChatMessageID()
 
 	// Add the message to the database
 	if d.Discord {
-		if err := models.ChatLog.InsertDiscord(d.Username, d.Msg, d.Room); err != nil {
+		if err := models.ChatLog.InsertDiscord(messageID, d.Username, storedMsg, d.Room); err != nil {
 			logger.Error("Failed to insert a Discord chat message into the database: " +
 				err.Error())
 			s.Error(DefaultErrorMsg)
 			return
 		}
-	} else if !d.OnlyDiscord {
-		if err := models.ChatLog.Insert(userID, d.Msg, d.Room); err != nil {
+
+		// Edits and deletes on Discord are mirrored to this message (in "discord_messages.go")
+		if d.DiscordMessageID != "" {
+			discordMessages.Set(d.DiscordMessageID, messageID, d.Room, d.Username, time.Now())
+		}
+	} else if !d.OnlyDiscord && !d.NoDatabase {
+		if err := models.ChatLog.Insert(messageID, userID, storedMsg, d.Room, d.ReplyTo); err != nil {
 			logger.Error("Failed to insert a chat message into the database: " + err.Error())
 			s.Error(DefaultErrorMsg)
 			return
@@ -121,18 +252,50 @@ func chat(ctx context.Context, s *Session, d *CommandData, userID int, rawMsg st
 
 	// Lobby messages go to everyone
 	if !d.OnlyDiscord {
+		msg, action := chatParseAction(d.Msg)
+		role := chatGetRole(s, d)
+		lookalike := chatGetMessageLookalike(d.Username, d.Server, d.Discord)
+		presence := chatGetPresence(s, d)
+		recipients := make([]*Session, 0)
 		sessionList := sessions.GetList()
 		for _, s2 := range sessionList {
+			if chatIsIgnored(s2, s) {
+				continue
+			}
+			recipients = append(recipients, s2)
 			s2.Emit("chat", &ChatMessage{
-				Msg:       d.Msg,
-				Who:       d.Username,
-				Discord:   d.Discord,
-				Server:    d.Server,
-				Datetime:  time.Now(),
-				Room:      d.Room,
-				Recipient: "",
+				ID:           messageID,
+				Msg:          msg,
+				Who:          d.Username,
+				Discord:      d.Discord,
+				Server:       d.Server,
+				Datetime:     time.Now(),
+				Room:         d.Room,
+				Recipient:    "",
+				Reactions:    make(map[string]int),
+				ReplyTo:      d.ReplyTo,
+				Quote:        quote,
+				Action:       action,
+				Role:         role,
+				HighlightAll: d.HighlightAll,
+				Lookalike:    lookalike,
+				Nonce:        d.Nonce,
+				Presence:     presence,
 			})
 		}
+		chatMetrics.Sent(d.Room, msg, len(recipients))
+
+		// Links and seeds might have a preview (and images a thumbnail), which is sent separately
+		if !d.Server {
+			chatPreviewStart(messageID, d.Room, d.Msg, recipients)
+			chatSeedPreviewSend(messageID, d.Room, d.Msg, recipients)
+			chatImageSend(messageID, d.Room, d.Msg, recipients)
+		}
+	}
+
+	// Let the people who were mentioned know
+	if !d.OnlyDiscord {
+		chatNotifyMentions(s, d, nil, messageID)
 	}
 
 	// Replicate all lobby messages to Discord
@@ -140,11 +303,16 @@ func chat(ctx context.Context, s *Session, d *CommandData, userID int, rawMsg st
 	if !d.Discord {
 		// We use "rawMsg" instead of "d.Msg" because we want to send the unescaped message
 		// (since Discord can handle escaping HTML special characters itself)
-		discordSend(discordChannelSyncWithLobby, d.Username, rawMsg)
+		discordSendBridged(d.Room, d.Username, rawMsg)
 
 		// Some messages are also sent to website-development
 		if sendMessageToWebDevChannel {
-			discordSend(discordChannelWebsiteDev, d.Username, rawMsg)
+			discordUsername := d.Username
+			if action, ok := chatParseAction(rawMsg); ok {
+				discordUsername = ""
+				rawMsg = chatDiscordAction(d.Username, action)
+			}
+			discordSend(discordChannelWebsiteDev, discordUsername, rawMsg)
 			sendMessageToWebDevChannel = false
 		}
 	}
@@ -153,7 +321,7 @@ func chat(ctx context.Context, s *Session, d *CommandData, userID int, rawMsg st
 	chatCommand(ctx, s, d, nil) // We pass nil because there is no associated table
 }
 
-func commandChatTable(ctx context.Context, s *Session, d *CommandData) {
+func commandChatTable(ctx context.Context, s *Session, d *CommandData, rawMsg string) {
 	// Parse the table ID from the room
 	match := lobbyRoomRegExp.FindStringSubmatch(d.Room)
 	if match == nil {
@@ -176,6 +344,11 @@ func commandChatTable(ctx context.Context, s *Session, d *CommandData) {
 
 	t, exists := getTableAndLock(ctx, s, tableID, !d.NoTableLock, !d.NoTablesLock)
 	if !exists {
+		if d.Discord {
+			// The table for a bridged Discord channel might not exist yet
+			logger.Info("Ignoring a Discord message for table " +
+				strconv.FormatUint(tableID, 10) + ", since it does not exist.")
+		}
 		return
 	}
 	if !d.NoTableLock {
@@ -183,9 +356,10 @@ func commandChatTable(ctx context.Context, s *Session, d *CommandData) {
 	}
 
 	// Validate that this player is in the game or spectating
+	// (messages from a bridged Discord channel do not have an associated player)
 	var playerIndex int
 	var spectatorIndex int
-	if !d.Server {
+	if !d.Server && !d.Discord {
 		playerIndex = t.GetPlayerIndexFromID(s.UserID)
 		spectatorIndex = t.GetSpectatorIndexFromID(s.UserID)
 		if playerIndex == -1 && spectatorIndex == -1 {
@@ -195,37 +369,156 @@ func commandChatTable(ctx context.Context, s *Session, d *CommandData) {
 		}
 	}
 
+	// Check to see if a moderator has made this room read-only
+	// (this must be before the silent commands, in the same way as in the "chat()" function)
+	if !chatRoomLockCheck(s, d) {
+		chatMetrics.Dropped(ChatDroppedRoomLocked)
+		return
+	}
+
+	// Check to see if the table owner has muted this spectator
+	// (this must also be before the silent commands, since some of them send messages to the table,
+	// e.g. "/everyone"; this also applies to the side channel for spectators)
+	if !chatMutedSpectatorCheck(s, d, t) {
+		return
+	}
+
+	// Check to see if slow mode is enabled for this table
+	if !chatSlowModeCheck(s, d, t) {
+		chatMetrics.Dropped(ChatDroppedSlowMode)
+		return
+	}
+
+	// Check for commands that should not be echoed to the table
+	if chatCommandSilent(ctx, s, d, t) {
+		return
+	}
+
+	// Messages to the side channel for spectators are handled separately
+	// (in "chat_spectators.go")
+	if strings.HasSuffix(d.Room, SpectatorRoomSuffix) {
+		commandChatSpectators(s, d, t)
+		return
+	}
+
+	// Sending a normal message means that they are no longer away
+	chatAFKClear(ctx, s, d, t)
+	chatAFKCheckMentions(s, d)
+
+	// Validate that the message being replied to exists at this table
+	var quote *ChatQuote
+	if d.ReplyTo != "" {
+		quote = t.GetChatQuote(d.ReplyTo)
+		if quote == nil {
+			if s != nil {
+				chatServerSendPM(s, ChatMsgReplyNotFound, d.Room)
+			}
+			return
+		}
+	}
+
 	// Store the chat in memory
 	userID := 0
 	if s != nil {
 		userID = s.UserID
 	}
 	chatMsg := &TableChatMessage{
-		UserID:   userID,
-		Username: d.Username, // This was prepared above in the "commandChat()" function
-		Msg:      d.Msg,
-		Datetime: time.Now(),
-		Server:   d.Server,
+		ID:        newChatMessageID(),
+		UserID:    userID,
+		Username:  d.Username, // This was prepared above in the "commandChat()" function
+		Msg:       d.Msg,
+		Datetime:  time.Now(),
+		Server:    d.Server,
+		Discord:   d.Discord,
+		Reactions: make(map[string][]int),
+		ReplyTo:   d.ReplyTo,
+		Role:      chatGetRole(s, d),
+		Review:    chatReviewIs(d, t),
+	}
+	// Messages that are kept out of the database are not kept in memory either
+	// (e.g. the countdown before an automatic start), so they are only seen once
+	if !d.NoDatabase {
+		t.Chat = append(t.Chat, chatMsg)
+	}
+
+	// Also store the chat in the database so that it will survive a server restart
+	// (the chat in a replay is stored as review comments instead, below)
+	if !t.Replay && !d.NoDatabase {
+		var err error
+		if d.Discord {
+			err = models.ChatLog.InsertDiscord(chatMsg.ID, d.Username, d.Msg, d.Room)
+		} else {
+			err = models.ChatLog.Insert(chatMsg.ID, userID, d.Msg, d.Room, d.ReplyTo)
+		}
+		if err != nil {
+			logger.Error("Failed to insert a table chat message into the database: " + err.Error())
+			// Do not return on failed chat insertion,
+			// since the message is still stored in memory
+		}
+	} else if chatMsg.Review && !d.NoDatabase {
+		// Chat in a replay is stored against the game instead (in "chat_review.go")
+		room := t.GetReviewRoomName()
+		if err := models.ChatLog.Insert(chatMsg.ID, userID, d.Msg, room, d.ReplyTo); err != nil {
+			logger.Error("Failed to insert a review comment into the database: " + err.Error())
+			// Do not return on failed chat insertion,
+			// since the message is still stored in memory
+		}
+	}
+
+	// Edits and deletes on Discord are mirrored to this message (in "discord_messages.go")
+	if d.DiscordMessageID != "" && !d.NoDatabase {
+		discordMessages.Set(d.DiscordMessageID, chatMsg.ID, d.Room, d.Username, chatMsg.Datetime)
 	}
-	t.Chat = append(t.Chat, chatMsg)
 
 	// Send it to all of the players and spectators
-	t.NotifyChat(&ChatMessage{
-		Msg:       d.Msg,
-		Who:       d.Username,
-		Discord:   d.Discord,
-		Server:    d.Server,
-		Datetime:  chatMsg.Datetime,
-		Room:      d.Room,
-		Recipient: "",
+	// (except for the people who have ignored the sender)
+	msg, action := chatParseAction(chatMsg.GetFilledMsg())
+	t.NotifyChatFrom(s, &ChatMessage{
+		ID:           chatMsg.ID,
+		Msg:          msg,
+		Who:          d.Username,
+		Discord:      d.Discord,
+		Server:       d.Server,
+		Datetime:     chatMsg.Datetime,
+		Room:         d.Room,
+		Recipient:    "",
+		Reactions:    make(map[string]int),
+		ReplyTo:      d.ReplyTo,
+		Quote:        quote,
+		Action:       action,
+		Role:         chatMsg.Role,
+		HighlightAll: d.HighlightAll,
+		Lookalike:    chatGetMessageLookalike(d.Username, d.Server, d.Discord),
+		Nonce:        d.Nonce,
+		Review:       chatMsg.Review,
+		Presence:     chatGetPresence(s, d),
 	})
+	t.NotifyChatUnread(s)
+	recipients := t.GetChatSessions(s)
+	chatMetrics.Sent(d.Room, msg, len(recipients))
+	if !d.Server {
+		chatPreviewStart(chatMsg.ID, d.Room, d.Msg, recipients)
+		chatSeedPreviewSend(chatMsg.ID, d.Room, d.Msg, recipients)
+		chatImageSend(chatMsg.ID, d.Room, d.Msg, recipients)
+		chatTurnLinkSend(chatMsg.ID, d.Room, d.Msg, t, recipients)
+	}
+	chatNotifyMentions(s, d, t, chatMsg.ID)
+
+	// Replicate the message to Discord if this table is bridged to a Discord channel
+	// (but don't send Discord messages that we are already replicating)
+	if !d.Discord {
+		discordSendBridged(d.Room, d.Username, rawMsg)
+	}
 
 	// Check for commands
-	chatCommand(ctx, s, d, t)
+	// (table commands act on behalf of a player, so they cannot be used from Discord)
+	if !d.Discord {
+		chatCommand(ctx, s, d, t)
+	}
 
 	// If this user was typing, set them so that they are not typing
 	// Check for spectators first in case this is a shared replay that the player happened to be in
-	if d.Server {
+	if d.Server || d.Discord {
 		return
 	}
 	if spectatorIndex != -1 {
@@ -244,16 +537,23 @@ func commandChatTable(ctx context.Context, s *Session, d *CommandData) {
 func sanitizeChatInput(s *Session, msg string, server bool) (string, bool) {
 	// Truncate long messages
 	// (we do this first to prevent wasting CPU cycles on validating extremely long messages)
-	maxLength := MaxChatLength
+	maxLength := maxChatLength
 	if server {
 		maxLength = MaxChatLengthServer
 	}
-	if len(msg) > maxLength {
-		msg = msg[0 : maxLength-1]
+	if maxLength > 0 {
+		msg = truncateRunes(msg, maxLength)
 	}
 
-	// Remove any non-printable characters, if any
-	msg = removeNonPrintableCharacters(msg)
+	// Remove any non-printable and invisible characters, if any (in "chat_invisible.go")
+	// (newlines are not printable, so we handle each line separately to preserve them)
+	msg = strings.ReplaceAll(msg, "\r\n", "\n")
+	msg = strings.ReplaceAll(msg, "\r", "\n")
+	lines := strings.Split(msg, "\n")
+	for i, line := range lines {
+		lines[i] = removeInvisibleCharacters(line)
+	}
+	msg = strings.Join(lines, "\n")
 
 	// Check for valid UTF8
 	if !utf8.Valid([]byte(msg)) {
@@ -263,14 +563,17 @@ func sanitizeChatInput(s *Session, msg string, server bool) (string, bool) {
 		return msg, false
 	}
 
-	// Replace any whitespace that is not a space with a space
+	// Replace any whitespace that is not a space or a newline with a space
 	msg2 := msg
 	for _, letter := range msg2 {
-		if unicode.IsSpace(letter) && letter != ' ' {
+		if unicode.IsSpace(letter) && letter != ' ' && letter != '\n' {
 			msg = strings.ReplaceAll(msg, string(letter), " ")
 		}
 	}
 
+	// Get rid of excessive blank lines
+	msg = collapseChatNewlines(msg)
+
 	// Trim whitespace from both sides
 	msg = strings.TrimSpace(msg)
 
@@ -294,3 +597,42 @@ func sanitizeChatInput(s *Session, msg string, server bool) (string, bool) {
 
 	return msg, true
 }
+
+// collapseChatNewlines trims the whitespace at the end of every line,
+// reduces runs of blank lines to a single blank line, and limits the total number of lines
+// (single and double newlines are preserved so that people can still format their messages)
+func collapseChatNewlines(msg string) string {
+	if !strings.Contains(msg, "\n") {
+		return msg
+	}
+
+	lines := make([]string, 0)
+	previousLineBlank := true // Leading blank lines are removed
+	for _, line := range strings.Split(msg, "\n") {
+		line = strings.TrimRight(line, " ")
+		lineBlank := line == ""
+		if lineBlank && previousLineBlank {
+			continue
+		}
+		lines = append(lines, line)
+		previousLineBlank = lineBlank
+	}
+
+	// Remove the trailing blank line, if any
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	// Join all of the lines past the limit into the final line
+	if len(lines) > ChatMaxLines {
+		extraLines := make([]string, 0)
+		for _, line := range lines[ChatMaxLines-1:] {
+			if line != "" {
+				extraLines = append(extraLines, strings.TrimSpace(line))
+			}
+		}
+		lines = append(lines[:ChatMaxLines-1], strings.Join(extraLines, " "))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"html"
+	"strconv"
 	"time"
+	"unicode/utf8"
 
 	"github.com/Hanabi-Live/hanabi-live/logger"
 )
@@ -22,6 +24,11 @@ func commandChatPM(ctx context.Context, s *Session, d *CommandData) {
 		return
 	}
 
+	// Check to see if they were temporarily muted for spamming (in "chat_auto_mute.go")
+	if !chatAutoMuteCheck(s, d.Room) {
+		return
+	}
+
 	// Sanitize and validate the chat message
 	if v, valid := sanitizeChatInput(s, d.Msg, false); !valid {
 		return
@@ -36,6 +43,11 @@ func commandChatPM(ctx context.Context, s *Session, d *CommandData) {
 		d.Recipient = v
 	}
 
+	// Check to see if the message contains a banned word or pattern (in "chat_banned_words.go")
+	if !chatBannedWordsCheck(s, d, d.Msg) {
+		return
+	}
+
 	// Validate that they are not sending a private message to themselves
 	normalizedUsername := normalizeString(d.Recipient)
 	if normalizedUsername == normalizeString(s.Username) {
@@ -43,6 +55,9 @@ func commandChatPM(ctx context.Context, s *Session, d *CommandData) {
 		return
 	}
 
+	// Escape all HTML special characters (to stop various attacks against other players)
+	d.Msg = html.EscapeString(d.Msg)
+
 	// Validate that the recipient is online
 	sessionList := sessions.GetList()
 	var recipientSession *Session
@@ -53,28 +68,44 @@ func commandChatPM(ctx context.Context, s *Session, d *CommandData) {
 		}
 	}
 	if recipientSession == nil {
-		s.Warning("User \"" + d.Recipient + "\" is not currently online.")
+		if !chatPMQueueEnabled() {
+			s.Warning("User \"" + d.Recipient + "\" is not currently online.")
+			return
+		}
+
+		// Messages to offline users are delivered when they next log in (in "chat_pm_queue.go")
+		if exists, recipient, err := models.Users.GetUserFromNormalizedUsername(
+			normalizedUsername,
+		); err != nil {
+			logger.Error("Failed to validate that \"" + normalizedUsername + "\" " +
+				"exists in the database: " + err.Error())
+			s.Error(DefaultErrorMsg)
+		} else if !exists {
+			chatServerSendPM(s, ChatMsgUserNotFound, d.Room, d.Recipient)
+		} else {
+			chatPMQueue(s, d, recipient)
+		}
 		return
 	}
 
-	// Escape all HTML special characters (to stop various attacks against other players)
-	d.Msg = html.EscapeString(d.Msg)
-
 	chatPM(s, d, recipientSession)
 }
 
 func chatPM(s *Session, d *CommandData, recipientSession *Session) {
 	// Log the message
-	logger.Info("PM <" + s.Username + "> --> <" + recipientSession.Username + "> " + d.Msg)
+	// (only the metadata is logged, since private messages are private)
+	logger.Info("PM <" + s.Username + "> --> <" + recipientSession.Username + "> " +
+		"(" + strconv.Itoa(utf8.RuneCountInString(d.Msg)) + " characters)")
 
 	// Add the message to the database
-	if err := models.ChatLogPM.Insert(s.UserID, d.Msg, recipientSession.UserID); err != nil {
+	if err := models.ChatLogPM.Insert(s.UserID, d.Msg, recipientSession.UserID, true); err != nil {
 		logger.Error("Failed to insert a private message into the database: " + err.Error())
 		s.Error(DefaultErrorMsg)
 		return
 	}
 
 	chatMessage := &ChatMessage{
+		ID:        newChatMessageID(),
 		Msg:       d.Msg,
 		Who:       s.Username,
 		Discord:   false,
@@ -82,11 +113,25 @@ func chatPM(s *Session, d *CommandData, recipientSession *Session) {
 		Datetime:  time.Now(),
 		Room:      "",
 		Recipient: recipientSession.Username,
+		Reactions: make(map[string]int),
+		ReplyTo:   "",
+		Quote:     nil,
+		Action:    false,
+		Lookalike: chatGetLookalike(s.Username, true),
 	}
 
 	// Echo the private message back to the person who sent it
 	s.Emit("chat", chatMessage)
 
 	// Send the private message to the recipient
-	recipientSession.Emit("chat", chatMessage)
+	// (unless they have ignored the sender)
+	if !chatIsIgnored(recipientSession, s) {
+		recipientSession.Emit("chat", chatMessage)
+		pmReceipts.Add(chatMessage.ID, s.UserID, recipientSession.UserID)
+
+		// From the "soundPM" setting of the recipient (in "chat_notifications.go")
+		if recipientSession.ChatNotifications().SoundPM {
+			recipientSession.NotifySoundLobby("tone")
+		}
+	}
 }
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+)
+
+// ChatReactionsMessage is sent to clients when the reactions to a message have changed
+type ChatReactionsMessage struct {
+	ID        string         `json:"id"`
+	Room      string         `json:"room"`
+	Reactions map[string]int `json:"reactions"`
+}
+
+// commandChatReact is sent when the user reacts to a chat message with an emoji
+// Reacting to the same message with the same emoji a second time will remove the reaction
+//
+// Example data:
+// {
+//   room: 'lobby', // Room can also be "table1", "table1234", etc.
+//   messageID: '9b2e5a1c-3f1d-4e8a-a3c7-6f0d2b8e4c13',
+//   emoji: 'joy',
+// }
+func commandChatReact(ctx context.Context, s *Session, d *CommandData) {
+	// Check to see if their IP has been muted
+	if s.Muted {
+		s.Warning("You have been muted by an administrator.")
+		return
+	}
+
+	// Validate the emoji
+	if _, ok := emojis[d.Emoji]; !ok {
+		s.Warning("\"" + d.Emoji + "\" is not a valid emoji.")
+		return
+	}
+
+	// Validate the message ID
+	if d.MessageID == "" {
+		s.Warning("You must specify the ID of the message that you are reacting to.")
+		return
+	}
+
+	if d.Room == "lobby" {
+		chatReactLobby(s, d)
+	} else if strings.HasPrefix(d.Room, "table") {
+		chatReactTable(ctx, s, d)
+	} else {
+		s.Warning("That is not a valid room.")
+	}
+}
+
+func chatReactLobby(s *Session, d *CommandData) {
+	// Validate that the message exists
+	if exists, err := models.ChatLog.Exists(d.MessageID, d.Room); err != nil {
+		logger.Error("Failed to check to see if chat message \"" + d.MessageID + "\" exists: " +
+			err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else if !exists {
+		s.Warning("There is no message with an ID of \"" + d.MessageID + "\".")
+		return
+	}
+
+	// Toggle the reaction
+	var alreadyReacted bool
+	if v, err := models.ChatLogReactions.Exists(d.MessageID, s.UserID, d.Emoji); err != nil {
+		logger.Error("Failed to check to see if user \"" + s.Username + "\" reacted to chat " +
+			"message \"" + d.MessageID + "\": " + err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else {
+		alreadyReacted = v
+	}
+	if alreadyReacted {
+		if err := models.ChatLogReactions.Delete(d.MessageID, s.UserID, d.Emoji); err != nil {
+			logger.Error("Failed to delete the reaction from user \"" + s.Username + "\" to " +
+				"chat message \"" + d.MessageID + "\": " + err.Error())
+			s.Error(DefaultErrorMsg)
+			return
+		}
+	} else {
+		if err := models.ChatLogReactions.Insert(d.MessageID, s.UserID, d.Emoji); err != nil {
+			logger.Error("Failed to insert the reaction from user \"" + s.Username + "\" to " +
+				"chat message \"" + d.MessageID + "\": " + err.Error())
+			s.Error(DefaultErrorMsg)
+			return
+		}
+	}
+
+	// Get the new reaction counts
+	var reactions map[string]int
+	if v, err := models.ChatLogReactions.GetCounts([]string{d.MessageID}); err != nil {
+		logger.Error("Failed to get the reactions for chat message \"" + d.MessageID + "\": " +
+			err.Error())
+		s.Error(DefaultErrorMsg)
+		return
+	} else if v2, ok := v[d.MessageID]; ok {
+		reactions = v2
+	} else {
+		reactions = make(map[string]int)
+	}
+
+	// Lobby messages go to everyone
+	chatReactionsMessage := &ChatReactionsMessage{
+		ID:        d.MessageID,
+		Room:      d.Room,
+		Reactions: reactions,
+	}
+	for _, s2 := range sessions.GetList() {
+		s2.Emit("chatReactions", chatReactionsMessage)
+	}
+}
+
+func chatReactTable(ctx context.Context, s *Session, d *CommandData) {
+	// Parse the table ID from the room
+	match := lobbyRoomRegExp.FindStringSubmatch(d.Room)
+	if match == nil {
+		s.Warning("That is not a valid room.")
+		return
+	}
+	var tableID uint64
+	if v, err := strconv.ParseUint(match[1], 10, 64); err != nil {
+		s.Warning("That is not a valid room.")
+		return
+	} else {
+		tableID = v
+	}
+
+	t, exists := getTableAndLock(ctx, s, tableID, !d.NoTableLock, !d.NoTablesLock)
+	if !exists {
+		return
+	}
+	if !d.NoTableLock {
+		defer t.Unlock(ctx)
+	}
+
+	// Validate that they are in the game or are a spectator
+	playerIndex := t.GetPlayerIndexFromID(s.UserID)
+	spectatorIndex := t.GetSpectatorIndexFromID(s.UserID)
+	if playerIndex == -1 && spectatorIndex == -1 {
+		s.Warning("You are not playing or spectating at table " + strconv.FormatUint(t.ID, 10) +
+			", so you cannot react to its chat messages.")
+		return
+	}
+
+	// Find the message
+	var chatMsg *TableChatMessage
+	for _, tableChatMsg := range t.Chat {
+		if tableChatMsg.ID == d.MessageID {
+			chatMsg = tableChatMsg
+			break
+		}
+	}
+	if chatMsg == nil {
+		s.Warning("There is no message with an ID of \"" + d.MessageID + "\".")
+		return
+	}
+
+	chatMsg.ToggleReaction(s.UserID, d.Emoji)
+
+	t.NotifyChatReactions(&ChatReactionsMessage{
+		ID:        chatMsg.ID,
+		Room:      d.Room,
+		Reactions: chatMsg.GetReactionCounts(),
+	})
+}
+
+// ToggleReaction adds the reaction if the user has not reacted with that emoji yet,
+// or removes it if they have
+func (chatMsg *TableChatMessage) ToggleReaction(userID int, emoji string) {
+	// Tables that were restored from a previous version of the server will not have this map
+	if chatMsg.Reactions == nil {
+		chatMsg.Reactions = make(map[string][]int)
+	}
+
+	userIDs := chatMsg.Reactions[emoji]
+	for i, reactedUserID := range userIDs {
+		if reactedUserID == userID {
+			userIDs = append(userIDs[:i], userIDs[i+1:]...)
+			if len(userIDs) == 0 {
+				delete(chatMsg.Reactions, emoji)
+			} else {
+				chatMsg.Reactions[emoji] = userIDs
+			}
+			return
+		}
+	}
+
+	chatMsg.Reactions[emoji] = append(userIDs, userID)
+}
+
+func (chatMsg *TableChatMessage) GetReactionCounts() map[string]int {
+	reactions := make(map[string]int)
+	for emoji, userIDs := range chatMsg.Reactions {
+		reactions[emoji] = len(userIDs)
+	}
+	return reactions
+}
@@ -2,16 +2,29 @@ package main
 
 import (
 	"context"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
 )
 
 // commandChatRead is sent when the user opens the in-game chat or
 // when they receive a chat message when the in-game chat is already open
+// It is also sent when they receive a private message (with the ID of the message instead)
 //
 // Example data:
 // {
 //   tableID: 5,
 // }
+// or:
+// {
+//   messageID: 'b7c9a1e4f2d3',
+// }
 func commandChatRead(ctx context.Context, s *Session, d *CommandData) {
+	if d.MessageID != "" {
+		chatReadPM(s, d)
+		return
+	}
+
 	t, exists := getTableAndLock(ctx, s, d.TableID, !d.NoTableLock, !d.NoTablesLock)
 	if !exists {
 		return
@@ -37,3 +50,32 @@ func commandChatRead(ctx context.Context, s *Session, d *CommandData) {
 	// Mark that they have read all of the in-game chat
 	t.ChatRead[s.UserID] = len(t.Chat)
 }
+
+// chatReadPM relays a read receipt to the sender of a private message
+// (in "chat_pm_receipts.go")
+func chatReadPM(s *Session, d *CommandData) {
+	// Return without an error message if the message is not a private message to this user
+	// (e.g. because it was already acknowledged)
+	senderID, ok := pmReceipts.Read(d.MessageID, s.UserID)
+	if !ok {
+		return
+	}
+
+	// Users can opt out of sending read receipts from the "Settings" tooltip in the lobby
+	if disabled, err := models.UserSettings.IsReadReceiptsDisabled(s.UserID); err != nil {
+		logger.Error("Failed to get the \"disable_read_receipts\" setting for user " +
+			"\"" + s.Username + "\": " + err.Error())
+		return
+	} else if disabled {
+		return
+	}
+
+	// The sender may have logged off in the meantime
+	if s2, ok := sessions.Get(senderID); ok {
+		s2.Emit("chatReceipt", &ChatReceiptMessage{
+			ID:        d.MessageID,
+			Recipient: s.Username,
+			Datetime:  time.Now(),
+		})
+	}
+}
@@ -62,23 +62,36 @@ func chatTyping(ctx context.Context, s *Session, t *Table, playerIndex int, spec
 		}
 	}
 
-	if name != "" {
-		// They were not already typing, so send a message to everyone else
-		t.NotifyChatTyping(name, true)
+	if name == "" {
+		// They were already typing, so there is already a goroutine that will check to see if they
+		// have stopped (this means that rapid keystrokes do not flood everyone else at the table)
+		return
 	}
 
+	// They were not already typing, so send a message to everyone else
+	t.NotifyChatTyping(name, true)
+
 	// X seconds from now, check to see if they have stopped typing
 	go chatTypingCheckStopped(ctx, t, s.UserID)
 }
 
 // chatTypingCheckStopped is meant to be run in a new goroutine
+// It will keep checking until the user has stopped typing
 func chatTypingCheckStopped(ctx context.Context, t *Table, userID int) {
-	time.Sleep(TypingDelay)
+	delay := TypingDelay
+	for delay > 0 {
+		time.Sleep(delay)
+		delay = chatTypingCheckStoppedOnce(ctx, t, userID)
+	}
+}
 
+// chatTypingCheckStoppedOnce returns how long to wait before checking again,
+// or 0 if there is no need to check again
+func chatTypingCheckStoppedOnce(ctx context.Context, t *Table, userID int) time.Duration {
 	// Check to see if the table still exists
 	t2, exists := getTableAndLock(ctx, nil, t.ID, false, true)
 	if !exists || t != t2 {
-		return
+		return 0
 	}
 	t.Lock(ctx)
 	defer t.Unlock(ctx)
@@ -90,37 +103,41 @@ func chatTypingCheckStopped(ctx context.Context, t *Table, userID int) {
 		// They left the game shortly after they started typing
 		// The "typing" message is automatically removed when a player leaves a table,
 		// so we don't have to do anything
-		return
+		return 0
 	}
 	if spectatorIndex == -1 && t.Replay {
 		// Same as above
-		return
+		return 0
 	}
 
 	// Check for spectators first in case this is a shared replay that the player happened to be in
+	var typing *bool
+	var lastTyped time.Time
 	name := ""
 	if spectatorIndex != -1 {
 		sp := t.Spectators[spectatorIndex]
-		if !sp.Typing {
-			return
-		}
-		if time.Since(sp.LastTyped) >= TypingDelay {
-			sp.Typing = false
-			name = sp.Name
-		}
+		typing = &sp.Typing
+		lastTyped = sp.LastTyped
+		name = sp.Name
 	} else if playerIndex != -1 {
 		p := t.Players[playerIndex]
-		if !p.Typing {
-			return
-		}
-		if time.Since(p.LastTyped) >= TypingDelay {
-			p.Typing = false
-			name = p.Name
-		}
+		typing = &p.Typing
+		lastTyped = p.LastTyped
+		name = p.Name
+	}
+
+	if !*typing {
+		// They sent a chat message, which already cleared the typing indicator
+		return 0
 	}
 
-	if name != "" {
-		// They have not typed anything for X seconds, so assume that they are finished typing
-		t.NotifyChatTyping(name, false)
+	if timeSinceLastTyped := time.Since(lastTyped); timeSinceLastTyped < TypingDelay {
+		// They are still typing
+		return TypingDelay - timeSinceLastTyped
 	}
+
+	// They have not typed anything for X seconds, so assume that they are finished typing
+	*typing = false
+	t.NotifyChatTyping(name, false)
+	return 0
 }
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"strconv"
+)
+
+// commandChatVote is sent when the user votes in the poll of a replay (in "chat_poll.go")
+// Voting a second time replaces the previous vote
+//
+// Example data:
+// {
+//   tableID: 15103,
+//   pollID: '9b2e5a1c-3f1d-4e8a-a3c7-6f0d2b8e4c13',
+//   option: 0, // The index of the option
+// }
+func commandChatVote(ctx context.Context, s *Session, d *CommandData) {
+	t, exists := getTableAndLock(ctx, s, d.TableID, !d.NoTableLock, !d.NoTablesLock)
+	if !exists {
+		return
+	}
+	if !d.NoTableLock {
+		defer t.Unlock(ctx)
+	}
+
+	// Validate that they are spectating the replay
+	if !t.Replay || t.GetSpectatorIndexFromID(s.UserID) == -1 {
+		s.Warning("You are not spectating replay " + strconv.FormatUint(t.ID, 10) + ", " +
+			"so you cannot vote in its poll.")
+		return
+	}
+
+	// Validate that the poll is still in progress
+	// (the poll ID prevents a late vote from counting towards the next poll)
+	if t.Poll == nil || t.Poll.ID != d.PollID {
+		s.Warning("That poll has already closed.")
+		return
+	}
+
+	// Validate the option
+	if d.Option < 0 || d.Option >= len(t.Poll.Options) {
+		s.Warning("That is not a valid option.")
+		return
+	}
+
+	t.Poll.Vote(s.UserID, s.Username, d.Option)
+	t.NotifyChatPoll(t.Poll.ToMessage(t.GetRoomName(), false))
+}
@@ -9,6 +9,10 @@ import (
 	"unicode/utf8"
 )
 
+const (
+	MaxNoteLength = 300
+)
+
 // commandNote is sent when the user writes a note
 //
 // Example data:
@@ -49,8 +53,8 @@ func commandNote(ctx context.Context, s *Session, d *CommandData) {
 
 	// Truncate long notes
 	// (we do this first to prevent wasting CPU cycles on validating extremely long notes)
-	if len(d.Note) > MaxChatLength {
-		d.Note = d.Note[0 : MaxChatLength-1]
+	if len(d.Note) > MaxNoteLength {
+		d.Note = d.Note[0 : MaxNoteLength-1]
 	}
 
 	// Remove any non-printable characters, if any
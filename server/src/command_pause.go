@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"html"
 	"strconv"
 	"time"
 )
@@ -103,6 +104,7 @@ func pause(ctx context.Context, s *Session, d *CommandData, t *Table, playerInde
 		g.Paused = true
 		g.PausePlayerIndex = playerIndex
 		g.PauseCount++
+		g.DatetimePaused = time.Now()
 
 		// Decrement the time that the player has taken so far prior to this pause
 		p.Time -= time.Since(g.DatetimeTurnBegin)
@@ -124,12 +126,22 @@ func pause(ctx context.Context, s *Session, d *CommandData, t *Table, playerInde
 	t.NotifyPause()
 
 	// Also send a chat message about it
+	// (and mark the message about the pause as resolved, if any)
 	msg := s.Username + " "
 	if !g.Paused {
 		msg += "un"
+		chatPauseResolve(t)
 	}
 	msg += "paused the game."
+	if g.Paused && d.PauseReason != "" {
+		// Server messages are not escaped in the "commandChat()" function,
+		// so the reason from the user must be escaped here
+		msg += " Reason: " + html.EscapeString(d.PauseReason)
+	}
 	chatServerSend(ctx, msg, t.GetRoomName(), d.NoTablesLock)
+	if g.Paused {
+		chatPauseRecord(t)
+	}
 
 	// If a user has read all of the chat thus far,
 	// mark that they have also read the "pause" message, since it is superfluous
@@ -70,16 +70,34 @@ func commandSetting(ctx context.Context, s *Session, d *CommandData) {
 			s.Warning("The setting of \"" + d.Name + "\" is too large.")
 			return
 		}
+	} else if d.Name == "chatHighlightWords" {
+		if v, valid := chatValidateHighlightWords(d.Setting); !valid {
+			s.Warning("The highlight words must be separated by commas, there can be at most " +
+				strconv.Itoa(ChatHighlightWordsMax) + " of them, and each of them can only " +
+				"contain letters, numbers, underscores, and hyphens (up to " +
+				strconv.Itoa(ChatHighlightWordMaxLength) + " characters).")
+			return
+		} else {
+			d.Setting = v
+		}
+	} else if d.Name == "timezone" {
+		if v, valid := chatParseTimezone(d.Setting); !valid {
+			s.Warning("The timezone of \"" + d.Setting + "\" is not in the tz database.")
+			return
+		} else {
+			d.Setting = chatTimezoneSetting(v)
+		}
 	}
 
 	setting(s, d)
 }
 
-func setting(s *Session, d *CommandData) {
+// setting returns false if the setting could not be stored in the database
+func setting(s *Session, d *CommandData) bool {
 	if err := models.UserSettings.Set(s.UserID, toSnakeCase(d.Name), d.Setting); err != nil {
 		logger.Error("Failed to set a setting for user \"" + s.Username + "\": " + err.Error())
 		s.Error(DefaultErrorMsg)
-		return
+		return false
 	}
 
 	// We also store whether or not they are a H-Group member on the session itself
@@ -90,4 +108,26 @@ func setting(s *Session, d *CommandData) {
 			s.SetHyphenated(false)
 		}
 	}
+
+	// We also store the timezone on the session itself (in "chat_timezone.go")
+	if d.Name == "timezone" {
+		s.SetTimezone(chatGetTimezone(d.Setting))
+	}
+
+	// We also store the chat notification settings on the session itself
+	// (in "chat_notifications.go")
+	chatNotifications := s.ChatNotifications()
+	switch d.Name {
+	case "soundMention":
+		chatNotifications.SoundMention = d.Setting == "1"
+	case "soundPM":
+		chatNotifications.SoundPM = d.Setting == "1"
+	case "chatHighlightWords":
+		chatNotifications.HighlightWords = chatParseHighlightWords(d.Setting)
+	default:
+		return true
+	}
+	s.SetChatNotifications(chatNotifications)
+
+	return true
 }
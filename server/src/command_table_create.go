@@ -141,6 +141,7 @@ func tableCreate(ctx context.Context, s *Session, d *CommandData, data *SpecialG
 	t.PasswordHash = passwordHash
 	t.MaxPlayers = d.MaxPlayers
 	t.Options = d.Options
+	t.ChatSpectatorNotices = chatSpectatorNoticesDefault(t.Options)
 	t.ExtraOptions = &ExtraOptions{
 		DatabaseID:                 data.DatabaseID,
 		NoWriteToDatabase:          false,
@@ -51,7 +51,9 @@ func commandTableJoin(ctx context.Context, s *Session, d *CommandData) {
 	}
 
 	// Validate that they entered the correct password
-	if t.PasswordHash != "" {
+	// (users who were invited with the "/invite" command do not need it)
+	_, invited := t.Invited[s.UserID]
+	if t.PasswordHash != "" && !invited {
 		if match, err := argon2id.ComparePasswordAndHash(d.Password, t.PasswordHash); err != nil {
 			logger.Error("Failed to compare the submitted password to the Argon2 hash: " +
 				err.Error())
@@ -135,6 +137,7 @@ func tableJoin(ctx context.Context, s *Session, d *CommandData, t *Table) {
 
 	t.Players = append(t.Players, p)
 	tables.AddPlaying(s.UserID, t.ID) // Keep track of user to table relationships
+	delete(t.Invited, s.UserID)
 
 	notifyAllTable(t)
 	t.NotifyPlayerChange()
@@ -159,6 +159,8 @@ func tableRestart(
 	for k, v := range t.ChatRead {
 		oldChatRead[k] = v
 	}
+	oldSlowMode := t.SlowMode
+	oldChatGameEvents := t.ChatGameEvents
 
 	// Force everyone to go back to the lobby
 	t.NotifyBoot()
@@ -268,6 +270,8 @@ func tableRestart(
 	for k, v := range oldChatRead {
 		t2.ChatRead[k] = v
 	}
+	t2.SlowMode = oldSlowMode
+	t2.ChatGameEvents = oldChatGameEvents
 
 	t2.ExtraOptions.Restarted = true
 
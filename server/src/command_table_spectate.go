@@ -107,6 +107,7 @@ func tableSpectate(ctx context.Context, s *Session, d *CommandData, t *Table) {
 
 	t.Spectators = append(t.Spectators, sp)
 	tables.AddSpectating(s.UserID, t.ID) // Keep track of user to table relationships
+	chatSpectatorNoticeAdd(t, s.UserID, s.Username, true)
 
 	notifyAllTable(t)    // Update the spectator list for the row in the lobby
 	t.NotifySpectators() // Update the in-game spectator list
@@ -94,6 +94,8 @@ func tableUnattendSpectator(ctx context.Context, s *Session, d *CommandData, t *
 
 	t.Spectators = append(t.Spectators[:j], t.Spectators[j+1:]...)
 	tables.DeleteSpectating(s.UserID, t.ID) // Keep track of user to table relationships
+	delete(t.MutedSpectators, s.UserID)
+	chatSpectatorNoticeAdd(t, s.UserID, s.Username, false)
 
 	if t.Replay && len(t.Spectators) == 0 {
 		// This was the last person to leave the replay, so delete it
@@ -138,6 +138,7 @@ func commandTableUpdate(ctx context.Context, s *Session, d *CommandData) {
 		for _, p := range t.Players {
 			if p.UserID == t.OwnerID {
 				p.Session.Emit("chat", &ChatMessage{
+					ID:        newChatMessageID(),
 					Msg:       message,
 					Who:       WebsiteName,
 					Discord:   false,
@@ -145,6 +146,10 @@ func commandTableUpdate(ctx context.Context, s *Session, d *CommandData) {
 					Datetime:  time.Now(),
 					Room:      room,
 					Recipient: p.Name,
+					Reactions: make(map[string]int),
+					ReplyTo:   "",
+					Quote:     nil,
+					Action:    false,
 				})
 				break
 			}
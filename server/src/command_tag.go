@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
@@ -36,6 +37,10 @@ func commandTag(ctx context.Context, s *Session, d *CommandData) {
 		return
 	}
 
+	if !tagValidateParticipant(s, t) {
+		return
+	}
+
 	// Sanitize, validate, and normalize the tag
 	if v, err := sanitizeTag(d.Msg); err != nil {
 		s.Warning(err.Error())
@@ -94,6 +99,45 @@ func tag(ctx context.Context, s *Session, d *CommandData, t *Table) {
 	chatServerSend(ctx, msg, t.GetRoomName(), d.NoTablesLock)
 }
 
+// tagValidateParticipant returns false if the user is not playing or spectating at the table
+// (in a replay, they must be spectating it)
+func tagValidateParticipant(s *Session, t *Table) bool {
+	playerIndex := t.GetPlayerIndexFromID(s.UserID)
+	spectatorIndex := t.GetSpectatorIndexFromID(s.UserID)
+	if playerIndex == -1 && spectatorIndex == -1 {
+		s.Warning("You are not playing or spectating at table " + strconv.FormatUint(t.ID, 10) +
+			", so you cannot change the tags of the game.")
+		return false
+	}
+	if spectatorIndex == -1 && t.Replay {
+		s.Warning("You are not spectating replay " + strconv.FormatUint(t.ID, 10) +
+			", so you cannot change the tags of the game.")
+		return false
+	}
+
+	return true
+}
+
+// tagsReveal lets everyone know about the tags that were added while the game was in progress
+// (which were only acknowledged to the person who added them, to avoid spoiling information)
+// It is called from the "ConvertToSharedReplay()" function
+// It is assumed that the tables mutex and the table mutex are locked when calling this function
+func tagsReveal(ctx context.Context, t *Table) {
+	g := t.Game
+	if len(g.Tags) == 0 {
+		return
+	}
+
+	tags := make([]string, 0, len(g.Tags))
+	for tag := range g.Tags {
+		tags = append(tags, "\""+tag+"\"")
+	}
+	sort.Strings(tags)
+
+	msg := "The following tags were added during the game: " + strings.Join(tags, ", ")
+	chatServerSend(ctx, msg, t.GetRoomName(), true)
+}
+
 func sanitizeTag(tag string) (string, error) {
 	// Validate tag length
 	if len(tag) > MaxTagLength {
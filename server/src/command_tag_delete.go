@@ -28,6 +28,10 @@ func commandTagDelete(ctx context.Context, s *Session, d *CommandData) {
 		return
 	}
 
+	if !tagValidateParticipant(s, t) {
+		return
+	}
+
 	// Sanitize, validate, and normalize the tag
 	if v, err := sanitizeTag(d.Msg); err != nil {
 		s.Warning(err.Error())
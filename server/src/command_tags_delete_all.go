@@ -28,6 +28,10 @@ func commandTagsDeleteAll(ctx context.Context, s *Session, d *CommandData) {
 		return
 	}
 
+	if !tagValidateParticipant(s, t) {
+		return
+	}
+
 	tagsDeleteAll(ctx, s, d, t)
 }
 
@@ -55,6 +55,9 @@ func discordInit() {
 	// Messages are only sent to website-development channel when the server restarts
 	sendMessageToWebDevChannel = false
 
+	// Map the Discord channels to rooms (in "discord_bridges.go")
+	discordBridgesInit()
+
 	// Initialize the command map
 	discordCommandInit()
 
@@ -76,6 +79,9 @@ func discordConnect() {
 	// Register function handlers for various events
 	discord.AddHandler(discordReady)
 	discord.AddHandler(discordMessageCreate)
+	discord.AddHandler(discordMessageUpdate)
+	discord.AddHandler(discordMessageDelete)
+	discord.AddHandler(discordMessageDeleteBulk)
 
 	// Open the websocket and begin listening
 	if err := discord.Open(); err != nil {
@@ -83,6 +89,9 @@ func discordConnect() {
 		return
 	}
 
+	// Check that all of the bridged channels exist
+	discordBridgesValidate()
+
 	// Announce that the server has started
 	// (we wait for Discord to connect before displaying this message)
 	msg := "The server has successfully started at: " + getCurrentTimestamp() + " " +
@@ -102,7 +111,7 @@ func discordReady(s *discordgo.Session, event *discordgo.Ready) {
 	discordIsReady.Set()
 }
 
-// Copy messages from Discord to the lobby
+// Copy messages from Discord to the room that the channel is bridged to
 func discordMessageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
 	// Don't do anything if we are not yet connected
 	if discordIsReady.IsNotSet() {
@@ -130,23 +139,47 @@ func discordMessageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
 		return
 	}
 
-	// Handle specific Discord commands in channels other than the lobby
+	// Handle specific Discord commands in channels that are not bridged to a room
 	// (to replicate some lobby functionality to the Discord server more generally)
-	if m.ChannelID != discordChannelSyncWithLobby {
+	room, ok := discordGetBridgedRoom(m.ChannelID)
+	if !ok {
 		discordCheckCommand(ctx, m)
 		return
 	}
 
+	// Bridging might be turned off for this room with the "/bridge" command
+	if !discordBridgesOffInbound && discordBridgeIsOff(room) {
+		return
+	}
+
+	// Drop the messages from a Discord bot or integration that is stuck in a loop
+	if discordDuplicates.Check(m.Author.ID, m.ChannelID, m.Content) {
+		logger.Debug("Dropped a duplicate Discord message from \"" + m.Author.Username + "#" +
+			m.Author.Discriminator + "\" in channel \"" + channel.Name + "\".")
+		chatMetrics.Dropped(ChatDroppedDiscordDuplicate)
+		return
+	}
+
+	// Use their nickname for the server, if any
+	// (this is also stored so that mentions of them can be filled in without waiting for a lookup)
+	username, ok := discordGetNickname(m.Author.ID)
+	discordNicknames.Set(m.Author.ID, username, ok)
+	if !ok {
+		username = m.Author.Username
+	}
+
 	// Send everyone the notification
 	commandChat(ctx, nil, &CommandData{ // nolint: exhaustivestruct
-		Username: discordGetNickname(m.Author.ID),
+		Username: username,
 		Msg:      m.Content,
 		Discord:  true,
-		Room:     "lobby",
+		Room:     room,
 		// Pass through the ID in case we need it for a custom command
 		DiscordID: m.Author.ID,
 		// Pass through the discriminator so we can append it to the username
 		DiscordDiscriminator: m.Author.Discriminator,
+		// Pass through the message ID so that later edits and deletes can be mirrored
+		DiscordMessageID: m.ID,
 	})
 }
 
@@ -193,27 +226,30 @@ func discordSend(to string, username string, msg string) {
 	}
 }
 
-func discordGetNickname(discordID string) string {
+// discordGetNickname returns false if the user could not be found
+// (e.g. if they left the server or deleted their account)
+func discordGetNickname(discordID string) (string, bool) {
 	if member, err := discord.GuildMember(discordGuildID, discordID); err != nil {
 		// This can occasionally fail, so we don't want to report the error to Sentry
 		logger.Info("Failed to get the Discord guild member: " + err.Error())
-		return "[error]"
+		return "", false
 	} else {
 		if member.Nick != "" {
-			return member.Nick
+			return member.Nick, true
 		}
 
-		return member.User.Username
+		return member.User.Username, true
 	}
 }
 
-func discordGetChannel(discordID string) string {
+// discordGetChannel returns false if the channel could not be found
+func discordGetChannel(discordID string) (string, bool) {
 	if channel, err := discord.Channel(discordID); err != nil {
 		// This can occasionally fail, so we don't want to report the error to Sentry
 		logger.Info("Failed to get the Discord channel: " + err.Error())
-		return "[error]"
+		return "", false
 	} else {
-		return channel.Name
+		return channel.Name, true
 	}
 }
 
@@ -250,14 +286,15 @@ func discordGetRoles() []*discordgo.Role {
 	return roles
 }
 
-func discordGetRole(discordID string) string {
+// discordGetRole returns false if the role could not be found
+func discordGetRole(discordID string) (string, bool) {
 	roles := discordGetRoles()
 	for _, role := range roles {
 		if role.ID == discordID {
-			return role.Name
+			return role.Name, true
 		}
 	}
-	return "[unknown role]"
+	return "", false
 }
 
 func discordGetRoleByName(name string) (*discordgo.Role, bool) {
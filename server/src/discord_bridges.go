@@ -0,0 +1,143 @@
+// Discord channels can be bridged to rooms other than the lobby (e.g. a channel for a specific
+// table) with the "DISCORD_CHANNEL_BRIDGES" environment variable
+// The lobby is always bridged to the "DISCORD_CHANNEL_SYNC_WITH_LOBBY" channel
+
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+	"github.com/sasha-s/go-deadlock"
+)
+
+var (
+	// These maps are only written to in "discordBridgesInit()", before the bot connects
+	discordBridgeChannels = make(map[string][]string) // Indexed by room
+	discordBridgeRooms    = make(map[string]string)   // Indexed by Discord channel ID
+
+	// Bridging can be temporarily turned off for a room with the "/bridge" command
+	discordBridgesOff      = make(map[string]struct{}) // Indexed by room
+	discordBridgesOffMutex = &deadlock.RWMutex{}
+	// By default, messages from Discord still appear on the website when bridging is off
+	discordBridgesOffInbound bool
+)
+
+func discordBridgesInit() {
+	discordBridgesOffInbound = getEnvInt("DISCORD_BRIDGE_OFF_INBOUND", 1) != 0
+
+	discordBridgeAdd("lobby", discordChannelSyncWithLobby)
+
+	// The format is a comma-separated list of "room:channelID" pairs,
+	// e.g. "lobby:123456789012345678,table5:234567890123456789"
+	bridgesString := os.Getenv("DISCORD_CHANNEL_BRIDGES")
+	for _, bridge := range strings.Split(bridgesString, ",") {
+		bridge = strings.TrimSpace(bridge)
+		if bridge == "" {
+			continue
+		}
+
+		parts := strings.Split(bridge, ":")
+		if len(parts) != 2 {
+			logger.Warn("The Discord channel bridge of \"" + bridge + "\" is not in the format of " +
+				"\"room:channelID\"; ignoring it.")
+			continue
+		}
+		room := strings.TrimSpace(parts[0])
+		channelID := strings.TrimSpace(parts[1])
+		if room != "lobby" && !strings.HasPrefix(room, "table") {
+			logger.Warn("The Discord channel bridge of \"" + bridge + "\" has an invalid room; " +
+				"ignoring it.")
+			continue
+		}
+
+		discordBridgeAdd(room, channelID)
+	}
+}
+
+func discordBridgeAdd(room string, channelID string) {
+	// Each Discord channel can only send its messages to one room
+	if existingRoom, ok := discordBridgeRooms[channelID]; ok {
+		if existingRoom != room {
+			logger.Warn("The Discord channel of \"" + channelID + "\" is already bridged to " +
+				"\"" + existingRoom + "\"; ignoring the bridge to \"" + room + "\".")
+		}
+		return
+	}
+
+	discordBridgeRooms[channelID] = room
+	discordBridgeChannels[room] = append(discordBridgeChannels[room], channelID)
+}
+
+// discordBridgesValidate is run once the bot has connected
+// A bridged channel that does not exist (e.g. because it was deleted) is not fatal,
+// since sending a message to it will fail gracefully
+func discordBridgesValidate() {
+	for channelID, room := range discordBridgeRooms {
+		if name, ok := discordGetChannel(channelID); !ok {
+			logger.Warn("The Discord channel of \"" + channelID + "\" (bridged to " +
+				"\"" + room + "\") could not be found.")
+		} else {
+			// Mentions of the bridged channels will not have to wait for a lookup
+			discordChannels.Set(channelID, name, true)
+		}
+	}
+}
+
+// discordGetBridgedChannels returns the IDs of the Discord channels that are bridged to a room
+// (which will be empty if the room is not bridged)
+func discordGetBridgedChannels(room string) []string {
+	return discordBridgeChannels[room]
+}
+
+// discordGetBridgedRoom returns false if the Discord channel is not bridged to a room
+func discordGetBridgedRoom(channelID string) (string, bool) {
+	room, ok := discordBridgeRooms[channelID]
+	return room, ok
+}
+
+// discordSendBridged replicates a chat message to all of the Discord channels that are bridged to
+// the room
+// The message should be unescaped, since Discord can handle escaping HTML special characters itself
+func discordSendBridged(room string, username string, msg string) {
+	if discordBridgeIsOff(room) {
+		return
+	}
+
+	if action, ok := chatParseAction(msg); ok {
+		msg = chatDiscordAction(username, action)
+		username = ""
+	}
+
+	for _, channelID := range discordGetBridgedChannels(room) {
+		discordSend(channelID, username, msg)
+	}
+}
+
+// discordBridgeIsOff returns true if bridging was turned off for the room with the "/bridge"
+// command
+func discordBridgeIsOff(room string) bool {
+	discordBridgesOffMutex.RLock()
+	defer discordBridgesOffMutex.RUnlock()
+
+	_, ok := discordBridgesOff[room]
+	return ok
+}
+
+// discordBridgeSet returns false if bridging was already in the requested state for the room
+func discordBridgeSet(room string, on bool) bool {
+	discordBridgesOffMutex.Lock()
+	defer discordBridgesOffMutex.Unlock()
+
+	_, off := discordBridgesOff[room]
+	if on != off {
+		return false
+	}
+	if on {
+		delete(discordBridgesOff, room)
+	} else {
+		discordBridgesOff[room] = struct{}{}
+	}
+	return true
+}
@@ -0,0 +1,184 @@
+// Looking up a nickname, role, or channel requires a request to Discord, which would block the
+// chat message that contains the mention
+// Instead, the lookups are cached in memory and performed in the background
+// A new chat message waits a short time for the lookups of its mentions with the "Prefetch()"
+// method; if a value is still not in the cache after that, the caller substitutes a placeholder
+// (messages are filled in again whenever they are read, so the value will show up later)
+
+package main
+
+import (
+	"container/list"
+	"time"
+
+	"github.com/sasha-s/go-deadlock"
+)
+
+const (
+	DiscordCacheSize = 1000
+	// Cached values are still used after they expire, but they will be refreshed in the background
+	DiscordCacheTTL = time.Hour
+	// Failed lookups are retried sooner, since the request to Discord can occasionally fail
+	DiscordCacheNotFoundTTL = time.Minute
+	// New chat messages wait at most this long for the lookups of the mentions in them
+	DiscordCachePrefetchTimeout = 500 * time.Millisecond
+)
+
+// The results of a cache lookup
+const (
+	DiscordCacheFound = iota
+	// Discord reported that the ID does not exist (e.g. a user that deleted their account)
+	DiscordCacheNotFound
+	// The ID has not been looked up yet (it is now being looked up in the background)
+	DiscordCacheMiss
+)
+
+type DiscordCache struct {
+	entries map[string]*list.Element // Indexed by Discord ID
+	// The most recently used entry is at the front
+	order *list.List
+	// The Discord IDs that are currently being looked up in the background
+	// (the channels are closed when the lookups finish)
+	pending map[string]chan struct{}
+	lookup  func(discordID string) (string, bool)
+	mutex   *deadlock.Mutex
+}
+
+type DiscordCacheEntry struct {
+	discordID string
+	value     string
+	// False if Discord reported that the ID does not exist
+	// (which is cached too, so that we do not keep looking it up)
+	found   bool
+	expires time.Time
+}
+
+var (
+	discordNicknames = NewDiscordCache(discordGetNickname)
+	discordRoles     = NewDiscordCache(discordGetRole)
+	discordChannels  = NewDiscordCache(discordGetChannel)
+)
+
+func NewDiscordCache(lookup func(discordID string) (string, bool)) *DiscordCache {
+	return &DiscordCache{
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		pending: make(map[string]chan struct{}),
+		lookup:  lookup,
+		mutex:   &deadlock.Mutex{},
+	}
+}
+
+// Get returns the value and one of the "DiscordCache" results above
+// It never blocks on a request to Discord; missing and expired values are looked up in the
+// background
+func (dc *DiscordCache) Get(discordID string) (string, int) {
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+
+	element, ok := dc.entries[discordID]
+	if !ok {
+		dc.refresh(discordID)
+		return "", DiscordCacheMiss
+	}
+
+	dc.order.MoveToFront(element)
+	entry := element.Value.(*DiscordCacheEntry)
+	if time.Now().After(entry.expires) {
+		dc.refresh(discordID)
+	}
+
+	if !entry.found {
+		return "", DiscordCacheNotFound
+	}
+	return entry.value, DiscordCacheFound
+}
+
+// Prefetch starts looking up the IDs that are not cached yet and waits for the lookups to finish,
+// or until the deadline
+func (dc *DiscordCache) Prefetch(discordIDs []string, deadline time.Time) {
+	dc.mutex.Lock()
+	waits := make([]chan struct{}, 0)
+	for _, discordID := range discordIDs {
+		if _, ok := dc.entries[discordID]; !ok {
+			dc.refresh(discordID)
+			waits = append(waits, dc.pending[discordID])
+		}
+	}
+	dc.mutex.Unlock()
+
+	if len(waits) == 0 {
+		return
+	}
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	for _, wait := range waits {
+		select {
+		case <-wait:
+		case <-timer.C:
+			return
+		}
+	}
+}
+
+// Set stores a value that was looked up elsewhere
+func (dc *DiscordCache) Set(discordID string, value string, found bool) {
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+
+	dc.set(discordID, value, found)
+}
+
+// set assumes that the mutex is locked
+func (dc *DiscordCache) set(discordID string, value string, found bool) {
+	entry := &DiscordCacheEntry{
+		discordID: discordID,
+		value:     value,
+		found:     found,
+		expires:   time.Now().Add(DiscordCacheTTL),
+	}
+	if !found {
+		entry.expires = time.Now().Add(DiscordCacheNotFoundTTL)
+	}
+
+	if element, ok := dc.entries[discordID]; ok {
+		oldEntry := element.Value.(*DiscordCacheEntry)
+		if !found && oldEntry.found {
+			// Keep showing the old value if the refresh failed
+			entry.value = oldEntry.value
+			entry.found = true
+		}
+		element.Value = entry
+		dc.order.MoveToFront(element)
+		return
+	}
+
+	dc.entries[discordID] = dc.order.PushFront(entry)
+
+	// Evict the least recently used entry
+	if dc.order.Len() > DiscordCacheSize {
+		oldest := dc.order.Back()
+		dc.order.Remove(oldest)
+		delete(dc.entries, oldest.Value.(*DiscordCacheEntry).discordID)
+	}
+}
+
+// refresh assumes that the mutex is locked
+func (dc *DiscordCache) refresh(discordID string) {
+	if _, ok := dc.pending[discordID]; ok {
+		return
+	}
+	done := make(chan struct{})
+	dc.pending[discordID] = done
+
+	go func() {
+		value, found := dc.lookup(discordID)
+
+		dc.mutex.Lock()
+		defer dc.mutex.Unlock()
+
+		delete(dc.pending, discordID)
+		dc.set(discordID, value, found)
+		close(done)
+	}()
+}
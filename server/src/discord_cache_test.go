@@ -0,0 +1,91 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiscordCacheGet(t *testing.T) {
+	tests := []struct {
+		name           string
+		discordID      string
+		prefetch       bool
+		expectedValue  string
+		expectedResult int
+	}{
+		{
+			name:           "first lookup of an existing user",
+			discordID:      "123",
+			prefetch:       false,
+			expectedValue:  "",
+			expectedResult: DiscordCacheMiss,
+		},
+		{
+			name:           "first lookup of a deleted user",
+			discordID:      "456",
+			prefetch:       false,
+			expectedValue:  "",
+			expectedResult: DiscordCacheMiss,
+		},
+		{
+			name:           "prefetched existing user",
+			discordID:      "123",
+			prefetch:       true,
+			expectedValue:  "Alice",
+			expectedResult: DiscordCacheFound,
+		},
+		{
+			name:           "prefetched deleted user",
+			discordID:      "456",
+			prefetch:       true,
+			expectedValue:  "",
+			expectedResult: DiscordCacheNotFound,
+		},
+	}
+
+	lookup := func(discordID string) (string, bool) {
+		if discordID == "123" {
+			return "Alice", true
+		}
+		return "", false
+	}
+
+	for _, test := range tests {
+		cache := NewDiscordCache(lookup)
+		if test.prefetch {
+			cache.Prefetch([]string{test.discordID}, time.Now().Add(time.Second))
+		}
+
+		value, result := cache.Get(test.discordID)
+		if value != test.expectedValue || result != test.expectedResult {
+			t.Errorf("%v: Get() = (%q, %v), expected (%q, %v)", test.name, value, result,
+				test.expectedValue, test.expectedResult)
+		}
+	}
+}
+
+func TestDiscordCachePrefetchTimeout(t *testing.T) {
+	release := make(chan struct{})
+	cache := NewDiscordCache(func(discordID string) (string, bool) {
+		<-release
+		return "Alice", true
+	})
+
+	// A slow lookup does not block the message for longer than the deadline
+	start := time.Now()
+	cache.Prefetch([]string{"123"}, time.Now().Add(50*time.Millisecond))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Prefetch() took %v, expected it to stop at the deadline", elapsed)
+	}
+	if _, result := cache.Get("123"); result != DiscordCacheMiss {
+		t.Errorf("Get() before the lookup finished = %v, expected %v", result, DiscordCacheMiss)
+	}
+
+	// The value is available once the lookup finishes
+	close(release)
+	cache.Prefetch([]string{"123"}, time.Now().Add(time.Second))
+	if value, result := cache.Get("123"); value != "Alice" || result != DiscordCacheFound {
+		t.Errorf("Get() after the lookup finished = (%q, %v), expected (%q, %v)", value, result,
+			"Alice", DiscordCacheFound)
+	}
+}
@@ -0,0 +1,76 @@
+// When a Discord bot or integration gets stuck in a loop, it can post the same line over and over
+// into a bridged channel
+// Identical consecutive messages from the same Discord author are dropped before they reach the
+// "commandChat()" function, so that they do not flood the room or the database
+
+package main
+
+import (
+	"time"
+
+	"github.com/sasha-s/go-deadlock"
+)
+
+const (
+	// A message is only considered to be a duplicate if it was sent this soon after the last one
+	DiscordDuplicateWindow = 10 * time.Second
+)
+
+var (
+	discordDuplicates = NewDiscordDuplicates()
+)
+
+type DiscordDuplicates struct {
+	lastMessages map[string]*DiscordLastMessage // Indexed by Discord ID
+	lastPurged   time.Time
+	mutex        *deadlock.Mutex
+}
+
+type DiscordLastMessage struct {
+	channelID string
+	msg       string
+	// The time of the last message, including the duplicates that were dropped,
+	// so that a loop that is faster than the window is dropped for as long as it continues
+	datetime time.Time
+}
+
+func NewDiscordDuplicates() *DiscordDuplicates {
+	return &DiscordDuplicates{
+		lastMessages: make(map[string]*DiscordLastMessage),
+		lastPurged:   time.Now(),
+		mutex:        &deadlock.Mutex{},
+	}
+}
+
+// Check returns true if the message is identical to the last message from the same author in the
+// same channel and it was sent within the window
+func (dd *DiscordDuplicates) Check(discordID string, channelID string, msg string) bool {
+	dd.mutex.Lock()
+	defer dd.mutex.Unlock()
+
+	now := time.Now()
+	lastMessage, ok := dd.lastMessages[discordID]
+	duplicate := ok &&
+		lastMessage.channelID == channelID &&
+		lastMessage.msg == msg &&
+		now.Sub(lastMessage.datetime) < DiscordDuplicateWindow
+	dd.lastMessages[discordID] = &DiscordLastMessage{
+		channelID: channelID,
+		msg:       msg,
+		datetime:  now,
+	}
+
+	// Forget about the authors who have not said anything recently
+	// (at most once a minute, so that we do not have to iterate over every author every time that a
+	// message is sent)
+	if now.Sub(dd.lastPurged) >= time.Minute {
+		dd.lastPurged = now
+		for discordID2, lastMessage2 := range dd.lastMessages {
+			if now.Sub(lastMessage2.datetime) >= DiscordDuplicateWindow {
+				delete(dd.lastMessages, discordID2)
+			}
+		}
+	}
+
+	return duplicate
+}
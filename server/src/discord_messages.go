@@ -0,0 +1,273 @@
+// When someone edits or deletes a message on Discord, the copy of it in the bridged room is
+// updated or removed to match
+// This requires keeping track of which chat message each Discord message became
+// The mapping is only kept in memory, so messages that were sent before the server restarted (or
+// a long time ago) are left alone
+
+package main
+
+import (
+	"context"
+	"html"
+	"strconv"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+	"github.com/bwmarrin/discordgo"
+	"github.com/sasha-s/go-deadlock"
+)
+
+const (
+	// Edits and deletes on Discord are only mirrored for messages that were sent this recently
+	DiscordMessageExpiration = 24 * time.Hour
+)
+
+var (
+	discordMessages = NewDiscordMessages()
+)
+
+type DiscordMessages struct {
+	messages   map[string]*DiscordMessage // Indexed by Discord message ID
+	lastPurged time.Time
+	mutex      *deadlock.Mutex
+}
+
+type DiscordMessage struct {
+	messageID string // The ID of the chat message on the server
+	room      string
+	username  string
+	datetime  time.Time
+}
+
+func NewDiscordMessages() *DiscordMessages {
+	return &DiscordMessages{
+		messages:   make(map[string]*DiscordMessage),
+		lastPurged: time.Now(),
+		mutex:      &deadlock.Mutex{},
+	}
+}
+
+func (dm *DiscordMessages) Set(
+	discordMessageID string,
+	messageID string,
+	room string,
+	username string,
+	datetime time.Time,
+) {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+
+	dm.messages[discordMessageID] = &DiscordMessage{
+		messageID: messageID,
+		room:      room,
+		username:  username,
+		datetime:  datetime,
+	}
+
+	// Forget about the messages that are too old to be mirrored
+	// (at most once an hour, so that we do not have to iterate over every message every time that a
+	// message is sent)
+	now := time.Now()
+	if now.Sub(dm.lastPurged) >= time.Hour {
+		dm.lastPurged = now
+		for discordMessageID2, message := range dm.messages {
+			if now.Sub(message.datetime) >= DiscordMessageExpiration {
+				delete(dm.messages, discordMessageID2)
+			}
+		}
+	}
+}
+
+func (dm *DiscordMessages) Get(discordMessageID string) (*DiscordMessage, bool) {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+
+	message, ok := dm.messages[discordMessageID]
+	if !ok || time.Since(message.datetime) >= DiscordMessageExpiration {
+		return nil, false
+	}
+	return message, true
+}
+
+func (dm *DiscordMessages) Delete(discordMessageID string) (*DiscordMessage, bool) {
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+
+	message, ok := dm.messages[discordMessageID]
+	if !ok {
+		return nil, false
+	}
+	delete(dm.messages, discordMessageID)
+	if time.Since(message.datetime) >= DiscordMessageExpiration {
+		return nil, false
+	}
+	return message, true
+}
+
+/*
+	Event handlers
+*/
+
+// Mirror the edits of bridged messages to the room that the channel is bridged to
+func discordMessageUpdate(s *discordgo.Session, m *discordgo.MessageUpdate) {
+	// Don't do anything if we are not yet connected
+	if discordIsReady.IsNotSet() {
+		return
+	}
+
+	// Discord also sends an update without any content when it adds an embed to a message
+	// (e.g. the preview of a link)
+	if m.Message == nil || m.Content == "" {
+		return
+	}
+
+	message, ok := discordMessages.Get(m.ID)
+	if !ok {
+		return
+	}
+
+	ctx := NewMiscContext("discordMessageUpdate")
+
+	// Sanitize and validate the new message in the same way as in the "commandChat()" function
+	var newMsg string
+	if v, valid := sanitizeChatInput(nil, m.Content, false); !valid {
+		return
+	} else {
+		newMsg = v
+	}
+	d := &CommandData{ // nolint: exhaustivestruct
+		Username: message.username,
+		Msg:      newMsg,
+		Discord:  true,
+		Room:     message.room,
+	}
+	if !chatBannedWordsCheck(nil, d, newMsg) {
+		// The original message is left as it was
+		return
+	}
+	newMsg = html.EscapeString(newMsg)
+
+	logger.Info("Discord user \"" + message.username + "\" edited chat message " +
+		"\"" + message.messageID + "\" in room \"" + message.room + "\": " + newMsg)
+
+	if message.room == "lobby" {
+		if err := chatEditLobbySend(
+			message.messageID,
+			newMsg,
+			message.username,
+			message.datetime,
+			message.room,
+		); err != nil {
+			logger.Error("Failed to update chat message \"" + message.messageID + "\" from " +
+				"Discord: " + err.Error())
+		}
+		return
+	}
+
+	t, exists := discordGetBridgedTable(ctx, message.room)
+	if !exists {
+		return
+	}
+	defer t.Unlock(ctx)
+
+	for _, chatMsg := range t.Chat {
+		if chatMsg.ID == message.messageID {
+			chatEditTableSend(t, chatMsg, newMsg, message.room)
+			break
+		}
+	}
+}
+
+// Mirror the deletes of bridged messages to the room that the channel is bridged to
+func discordMessageDelete(s *discordgo.Session, m *discordgo.MessageDelete) {
+	if m.Message == nil {
+		return
+	}
+	discordDeleteBridged(m.ID)
+}
+
+// Moderators on Discord can delete many messages at once
+func discordMessageDeleteBulk(s *discordgo.Session, m *discordgo.MessageDeleteBulk) {
+	for _, discordMessageID := range m.Messages {
+		discordDeleteBridged(discordMessageID)
+	}
+}
+
+/*
+	Miscellaneous functions
+*/
+
+func discordDeleteBridged(discordMessageID string) {
+	// Don't do anything if we are not yet connected
+	if discordIsReady.IsNotSet() {
+		return
+	}
+
+	message, ok := discordMessages.Delete(discordMessageID)
+	if !ok {
+		return
+	}
+
+	ctx := NewMiscContext("discordDeleteBridged")
+
+	logger.Info("Discord user \"" + message.username + "\" deleted chat message " +
+		"\"" + message.messageID + "\" in room \"" + message.room + "\".")
+
+	chatDeleteMessage := &ChatDeleteMessage{
+		ID:   message.messageID,
+		Room: message.room,
+	}
+
+	if message.room == "lobby" {
+		discordDeleteFromDatabase(message.messageID, message.room)
+
+		// Lobby messages go to everyone
+		for _, s2 := range sessions.GetList() {
+			s2.Emit("chatDelete", chatDeleteMessage)
+		}
+		return
+	}
+
+	t, exists := discordGetBridgedTable(ctx, message.room)
+	if !exists {
+		return
+	}
+	defer t.Unlock(ctx)
+
+	t.DeleteChatMessage(message.messageID)
+
+	// The review comments in a replay are stored in a different room, in "chat_review.go"
+	room := message.room
+	if t.GetReviewRoomName() != "" {
+		room = t.GetReviewRoomName()
+	}
+	discordDeleteFromDatabase(message.messageID, room)
+
+	t.NotifyChatDelete(chatDeleteMessage)
+}
+
+func discordDeleteFromDatabase(messageID string, room string) {
+	if _, _, err := models.ChatLog.Delete(messageID, room); err != nil {
+		logger.Error("Failed to delete chat message \"" + messageID + "\": " + err.Error())
+		// Do not return on a failed deletion, since the message is still removed from the clients
+	}
+	if err := models.ChatLogReactions.DeleteAll(messageID); err != nil {
+		logger.Error("Failed to delete the reactions for chat message \"" + messageID + "\": " +
+			err.Error())
+	}
+}
+
+// discordGetBridgedTable returns the table for a bridged table room (e.g. "table5")
+// If it exists, the table mutex is locked
+func discordGetBridgedTable(ctx context.Context, room string) (*Table, bool) {
+	match := lobbyRoomRegExp.FindStringSubmatch(room)
+	if match == nil {
+		return nil, false
+	}
+	tableID, err := strconv.ParseUint(match[1], 10, 64)
+	if err != nil {
+		return nil, false
+	}
+
+	return getTableAndLock(ctx, nil, tableID, true, true)
+}
@@ -1,7 +1,12 @@
 package main
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"path"
 	"regexp"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
 )
 
 const (
@@ -11,4 +16,23 @@ const (
 
 var (
 	emojiRegExp = regexp.MustCompile(EmojiPattern)
+
+	// Indexed by shortcode (e.g. "joy"), the values are the emoji themselves
+	emojis map[string]string
 )
+
+func emojisInit() {
+	// Import the JSON file
+	filePath := path.Join(jsonPath, "emojis.json")
+	var fileContents []byte
+	if v, err := ioutil.ReadFile(filePath); err != nil {
+		logger.Fatal("Failed to read the \"" + filePath + "\" file: " + err.Error())
+		return
+	} else {
+		fileContents = v
+	}
+	if err := json.Unmarshal(fileContents, &emojis); err != nil {
+		logger.Fatal("Failed to convert the emojis file to JSON: " + err.Error())
+		return
+	}
+}
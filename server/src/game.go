@@ -72,6 +72,10 @@ type Game struct {
 	Paused           bool
 	PausePlayerIndex int
 	PauseCount       int
+	DatetimePaused   time.Time
+	// The ID of the table chat message that announced the current pause
+	// (so that it can be annotated when the game is unpaused)
+	PauseChatID string
 
 	// Shared replay fields
 	EfficiencyMod int
@@ -123,6 +127,8 @@ func NewGame(t *Table) *Game {
 		Paused:           false,
 		PausePlayerIndex: -1,
 		PauseCount:       0,
+		DatetimePaused:   time.Time{},
+		PauseChatID:      "",
 
 		EfficiencyMod: 0,
 
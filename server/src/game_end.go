@@ -116,6 +116,8 @@ func (g *Game) WriteDatabase() error {
 		EndCondition:     g.EndCondition,
 		DatetimeStarted:  g.DatetimeStarted,
 		DatetimeFinished: g.DatetimeFinished,
+		TableID:          t.ID,
+		DatetimeCreated:  t.DatetimeCreated,
 	}
 	if v, err := models.Games.Insert(row); err != nil {
 		logger.Error("Failed to insert the game row: " + err.Error())
@@ -215,12 +217,16 @@ func (g *Game) WriteDatabase() error {
 	}
 
 	// Next, we insert rows for each chat message (if any)
+	// (most of them will already be in the database, since table messages are inserted as they
+	// are sent, but this catches any insertions that failed or that happened on an older server)
 	chatLogRows := make([]*ChatLogRow, 0)
 	for _, chatMsg := range t.Chat {
 		chatLogRows = append(chatLogRows, &ChatLogRow{
-			UserID:  chatMsg.UserID,
-			Message: chatMsg.Msg,
-			Room:    t.GetRoomName(),
+			MessageID: chatMsg.ID,
+			UserID:    chatMsg.UserID,
+			Message:   chatMsg.Msg,
+			Room:      t.GetRoomName(),
+			ReplyTo:   chatMsg.ReplyTo,
 		})
 	}
 	if len(chatLogRows) > 0 {
@@ -449,6 +455,12 @@ func (t *Table) ConvertToSharedReplay(ctx context.Context, d *CommandData) {
 		sp.Session.NotifyNoteList(t, -1)
 	}
 
+	// Now that the game is over, the players can see what the spectators were saying
+	chatSpectatorsReveal(ctx, t)
+
+	// Also let everyone know about the tags that were added during the game
+	tagsReveal(ctx, t)
+
 	notifyAllTable(t)    // Update the spectator list for the row in the lobby
 	t.NotifySpectators() // Update the in-game spectator list
 }
@@ -268,6 +268,8 @@ func httpInit() {
 	// Path handlers for bots, developers, researchers, etc.
 	httpRouter.GET("/export", httpExport)
 	httpRouter.GET("/export/:databaseID", httpExport)
+	httpRouter.GET("/chat-transcript/:tableID", httpChatTranscript)
+	httpRouter.GET("/chat-transcript-game/:databaseID", httpChatTranscriptGame)
 
 	// Other
 	httpRouter.Static("/public", path.Join(projectPath, "public"))
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hanabi-Live/hanabi-live/logger"
+	gsessions "github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// Downloads the full chat of a table
+//   URL: /chat-transcript/:tableID
+//
+//   Query parameters
+//   format  string ("text" or "json", default "text")
+//
+// Only the players at the table and moderators are allowed to download the transcript
+func httpChatTranscript(c *gin.Context) {
+	// Local variables
+	w := c.Writer
+
+	userID, format, ok := httpChatTranscriptParse(c)
+	if !ok {
+		return
+	}
+
+	// Parse the table ID from the URL
+	var tableID uint64
+	if v, err := strconv.ParseUint(c.Param("tableID"), 10, 64); err != nil {
+		http.Error(w, "Error: That is not a valid table ID.", http.StatusBadRequest)
+		return
+	} else {
+		tableID = v
+	}
+
+	t, exists := getTableAndLock(c, nil, tableID, true, true)
+	if !exists {
+		http.Error(w, "Error: That table does not exist.", http.StatusNotFound)
+		return
+	}
+	room := t.GetRoomName()
+	tableName := t.Name
+	isPlayer := t.GetPlayerIndexFromID(userID) != -1
+
+	// Table IDs are reused after a restart, so older messages in the room are from other tables
+	since := t.DatetimeCreated
+	if t.DatetimeChatCleared.After(since) {
+		since = t.DatetimeChatCleared
+	}
+	t.Unlock(c)
+
+	if !isPlayer && !httpChatTranscriptCheckModerator(c, userID) {
+		return
+	}
+
+	// Get every message (the messages are already filled and formatted)
+	var msgs []*ChatMessage
+	if v, err := chatGetPastFromDatabase(room, 0, since.Add(-time.Nanosecond), time.Time{}); err != nil {
+		logger.Error("Failed to get the chat transcript for room \"" + room + "\": " + err.Error())
+		http.Error(
+			w,
+			http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError,
+		)
+		return
+	} else {
+		msgs = v
+	}
+
+	filename := "hanabi-chat-table-" + strconv.FormatUint(tableID, 10) + "." + format
+	httpChatTranscriptSend(c, format, filename, "table: "+tableName, msgs)
+}
+
+// Downloads the full chat of a game that has already finished
+// This is the chat from the table where the game was played, followed by the review comments from
+// every time that the replay was opened (in "chat_review.go")
+//   URL: /chat-transcript-game/:databaseID
+//
+//   Query parameters
+//   format  string ("text" or "json", default "text")
+//
+// Only the players of the game and moderators are allowed to download the transcript
+func httpChatTranscriptGame(c *gin.Context) {
+	// Local variables
+	w := c.Writer
+
+	userID, format, ok := httpChatTranscriptParse(c)
+	if !ok {
+		return
+	}
+
+	// Parse the database ID from the URL
+	var databaseID int
+	if v, err := strconv.Atoi(c.Param("databaseID")); err != nil || v <= 0 {
+		http.Error(w, "Error: That is not a valid game ID.", http.StatusBadRequest)
+		return
+	} else {
+		databaseID = v
+	}
+
+	if exists, err := models.Games.Exists(databaseID); err != nil {
+		logger.Error("Failed to check to see if game " + strconv.Itoa(databaseID) + " exists: " +
+			err.Error())
+		http.Error(
+			w,
+			http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError,
+		)
+		return
+	} else if !exists {
+		http.Error(w, "Error: That game does not exist.", http.StatusNotFound)
+		return
+	}
+
+	var dbPlayers []*DBPlayer
+	if v, err := models.Games.GetPlayers(databaseID); err != nil {
+		logger.Error("Failed to get the players from the database for game " +
+			strconv.Itoa(databaseID) + ": " + err.Error())
+		http.Error(
+			w,
+			http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError,
+		)
+		return
+	} else {
+		dbPlayers = v
+	}
+	isPlayer := false
+	for _, dbPlayer := range dbPlayers {
+		if dbPlayer.ID == userID {
+			isPlayer = true
+			break
+		}
+	}
+
+	if !isPlayer && !httpChatTranscriptCheckModerator(c, userID) {
+		return
+	}
+
+	msgs, err := chatTranscriptGetGame(databaseID)
+	if err != nil {
+		logger.Error("Failed to get the chat transcript for game " + strconv.Itoa(databaseID) +
+			": " + err.Error())
+		http.Error(
+			w,
+			http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+
+	filename := "hanabi-chat-game-" + strconv.Itoa(databaseID) + "." + format
+	httpChatTranscriptSend(c, format, filename, "game #"+strconv.Itoa(databaseID), msgs)
+}
+
+// httpChatTranscriptParse validates the parts of the request that are the same for tables and
+// games, returning the user ID and the format
+// If it returns false, an error has already been sent to the client
+func httpChatTranscriptParse(c *gin.Context) (int, string, bool) {
+	// Local variables
+	w := c.Writer
+
+	// They must be logged in
+	session := gsessions.Default(c)
+	var userID int
+	if v := session.Get("userID"); v == nil {
+		http.Error(w, "Error: You must be logged in to download a chat transcript.",
+			http.StatusUnauthorized)
+		return 0, "", false
+	} else {
+		userID = v.(int)
+	}
+
+	format := c.DefaultQuery("format", "text")
+	if format != "text" && format != "json" {
+		http.Error(w, "Error: The format must be either \"text\" or \"json\".",
+			http.StatusBadRequest)
+		return 0, "", false
+	}
+
+	return userID, format, true
+}
+
+// httpChatTranscriptCheckModerator returns true if the user is a moderator
+// If it returns false, an error has already been sent to the client
+func httpChatTranscriptCheckModerator(c *gin.Context, userID int) bool {
+	// Local variables
+	w := c.Writer
+
+	if moderator, err := models.Users.IsModerator(userID); err != nil {
+		logger.Error("Failed to check to see if user " + strconv.Itoa(userID) +
+			" is a moderator: " + err.Error())
+		http.Error(
+			w,
+			http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError,
+		)
+		return false
+	} else if !moderator {
+		http.Error(w, "Error: Only the players of the game can download its chat transcript.",
+			http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+func httpChatTranscriptSend(
+	c *gin.Context,
+	format string,
+	filename string,
+	title string,
+	msgs []*ChatMessage,
+) {
+	c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+
+	if format == "json" {
+		c.JSON(http.StatusOK, msgs)
+		return
+	}
+
+	c.String(http.StatusOK, chatTranscriptText(title, msgs))
+}
+
+// chatTranscriptGetGame gets every message of a game from the database, from oldest to newest
+func chatTranscriptGetGame(databaseID int) ([]*ChatMessage, error) {
+	msgs := make([]*ChatMessage, 0)
+
+	// The chat from the game itself
+	// (nothing was recorded about the table for older games, so they only have review comments)
+	if recorded, room, datetimeCreated, datetimeFinished, err := models.Games.GetChatRoom(
+		databaseID,
+	); err != nil {
+		return nil, err
+	} else if recorded {
+		if v, err := chatGetPastFromDatabase(
+			room,
+			0,
+			datetimeCreated.Add(-time.Nanosecond),
+			datetimeFinished.Add(time.Nanosecond),
+		); err != nil {
+			return nil, err
+		} else {
+			msgs = append(msgs, v...)
+		}
+	}
+
+	// The review comments
+	room := ReviewRoomPrefix + strconv.Itoa(databaseID)
+	if v, err := chatGetPastFromDatabase(room, 0, time.Time{}, time.Time{}); err != nil {
+		return nil, err
+	} else {
+		msgs = append(msgs, v...)
+	}
+
+	return msgs, nil
+}
+
+// chatTranscriptText formats the messages in the same way as the "/search" command
+// Messages are stored escaped (and filled messages can contain formatting tags),
+// but a text file does not need either
+func chatTranscriptText(title string, msgs []*ChatMessage) string {
+	var sb strings.Builder
+	sb.WriteString("Chat transcript for " + title + "\n\n")
+	for _, msg := range msgs {
+		name := msg.Who
+		if msg.Server || name == "__server" {
+			name = WebsiteName
+		}
+		text := html.UnescapeString(htmlTagRegExp.ReplaceAllString(msg.Msg, ""))
+		line := "<" + name + "> " + text
+		if msg.Action {
+			line = "* " + name + " " + text
+		}
+		sb.WriteString("[" + formatTimestampUnix(msg.Datetime) + "] " + line + "\n")
+	}
+
+	return sb.String()
+}
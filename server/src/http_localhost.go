@@ -34,19 +34,31 @@ func httpLocalhostInit() {
 
 	// Path handlers
 	httpRouter.POST("/ban", httpLocalhostUserAction)
+	httpRouter.POST("/bracket", httpLocalhostBracket)
+	httpRouter.POST("/bracketAnnounce", httpLocalhostBracketAnnounce)
 	httpRouter.GET("/cancel", httpLocalhostCancel)
+	httpRouter.POST("/cancelAnnouncement", httpLocalhostCancelAnnouncement)
 	httpRouter.GET("/clearEmptyTables", httpLocalhostClearEmptyTables)
 	httpRouter.GET("/debugFunction", httpLocalhostDebugFunction)
+	httpRouter.POST("/disableReminder", httpLocalhostDisableReminder)
 	httpRouter.GET("/getLongTables", httpLocalhostGetLongTables)
 	httpRouter.GET("/maintenance", httpLocalhostMaintenance)
+	httpRouter.GET("/metrics", httpLocalhostMetrics)
 	httpRouter.POST("/mute", httpLocalhostUserAction)
 	httpRouter.GET("/print", httpLocalhostPrint)
 	httpRouter.GET("/gracefulRestart", httpLocalhostGracefulRestart)
+	httpRouter.GET("/reloadBannedWords", httpLocalhostReloadBannedWords)
+	httpRouter.GET("/reloadMacros", httpLocalhostReloadMacros)
+	httpRouter.GET("/reloadReminders", httpLocalhostReloadReminders)
+	httpRouter.GET("/reminders", httpLocalhostReminders)
 	httpRouter.GET("/saveTables", httpLocalhostSaveTables)
+	httpRouter.POST("/scheduleAnnouncement", httpLocalhostScheduleAnnouncement)
 	httpRouter.POST("/sendWarning", httpLocalhostUserAction)
 	httpRouter.POST("/sendWarningAll", httpLocalhostSendWarningAll)
 	httpRouter.POST("/sendError", httpLocalhostUserAction)
+	httpRouter.POST("/sendGames", httpLocalhostSendGames)
 	httpRouter.GET("/shutdown", httpLocalhostShutdown)
+	httpRouter.POST("/shutdownCountdown", httpLocalhostShutdownCountdown)
 	httpRouter.GET("/terminate", httpLocalhostTerminate)
 	httpRouter.GET("/timeLeft", httpLocalhostTimeLeft)
 	httpRouter.GET("/uptime", httpLocalhostUptime)
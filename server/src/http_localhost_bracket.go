@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// httpLocalhostBracket replaces the tournament bracket and announces the first round
+// (in "chat_bracket.go")
+func httpLocalhostBracket(c *gin.Context) {
+	// Local variables
+	w := c.Writer
+
+	// Validate that the admin sent a bracket
+	bracketString := c.PostForm("bracket")
+	if bracketString == "" {
+		http.Error(w, "You must send a \"bracket\" POST parameter.", http.StatusBadRequest)
+		return
+	}
+	var bracket *TournamentBracket
+	if err := json.Unmarshal([]byte(bracketString), &bracket); err != nil {
+		http.Error(w, "Error: failed to parse the bracket: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := chatBracketSet(bracket); err != nil {
+		http.Error(w, "Error: "+err.Error()+".", http.StatusBadRequest)
+		return
+	}
+	if err := chatBracketAnnounce(c, 1); err != nil {
+		http.Error(w, "Error: "+err.Error()+".", http.StatusBadRequest)
+		return
+	}
+
+	c.String(http.StatusOK, "success\n")
+}
+
+// httpLocalhostBracketAnnounce announces a round of the tournament bracket
+// If the "round" POST parameter is blank, the current round is announced again
+func httpLocalhostBracketAnnounce(c *gin.Context) {
+	// Local variables
+	w := c.Writer
+
+	round := 0
+	if roundString := c.PostForm("round"); roundString != "" {
+		if v, err := strconv.Atoi(roundString); err != nil || v < 1 {
+			http.Error(w, "The \"round\" POST parameter must be a positive number.",
+				http.StatusBadRequest)
+			return
+		} else {
+			round = v
+		}
+	}
+
+	if err := chatBracketAnnounce(c, round); err != nil {
+		http.Error(w, "Error: "+err.Error()+".", http.StatusBadRequest)
+		return
+	}
+
+	c.String(http.StatusOK, "success\n")
+}
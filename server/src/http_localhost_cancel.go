@@ -10,6 +10,12 @@ func httpLocalhostCancel(c *gin.Context) {
 	// Local variables
 	w := c.Writer
 
+	// A shutdown countdown can be canceled before the shutdown itself begins
+	if chatServerCancelShutdownCountdown(c) {
+		c.String(http.StatusOK, "success\n")
+		return
+	}
+
 	if shuttingDown.IsNotSet() {
 		http.Error(
 			w,
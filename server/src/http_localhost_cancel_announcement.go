@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func httpLocalhostCancelAnnouncement(c *gin.Context) {
+	// Local variables
+	w := c.Writer
+
+	// Validate the announcement ID
+	idString := c.PostForm("id")
+	if idString == "" {
+		http.Error(w, "You must send an \"id\" POST parameter.", http.StatusBadRequest)
+		return
+	}
+	var id int
+	if v, err := strconv.Atoi(idString); err != nil {
+		http.Error(w, "The \"id\" POST parameter must be a number.", http.StatusBadRequest)
+		return
+	} else {
+		id = v
+	}
+
+	if !chatServerCancelScheduled(id) {
+		http.Error(
+			w,
+			"There is no pending announcement with an ID of \""+idString+"\".",
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	c.String(http.StatusOK, "success\n")
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// httpLocalhostMetrics is meant to be scraped by Prometheus
+func httpLocalhostMetrics(c *gin.Context) {
+	c.Data(
+		http.StatusOK,
+		"text/plain; version=0.0.4; charset=utf-8",
+		[]byte(chatMetrics.Prometheus()),
+	)
+}
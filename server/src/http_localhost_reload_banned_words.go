@@ -0,0 +1,19 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func httpLocalhostReloadBannedWords(c *gin.Context) {
+	// Local variables
+	w := c.Writer
+
+	if err := chatBannedWords.Load(); err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.String(http.StatusOK, "success\n")
+}
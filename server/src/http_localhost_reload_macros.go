@@ -0,0 +1,20 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func httpLocalhostReloadMacros(c *gin.Context) {
+	// Local variables
+	w := c.Writer
+
+	if err := chatMacros.Load(); err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	chatMacros.NotifyAll()
+
+	c.String(http.StatusOK, "success\n")
+}
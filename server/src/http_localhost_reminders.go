@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func httpLocalhostReminders(c *gin.Context) {
+	lines := chatReminders.List()
+	if len(lines) == 0 {
+		c.String(http.StatusOK, "There are no chat reminders.\n")
+		return
+	}
+
+	c.String(http.StatusOK, strings.Join(lines, "\n")+"\n")
+}
+
+func httpLocalhostDisableReminder(c *gin.Context) {
+	// Local variables
+	w := c.Writer
+
+	// Validate the name of the reminder
+	name := c.PostForm("name")
+	if name == "" {
+		http.Error(w, "You must send a \"name\" POST parameter.", http.StatusBadRequest)
+		return
+	}
+
+	if !chatReminders.Disable(name) {
+		http.Error(
+			w,
+			"There is no enabled reminder with a name of \""+name+"\".",
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	c.String(http.StatusOK, "success\n")
+}
+
+func httpLocalhostReloadReminders(c *gin.Context) {
+	// Local variables
+	w := c.Writer
+
+	if err := chatReminders.Load(); err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c.String(http.StatusOK, "success\n")
+}
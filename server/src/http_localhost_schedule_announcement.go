@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func httpLocalhostScheduleAnnouncement(c *gin.Context) {
+	// Local variables
+	w := c.Writer
+
+	// Validate that the admin sent a message
+	msg := c.PostForm("msg")
+	if msg == "" {
+		http.Error(w, "You must send a \"msg\" POST parameter.", http.StatusBadRequest)
+		return
+	}
+
+	// Validate the time (e.g. "2021-01-02T03:04:05Z")
+	timeString := c.PostForm("time")
+	if timeString == "" {
+		http.Error(w, "You must send a \"time\" POST parameter.", http.StatusBadRequest)
+		return
+	}
+	var at time.Time
+	if v, err := time.Parse(time.RFC3339, timeString); err != nil {
+		http.Error(
+			w,
+			"The \"time\" POST parameter must be in RFC 3339 format (e.g. \"2021-01-02T03:04:05Z\").",
+			http.StatusBadRequest,
+		)
+		return
+	} else {
+		at = v
+	}
+	if at.Before(time.Now()) {
+		http.Error(w, "The \"time\" POST parameter must be in the future.", http.StatusBadRequest)
+		return
+	}
+
+	id := chatServerSendScheduled(c, msg, at)
+	c.String(http.StatusOK, "success (announcement ID: "+strconv.Itoa(id)+")\n")
+}
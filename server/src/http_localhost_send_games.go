@@ -0,0 +1,28 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// httpLocalhostSendGames sends a server message to every table that is not a shared replay
+// (e.g. to warn the players that their game will be paused for maintenance)
+func httpLocalhostSendGames(c *gin.Context) {
+	// Local variables
+	w := c.Writer
+
+	// Validate that the admin sent a message
+	msg := c.PostForm("msg")
+	if msg == "" {
+		http.Error(w, "You must send a \"msg\" POST parameter.", http.StatusBadRequest)
+		return
+	}
+
+	// We must acquire the tables lock before entering the "chatServerSendGames()" function
+	tables.Lock(c)
+	chatServerSendGames(c, msg)
+	tables.Unlock(c)
+
+	c.String(http.StatusOK, "success\n")
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func httpLocalhostShutdownCountdown(c *gin.Context) {
+	// Local variables
+	w := c.Writer
+
+	if shuttingDown.IsSet() {
+		http.Error(w, "The server is already shutting down.", http.StatusBadRequest)
+		return
+	}
+
+	// Validate the duration (e.g. "15m")
+	durationString := c.PostForm("duration")
+	if durationString == "" {
+		http.Error(w, "You must send a \"duration\" POST parameter.", http.StatusBadRequest)
+		return
+	}
+	var duration time.Duration
+	if v, err := time.ParseDuration(durationString); err != nil {
+		http.Error(
+			w,
+			"The \"duration\" POST parameter must be a duration (e.g. \"15m\").",
+			http.StatusBadRequest,
+		)
+		return
+	} else {
+		duration = v
+	}
+	if duration <= 0 {
+		http.Error(w, "The \"duration\" POST parameter must be positive.", http.StatusBadRequest)
+		return
+	}
+
+	if !chatServerShutdownCountdown(c, duration) {
+		http.Error(w, "There is already a shutdown countdown in progress.", http.StatusBadRequest)
+		return
+	}
+
+	c.String(http.StatusOK, "success\n")
+}
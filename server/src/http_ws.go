@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strconv"
 	"sync/atomic"
+	"time"
 
 	"github.com/Hanabi-Live/hanabi-live/logger"
 	gsessions "github.com/gin-contrib/sessions"
@@ -106,6 +107,17 @@ func httpWS(c *gin.Context) {
 	// Attach the user ID and username so that we can identify the user in the next step
 	keys["userID"] = userID
 	keys["username"] = username
+	// The client can optionally specify the time of the last lobby chat message that they saw
+	// (in Unix milliseconds) so that we only have to send them the messages that they missed
+	lastSeen := time.Time{}
+	if v := c.Query("lastSeen"); v != "" {
+		if milliseconds, err := strconv.ParseInt(v, 10, 64); err == nil {
+			lastSeen = time.Unix(0, milliseconds*int64(time.Millisecond))
+		}
+	}
+	keys["lastSeen"] = lastSeen
+	// Private messages from the server are shown in the language of their browser, if possible
+	keys["language"] = chatGetLanguage(c.GetHeader("Accept-Language"))
 
 	// "HandleRequestWithKeys()" will call the "websocketConnect()" function if successful;
 	// further initialization is performed there
@@ -179,6 +179,9 @@ func main() {
 	// Initialize the list that contains every word in the dictionary
 	wordListInit()
 
+	// Initialize the list of emoji shortcodes that can be used for reactions (in "emoji.go")
+	emojisInit()
+
 	// Start the Discord bot (in "discord.go")
 	discordInit()
 
@@ -188,6 +191,80 @@ func main() {
 	// Initialize chat commands (in "chatCommand.go")
 	chatCommandInit()
 
+	// Initialize the aliases for chat commands (in "chat_alias.go")
+	// (this must be after the chat commands are initialized)
+	chatCommandAliasesInit()
+
+	// Initialize the logging of chat commands (in "chat_command_log.go")
+	chatCommandLogInit()
+
+	// Initialize chat rate-limiting (in "chat_rate_limit.go")
+	chatRateLimitInit()
+
+	// Initialize the automatic muting of spammers (in "chat_auto_mute.go")
+	chatAutoMuteInit()
+
+	// Initialize the maximum length of chat messages (in "command_chat.go")
+	chatMaxLengthInit()
+
+	// Initialize the chat history limits (in "chat.go")
+	chatLimitInit()
+
+	// Initialize duplicate chat message detection (in "chat_flood.go")
+	chatFloodInit()
+
+	// Initialize the chat command cooldowns (in "chat_cooldown.go")
+	chatCommandCooldownsInit()
+
+	// Initialize the maximum number of mentions per message (in "chat_mention_limit.go")
+	chatMaxMentionsInit()
+
+	// Initialize the queue of private messages for offline users (in "chat_pm_queue.go")
+	chatPMQueueInit()
+
+	// Initialize the profanity filter, if enabled (in "chat_profanity.go")
+	profanityFilterInit()
+
+	// Initialize the banned words, if any (in "chat_banned_words.go")
+	chatBannedWordsInit()
+
+	// Initialize the chat macros (in "chat_macros.go")
+	// (this must be after the chat commands are initialized)
+	chatMacrosInit()
+
+	// Initialize link previews, if enabled (in "chat_preview.go")
+	chatPreviewInit()
+
+	// Initialize inline images, if enabled (in "chat_image.go")
+	chatImageInit()
+
+	// Initialize the countdown before an automatic start (in "chat_pregame.go")
+	startInCountdownInit()
+
+	// Initialize the game events that can be echoed to the table chat (in "chat_game_events.go")
+	chatGameEventsInit()
+
+	// Initialize the notices for spectators coming and going (in "chat_spectator_notices.go")
+	chatSpectatorNoticesInit()
+
+	// Initialize the purging of old chat messages (in "chat_retention.go")
+	chatRetentionInit()
+
+	// Initialize the rotating server messages in the lobby (in "chat_motd.go")
+	motdInit()
+
+	// Initialize the recurring reminders in the lobby, if any (in "chat_reminders.go")
+	chatRemindersInit()
+
+	// Initialize the forwarding of feedback to Discord, if enabled (in "chat_feedback.go")
+	chatFeedbackInit()
+
+	// Initialize the translation of chat messages, if enabled (in "chat_translate.go")
+	chatTranslateInit()
+
+	// Initialize the protected names that cannot be impersonated (in "chat_lookalike.go")
+	chatLookalikeInit()
+
 	// Calculate variant efficiencies
 	variantslogic.Init(jsonPath)
 
@@ -26,7 +26,7 @@ func maintenance(ctx context.Context, enabled bool) {
 		msg += "disabled."
 	}
 
-	// We must acquires the tables lock before entering the "chatServerSendAll()" function
+	// We must acquire the tables lock before entering the "chatServerSendAll()" function
 	tables.Lock(ctx)
 	defer tables.Unlock(ctx)
 
@@ -9,6 +9,7 @@ import (
 	"math"
 	"math/rand"
 	"net/url"
+	"os"
 	"os/exec"
 	"path"
 	"regexp"
@@ -61,6 +62,23 @@ func getCurrentTimestamp() string {
 	return formatTimestampUnix(time.Now())
 }
 
+// getEnvInt reads an integer from an environment variable
+// (they were loaded from the ".env" file in "main.go")
+// If the environment variable is blank, the default value is returned instead
+func getEnvInt(name string, defaultValue int) int {
+	valueString := os.Getenv(name)
+	if len(valueString) == 0 {
+		return defaultValue
+	}
+
+	if v, err := strconv.Atoi(valueString); err != nil {
+		logger.Fatal("Failed to convert the \"" + name + "\" environment variable to a number.")
+		return defaultValue
+	} else {
+		return v
+	}
+}
+
 // From: http://golangcookbook.blogspot.com/2012/11/generate-random-number-in-given-range.html
 func getRandom(min int, max int) int {
 	max++
@@ -312,6 +330,19 @@ func toSnakeCase(str string) string {
 	return strings.ToLower(snake)
 }
 
+// truncateRunes truncates a string to a maximum amount of runes (instead of bytes),
+// so that multibyte characters are not cut in half
+func truncateRunes(s string, maxRunes int) string {
+	numRunes := 0
+	for i := range s {
+		if numRunes == maxRunes {
+			return s[:i]
+		}
+		numRunes++
+	}
+	return s
+}
+
 func truncateTrimCheckEmpty(name string) string {
 	// Truncate long table names
 	// (we do this first to prevent wasting CPU cycles on validating extremely long table names)
@@ -21,6 +21,9 @@ type Models struct {
 	BannedIPs
 	ChatLog
 	ChatLogPM
+	ChatLogReactions
+	ChatReports
+	ChatFeedback
 	DiscordWaiters
 	GameActions
 	GameParticipantNotes
@@ -33,6 +36,7 @@ type Models struct {
 	Users
 	UserFriends
 	UserReverseFriends
+	UserIgnores
 	UserSettings
 	UserStats
 	VariantStats
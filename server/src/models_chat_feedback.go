@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type ChatFeedback struct{}
+
+// FeedbackRow is an entry from the "/feedback" command
+type FeedbackRow struct {
+	Username string
+	Room     string
+	Message  string
+	Datetime time.Time
+}
+
+func (*ChatFeedback) Insert(userID int, room string, message string) error {
+	_, err := db.Exec(context.Background(), `
+		INSERT INTO chat_feedback (user_id, room, message)
+		VALUES ($1, $2, $3)
+	`, userID, room, message)
+	return err
+}
+
+// GetRecent gets the last "count" entries, from newest to oldest
+func (*ChatFeedback) GetRecent(count int) ([]FeedbackRow, error) {
+	feedback := make([]FeedbackRow, 0)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			users.username,
+			chat_feedback.room,
+			chat_feedback.message,
+			chat_feedback.datetime_sent
+		FROM chat_feedback
+			JOIN users ON chat_feedback.user_id = users.id
+		ORDER BY chat_feedback.datetime_sent DESC
+		LIMIT $1
+	`, count); err != nil {
+		return feedback, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var row FeedbackRow
+		if err := rows.Scan(
+			&row.Username,
+			&row.Room,
+			&row.Message,
+			&row.Datetime,
+		); err != nil {
+			return feedback, err
+		}
+		feedback = append(feedback, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return feedback, err
+	}
+	rows.Close()
+
+	return feedback, nil
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v4"
@@ -13,28 +15,50 @@ type ChatLog struct{}
 
 // ChatLogRow mirrors the "chat_log" table row
 type ChatLogRow struct {
-	UserID  int
-	Message string
-	Room    string
+	MessageID string
+	UserID    int
+	Message   string
+	Room      string
+	ReplyTo   string
 }
 
-func (*ChatLog) Insert(userID int, message string, room string) error {
+// Insert adds a new message to the chat log
+// "replyTo" is the ID of the message that this is a reply to (or blank if it is not a reply)
+func (*ChatLog) Insert(
+	messageID string,
+	userID int,
+	message string,
+	room string,
+	replyTo string,
+) error {
 	_, err := db.Exec(context.Background(), `
-		INSERT INTO chat_log (user_id, message, room)
-		VALUES ($1, $2, $3)
-	`, userID, message, room)
+		INSERT INTO chat_log (message_id, user_id, message, room, reply_to)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+	`, messageID, userID, message, room, replyTo)
 	return err
 }
 
+// BulkInsert inserts the rows, skipping any messages that are already in the database
 func (*ChatLog) BulkInsert(chatLogRows []*ChatLogRow) error {
 	SQLString := `
-		INSERT INTO chat_log (user_id, message, room)
+		INSERT INTO chat_log (message_id, user_id, message, room, reply_to)
 		VALUES %s
+		ON CONFLICT (message_id) DO NOTHING
 	`
-	numArgsPerRow := 3
+	numArgsPerRow := 5
 	valueArgs := make([]interface{}, 0, numArgsPerRow*len(chatLogRows))
 	for _, chatLogRow := range chatLogRows {
-		valueArgs = append(valueArgs, chatLogRow.UserID, chatLogRow.Message, chatLogRow.Room)
+		valueArgs = append(
+			valueArgs,
+			chatLogRow.MessageID,
+			chatLogRow.UserID,
+			chatLogRow.Message,
+			chatLogRow.Room,
+			sql.NullString{
+				String: chatLogRow.ReplyTo,
+				Valid:  chatLogRow.ReplyTo != "",
+			},
+		)
 	}
 	SQLString = getBulkInsertSQLSimple(SQLString, numArgsPerRow, len(chatLogRows))
 
@@ -42,46 +66,261 @@ func (*ChatLog) BulkInsert(chatLogRows []*ChatLogRow) error {
 	return err
 }
 
-func (*ChatLog) InsertDiscord(discordName string, message string, room string) error {
+func (*ChatLog) InsertDiscord(
+	messageID string,
+	discordName string,
+	message string,
+	room string,
+) error {
 	_, err := db.Exec(context.Background(), `
-		INSERT INTO chat_log (user_id, discord_name, message, room)
-		VALUES (0, $1, $2, $3)
-	`, discordName, message, room)
+		INSERT INTO chat_log (message_id, user_id, discord_name, message, room)
+		VALUES ($1, 0, $2, $3, $4)
+	`, messageID, discordName, message, room)
 	return err
 }
 
+// GetLast gets the ID and the send time of the most recent message that a user sent in a room
+func (*ChatLog) GetLast(userID int, room string) (bool, string, time.Time, error) {
+	var messageID string
+	var datetimeSent time.Time
+	if err := db.QueryRow(context.Background(), `
+		SELECT COALESCE(message_id, id::TEXT), datetime_sent
+		FROM chat_log
+		WHERE user_id = $1
+			AND room = $2
+		ORDER BY datetime_sent DESC
+		LIMIT 1
+	`, userID, room).Scan(&messageID, &datetimeSent); errors.Is(err, pgx.ErrNoRows) {
+		return false, messageID, datetimeSent, nil
+	} else if err != nil {
+		return false, messageID, datetimeSent, err
+	}
+
+	return true, messageID, datetimeSent, nil
+}
+
+func (*ChatLog) Exists(messageID string, room string) (bool, error) {
+	var count int
+	if err := db.QueryRow(context.Background(), `
+		SELECT COUNT(id)
+		FROM chat_log
+		WHERE COALESCE(message_id, id::TEXT) = $1
+			AND room = $2
+	`, messageID, room).Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (*ChatLog) UpdateMessage(messageID string, message string) error {
+	_, err := db.Exec(context.Background(), `
+		UPDATE chat_log
+		SET message = $1
+		WHERE COALESCE(message_id, id::TEXT) = $2
+	`, message, messageID)
+	return err
+}
+
+// Delete removes a message from the chat log and returns the text of the deleted message
+func (*ChatLog) Delete(messageID string, room string) (bool, string, error) {
+	var message string
+	if err := db.QueryRow(context.Background(), `
+		DELETE FROM chat_log
+		WHERE COALESCE(message_id, id::TEXT) = $1
+			AND room = $2
+		RETURNING message
+	`, messageID, room).Scan(&message); errors.Is(err, pgx.ErrNoRows) {
+		return false, message, nil
+	} else if err != nil {
+		return false, message, err
+	}
+
+	return true, message, nil
+}
+
+// GetMessage gets the author and the text of a message so that it can be reported to the moderators
+// (the user ID is 0 for messages from the server and from Discord)
+func (*ChatLog) GetMessage(messageID string, room string) (bool, int, string, string, error) {
+	var userID int
+	var name string
+	var message string
+	if err := db.QueryRow(context.Background(), `
+		SELECT
+			chat_log.user_id,
+			COALESCE(chat_log.discord_name, users.username, '__server'),
+			chat_log.message
+		FROM
+			chat_log
+		LEFT JOIN
+			users ON users.id = chat_log.user_id
+		WHERE
+			COALESCE(chat_log.message_id, chat_log.id::TEXT) = $1
+			AND chat_log.room = $2
+	`, messageID, room).Scan(&userID, &name, &message); errors.Is(err, pgx.ErrNoRows) {
+		return false, userID, name, message, nil
+	} else if err != nil {
+		return false, userID, name, message, err
+	}
+
+	return true, userID, name, message, nil
+}
+
+// GetQuote gets the author and the text of a message so that it can be quoted in a reply
+func (*ChatLog) GetQuote(messageID string, room string) (bool, *ChatQuote, error) {
+	var name string
+	var message string
+	if err := db.QueryRow(context.Background(), `
+		SELECT
+			COALESCE(chat_log.discord_name, users.username, '__server'),
+			chat_log.message
+		FROM
+			chat_log
+		LEFT JOIN
+			users ON users.id = chat_log.user_id
+		WHERE
+			COALESCE(chat_log.message_id, chat_log.id::TEXT) = $1
+			AND chat_log.room = $2
+	`, messageID, room).Scan(&name, &message); errors.Is(err, pgx.ErrNoRows) {
+		return false, nil, nil
+	} else if err != nil {
+		return false, nil, err
+	}
+
+	// Messages are stored unfilled, so they are filled in before they are quoted
+	return true, newChatQuote(name, chatFillAll(message)), nil
+}
+
 type DBChatMessage struct {
+	MessageID   string         `json:"messageID"`
+	UserID      int            `json:"userID"`
 	Name        string         `json:"name"`
+	Moderator   bool           `json:"moderator"`
 	DiscordName sql.NullString `json:"discordName"`
 	Message     string         `json:"message"`
 	Datetime    time.Time      `json:"datetime"`
+	ReplyTo     sql.NullString `json:"replyTo"`
+	// The author and the text of the message being replied to
+	// (the text will not be valid if the message is not a reply or if the original was deleted)
+	QuoteName    sql.NullString `json:"quoteName"`
+	QuoteMessage sql.NullString `json:"quoteMessage"`
 }
 
-// Get the past messages sent in the lobby
-func (*ChatLog) Get(room string, count int) ([]DBChatMessage, error) {
+// Get the past messages sent in a room, from newest to oldest
+// If "after" or "before" are not the zero time, only messages sent in between are returned
+func (*ChatLog) Get(
+	room string,
+	count int,
+	after time.Time,
+	before time.Time,
+) ([]DBChatMessage, error) {
 	chatMessages := make([]DBChatMessage, 0)
 
 	SQLString := `
 		SELECT
+			COALESCE(chat_log.message_id, chat_log.id::TEXT),
+			chat_log.user_id,
 			COALESCE(users.username, '__server'),
+			COALESCE(users.moderator, FALSE),
 			chat_log.discord_name,
 			chat_log.message,
-			chat_log.datetime_sent
+			chat_log.datetime_sent,
+			chat_log.reply_to,
+			COALESCE(reply.discord_name, reply_users.username, '__server'),
+			reply.message
 		FROM
 			chat_log
 		LEFT JOIN
 			users ON users.id = chat_log.user_id
+		LEFT JOIN
+			chat_log AS reply ON COALESCE(reply.message_id, reply.id::TEXT) = chat_log.reply_to
+				AND reply.room = chat_log.room
+		LEFT JOIN
+			users AS reply_users ON reply_users.id = reply.user_id
 		WHERE
-			room = $1
-		ORDER BY
-			chat_log.datetime_sent DESC
+			chat_log.room = $1
 	`
+	args := []interface{}{room}
+	if !after.IsZero() {
+		args = append(args, after)
+		SQLString += "AND chat_log.datetime_sent > $" + strconv.Itoa(len(args)) + "\n"
+	}
+	if !before.IsZero() {
+		args = append(args, before)
+		SQLString += "AND chat_log.datetime_sent < $" + strconv.Itoa(len(args)) + "\n"
+	}
+	SQLString += "ORDER BY chat_log.datetime_sent DESC\n"
 	if count > 0 {
 		SQLString += "LIMIT " + strconv.Itoa(count)
 	}
 
 	var rows pgx.Rows
-	if v, err := db.Query(context.Background(), SQLString, room); err != nil {
+	if v, err := db.Query(context.Background(), SQLString, args...); err != nil {
+		return chatMessages, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var message DBChatMessage
+		if err := rows.Scan(
+			&message.MessageID,
+			&message.UserID,
+			&message.Name,
+			&message.Moderator,
+			&message.DiscordName,
+			&message.Message,
+			&message.Datetime,
+			&message.ReplyTo,
+			&message.QuoteName,
+			&message.QuoteMessage,
+		); err != nil {
+			return chatMessages, err
+		}
+		chatMessages = append(chatMessages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return chatMessages, err
+	}
+	rows.Close()
+
+	return chatMessages, nil
+}
+
+// GetSince gets all of the messages sent in a room since a particular time, from oldest to newest
+// (this is used to restore the chat for a table, since table IDs are reused after a restart)
+func (*ChatLog) GetSince(room string, datetime time.Time) ([]DBChatMessage, error) {
+	chatMessages := make([]DBChatMessage, 0)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			COALESCE(chat_log.message_id, chat_log.id::TEXT),
+			chat_log.user_id,
+			COALESCE(users.username, '__server'),
+			COALESCE(users.moderator, FALSE),
+			chat_log.discord_name,
+			chat_log.message,
+			chat_log.datetime_sent,
+			chat_log.reply_to,
+			COALESCE(reply.discord_name, reply_users.username, '__server'),
+			reply.message
+		FROM
+			chat_log
+		LEFT JOIN
+			users ON users.id = chat_log.user_id
+		LEFT JOIN
+			chat_log AS reply ON COALESCE(reply.message_id, reply.id::TEXT) = chat_log.reply_to
+				AND reply.room = chat_log.room
+		LEFT JOIN
+			users AS reply_users ON reply_users.id = reply.user_id
+		WHERE
+			chat_log.room = $1
+			AND chat_log.datetime_sent >= $2
+		ORDER BY
+			chat_log.datetime_sent ASC
+	`, room, datetime); err != nil {
 		return chatMessages, err
 	} else {
 		rows = v
@@ -90,10 +329,163 @@ func (*ChatLog) Get(room string, count int) ([]DBChatMessage, error) {
 	for rows.Next() {
 		var message DBChatMessage
 		if err := rows.Scan(
+			&message.MessageID,
+			&message.UserID,
 			&message.Name,
+			&message.Moderator,
 			&message.DiscordName,
 			&message.Message,
 			&message.Datetime,
+			&message.ReplyTo,
+			&message.QuoteName,
+			&message.QuoteMessage,
+		); err != nil {
+			return chatMessages, err
+		}
+		chatMessages = append(chatMessages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return chatMessages, err
+	}
+	rows.Close()
+
+	return chatMessages, nil
+}
+
+// UserChatMessage is a message from a specific user, which can be from any room
+type UserChatMessage struct {
+	Room     string
+	Message  string
+	Datetime time.Time
+}
+
+// GetFromUser gets the last "count" messages that a user sent in any room, from newest to oldest
+func (*ChatLog) GetFromUser(userID int, count int) ([]UserChatMessage, error) {
+	chatMessages := make([]UserChatMessage, 0)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			room,
+			message,
+			datetime_sent
+		FROM chat_log
+		WHERE user_id = $1
+		ORDER BY datetime_sent DESC
+		LIMIT $2
+	`, userID, count); err != nil {
+		return chatMessages, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var message UserChatMessage
+		if err := rows.Scan(
+			&message.Room,
+			&message.Message,
+			&message.Datetime,
+		); err != nil {
+			return chatMessages, err
+		}
+		chatMessages = append(chatMessages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return chatMessages, err
+	}
+	rows.Close()
+
+	return chatMessages, nil
+}
+
+// DeleteSince removes all of the messages sent in a room since a particular time
+// and returns the number of messages that were deleted
+func (*ChatLog) DeleteSince(room string, datetime time.Time) (int64, error) {
+	commandTag, err := db.Exec(context.Background(), `
+		DELETE FROM chat_log
+		WHERE room = $1
+			AND datetime_sent >= $2
+	`, room, datetime)
+	return commandTag.RowsAffected(), err
+}
+
+// DeleteBefore removes up to "limit" of the messages that were sent before a particular time
+// (either from the lobby or from the tables) and returns the number of messages that were deleted
+// The reactions to the deleted messages are deleted along with them,
+// but the reports are kept so that the moderators still have a record of them
+func (*ChatLog) DeleteBefore(lobby bool, datetime time.Time, limit int) (int64, error) {
+	roomCondition := "room = 'lobby'"
+	if !lobby {
+		// The review comments of replays are also table messages (in "chat_review.go")
+		roomCondition = "(room LIKE 'table%' OR room LIKE '" + ReviewRoomPrefix + "%')"
+	}
+
+	var numDeleted int64
+	err := db.QueryRow(context.Background(), `
+		WITH deleted AS (
+			DELETE FROM chat_log
+			WHERE id IN (
+				SELECT id
+				FROM chat_log
+				WHERE `+roomCondition+`
+					AND datetime_sent < $1
+				LIMIT $2
+			)
+			RETURNING COALESCE(message_id, id::TEXT) AS message_id
+		), deleted_reactions AS (
+			DELETE FROM chat_log_reactions
+			WHERE message_id IN (SELECT message_id FROM deleted)
+		)
+		SELECT COUNT(*) FROM deleted
+	`, datetime, limit).Scan(&numDeleted)
+	return numDeleted, err
+}
+
+type SearchChatMessage struct {
+	Name     string
+	Message  string
+	Datetime time.Time
+}
+
+// Search gets the last "count" messages in a room that contain all of the terms
+// (case-insensitive), from newest to oldest
+func (*ChatLog) Search(room string, terms []string, count int) ([]SearchChatMessage, error) {
+	chatMessages := make([]SearchChatMessage, 0)
+
+	// The wildcard characters in the terms must be escaped so that they are matched literally
+	likeEscaper := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	args := []interface{}{room, count}
+	conditions := ""
+	for _, term := range terms {
+		args = append(args, "%"+likeEscaper.Replace(term)+"%")
+		conditions += " AND chat_log.message ILIKE $" + strconv.Itoa(len(args))
+	}
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT
+			COALESCE(chat_log.discord_name, users.username, '__server'),
+			chat_log.message,
+			chat_log.datetime_sent
+		FROM chat_log
+		LEFT JOIN users ON users.id = chat_log.user_id
+		WHERE chat_log.room = $1`+conditions+`
+		ORDER BY chat_log.datetime_sent DESC
+		LIMIT $2
+	`, args...); err != nil {
+		return chatMessages, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var message SearchChatMessage
+		if err := rows.Scan(
+			&message.Name,
+			&message.Message,
+			&message.Datetime,
 		); err != nil {
 			return chatMessages, err
 		}
@@ -2,14 +2,117 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v4"
 )
 
 type ChatLogPM struct{}
 
-func (*ChatLogPM) Insert(userID int, message string, recipientID int) error {
+// Insert records a private message
+// Messages to offline users are not delivered yet; they will be sent when the recipient next logs
+// in (in "chat_pm_queue.go")
+func (*ChatLogPM) Insert(userID int, message string, recipientID int, delivered bool) error {
+	_, err := db.Exec(context.Background(), `
+		INSERT INTO chat_log_pm (user_id, recipient_id, message, delivered)
+		VALUES ($1, $2, $3, $4)
+	`, userID, recipientID, message, delivered)
+	return err
+}
+
+// InsertMention records a notification for a user who was mentioned while they were offline
+// (in "chat_mention_offline.go")
+func (*ChatLogPM) InsertMention(userID int, message string, recipientID int, room string) error {
 	_, err := db.Exec(context.Background(), `
-		INSERT INTO chat_log_pm (user_id, recipient_id, message)
-		VALUES ($1, $2, $3)
-	`, userID, recipientID, message)
+		INSERT INTO chat_log_pm (user_id, recipient_id, message, delivered, mention_room)
+		VALUES ($1, $2, $3, FALSE, $4)
+	`, userID, recipientID, message, room)
 	return err
 }
+
+// MentionQueued checks to see if a user is already going to be notified that someone mentioned
+// them in a particular room
+func (*ChatLogPM) MentionQueued(userID int, recipientID int, room string) (bool, error) {
+	var count int
+	err := db.QueryRow(context.Background(), `
+		SELECT COUNT(id)
+		FROM chat_log_pm
+		WHERE user_id = $1
+			AND recipient_id = $2
+			AND mention_room = $3
+			AND NOT delivered
+	`, userID, recipientID, room).Scan(&count)
+	return count > 0, err
+}
+
+// CountUndelivered counts the private messages that are waiting for a user and that were sent
+// after a particular time
+func (*ChatLogPM) CountUndelivered(recipientID int, since time.Time) (int, error) {
+	var count int
+	err := db.QueryRow(context.Background(), `
+		SELECT COUNT(id)
+		FROM chat_log_pm
+		WHERE recipient_id = $1
+			AND NOT delivered
+			AND datetime_sent >= $2
+	`, recipientID, since).Scan(&count)
+	return count, err
+}
+
+type QueuedPM struct {
+	UserID   int    // The sender
+	Username string // The sender
+	Message  string
+	Datetime time.Time
+	// Only valid if this is a notification for a mention (instead of a private message)
+	MentionRoom sql.NullString
+}
+
+// Deliver marks all of the private messages that are waiting for a user as delivered and returns
+// them, in no particular order
+// (this is done in a single query so that a message that is sent at the same time is not lost)
+func (*ChatLogPM) Deliver(recipientID int) ([]QueuedPM, error) {
+	pms := make([]QueuedPM, 0)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		UPDATE chat_log_pm
+		SET delivered = TRUE
+		FROM users
+		WHERE chat_log_pm.recipient_id = $1
+			AND NOT chat_log_pm.delivered
+			AND users.id = chat_log_pm.user_id
+		RETURNING
+			users.id,
+			users.username,
+			chat_log_pm.message,
+			chat_log_pm.datetime_sent,
+			chat_log_pm.mention_room
+	`, recipientID); err != nil {
+		return pms, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var pm QueuedPM
+		if err := rows.Scan(
+			&pm.UserID,
+			&pm.Username,
+			&pm.Message,
+			&pm.Datetime,
+			&pm.MentionRoom,
+		); err != nil {
+			return pms, err
+		}
+		pms = append(pms, pm)
+	}
+
+	if err := rows.Err(); err != nil {
+		return pms, err
+	}
+	rows.Close()
+
+	return pms, nil
+}
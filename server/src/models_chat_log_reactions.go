@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type ChatLogReactions struct{}
+
+func (*ChatLogReactions) Insert(messageID string, userID int, emoji string) error {
+	_, err := db.Exec(context.Background(), `
+		INSERT INTO chat_log_reactions (message_id, user_id, emoji)
+		VALUES ($1, $2, $3)
+	`, messageID, userID, emoji)
+	return err
+}
+
+func (*ChatLogReactions) Delete(messageID string, userID int, emoji string) error {
+	_, err := db.Exec(context.Background(), `
+		DELETE FROM chat_log_reactions
+		WHERE message_id = $1
+			AND user_id = $2
+			AND emoji = $3
+	`, messageID, userID, emoji)
+	return err
+}
+
+func (*ChatLogReactions) DeleteAll(messageID string) error {
+	_, err := db.Exec(context.Background(), `
+		DELETE FROM chat_log_reactions
+		WHERE message_id = $1
+	`, messageID)
+	return err
+}
+
+func (*ChatLogReactions) Exists(messageID string, userID int, emoji string) (bool, error) {
+	var count int
+	if err := db.QueryRow(context.Background(), `
+		SELECT COUNT(message_id)
+		FROM chat_log_reactions
+		WHERE message_id = $1
+			AND user_id = $2
+			AND emoji = $3
+	`, messageID, userID, emoji).Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// GetCounts gets the number of reactions for each emoji, indexed by message ID
+func (*ChatLogReactions) GetCounts(messageIDs []string) (map[string]map[string]int, error) {
+	reactionsMap := make(map[string]map[string]int)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT message_id, emoji, COUNT(user_id)
+		FROM chat_log_reactions
+		WHERE message_id = ANY($1)
+		GROUP BY message_id, emoji
+	`, messageIDs); err != nil {
+		return reactionsMap, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var messageID string
+		var emoji string
+		var count int
+		if err := rows.Scan(&messageID, &emoji, &count); err != nil {
+			return reactionsMap, err
+		}
+		if _, ok := reactionsMap[messageID]; !ok {
+			reactionsMap[messageID] = make(map[string]int)
+		}
+		reactionsMap[messageID][emoji] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return reactionsMap, err
+	}
+	rows.Close()
+
+	return reactionsMap, nil
+}
+
+// GetUsers gets the IDs of the users who reacted with each emoji, indexed by message ID
+// (this is needed to restore the chat of a table, since the same user cannot react twice)
+func (*ChatLogReactions) GetUsers(messageIDs []string) (map[string]map[string][]int, error) {
+	reactionsMap := make(map[string]map[string][]int)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT message_id, emoji, user_id
+		FROM chat_log_reactions
+		WHERE message_id = ANY($1)
+	`, messageIDs); err != nil {
+		return reactionsMap, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var messageID string
+		var emoji string
+		var userID int
+		if err := rows.Scan(&messageID, &emoji, &userID); err != nil {
+			return reactionsMap, err
+		}
+		if _, ok := reactionsMap[messageID]; !ok {
+			reactionsMap[messageID] = make(map[string][]int)
+		}
+		reactionsMap[messageID][emoji] = append(reactionsMap[messageID][emoji], userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return reactionsMap, err
+	}
+	rows.Close()
+
+	return reactionsMap, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+)
+
+type ChatReports struct{}
+
+func (*ChatReports) Insert(
+	reporterID int,
+	messageID string,
+	room string,
+	userID int,
+	name string,
+	message string,
+	reason string,
+) error {
+	_, err := db.Exec(context.Background(), `
+		INSERT INTO chat_reports (reporter_id, message_id, room, user_id, name, message, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+	`, reporterID, messageID, room, userID, name, message, reason)
+	return err
+}
+
+// Exists returns true if the user has already reported the message
+func (*ChatReports) Exists(reporterID int, messageID string) (bool, error) {
+	var count int
+	if err := db.QueryRow(context.Background(), `
+		SELECT COUNT(id)
+		FROM chat_reports
+		WHERE reporter_id = $1
+			AND message_id = $2
+	`, reporterID, messageID).Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"strconv"
@@ -25,6 +26,8 @@ type GameRow struct {
 	EndCondition     int
 	DatetimeStarted  time.Time
 	DatetimeFinished time.Time
+	TableID          uint64
+	DatetimeCreated  time.Time // The time that the table was created
 }
 
 type GamesRow struct {
@@ -68,7 +71,9 @@ func (*Games) Insert(gameRow GameRow) (int, error) {
 				num_turns,
 				end_condition,
 				datetime_started,
-				datetime_finished
+				datetime_finished,
+				table_id,
+				datetime_table_created
 			) VALUES (
 				$1,
 				$2,
@@ -89,7 +94,9 @@ func (*Games) Insert(gameRow GameRow) (int, error) {
 				$17,
 				$18,
 				$19,
-				$20
+				$20,
+				$21,
+				$22
 			)
 			RETURNING id
 		`,
@@ -115,6 +122,8 @@ func (*Games) Insert(gameRow GameRow) (int, error) {
 		gameRow.EndCondition,
 		gameRow.DatetimeStarted,
 		gameRow.DatetimeFinished,
+		gameRow.TableID,
+		gameRow.DatetimeCreated,
 	).Scan(&id); err != nil {
 		return -1, err
 	}
@@ -778,6 +787,28 @@ func (*Games) GetDatetimes(databaseID int) (time.Time, time.Time, error) {
 	return datetimeStarted, datetimeFinished, err
 }
 
+// GetChatRoom returns the room that the game was played in and the times of the first and last
+// messages of the game in that room
+// It returns false if the table of the game was not recorded (for games from before this existed)
+func (*Games) GetChatRoom(databaseID int) (bool, string, time.Time, time.Time, error) {
+	var tableID sql.NullInt64
+	var datetimeTableCreated sql.NullTime
+	var datetimeFinished time.Time
+	if err := db.QueryRow(context.Background(), `
+		SELECT table_id, datetime_table_created, datetime_finished
+		FROM games
+		WHERE games.id = $1
+	`, databaseID).Scan(&tableID, &datetimeTableCreated, &datetimeFinished); err != nil {
+		return false, "", time.Time{}, time.Time{}, err
+	}
+
+	if !tableID.Valid || !datetimeTableCreated.Valid {
+		return false, "", time.Time{}, time.Time{}, nil
+	}
+	room := "table" + strconv.FormatInt(tableID.Int64, 10)
+	return true, room, datetimeTableCreated.Time, datetimeFinished, nil
+}
+
 type DBPlayer struct {
 	ID                  int
 	Name                string
@@ -57,6 +57,39 @@ func (*UserFriends) GetAllUsernames(userID int) ([]string, error) {
 	return friends, nil
 }
 
+// GetAll gets the ID and username of each of this user's friends, sorted by username
+func (*UserFriends) GetAll(userID int) ([]User, error) {
+	friends := make([]User, 0)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT users.id, users.username
+		FROM user_friends
+			JOIN users ON user_friends.friend_id = users.id
+		WHERE user_friends.user_id = $1
+		ORDER BY LOWER(users.username)
+	`, userID); err != nil {
+		return friends, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var friend User
+		if err := rows.Scan(&friend.ID, &friend.Username); err != nil {
+			return friends, err
+		}
+		friends = append(friends, friend)
+	}
+
+	if err := rows.Err(); err != nil {
+		return friends, err
+	}
+	rows.Close()
+
+	return friends, nil
+}
+
 // GetMap composes a map that represents all of this user's friends
 // We use a map to represent the friends instead of a slice because it is faster to check for the
 // existence of a friend in a map than to interate through a slice
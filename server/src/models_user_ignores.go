@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type UserIgnores struct{}
+
+func (*UserIgnores) Insert(userID int, ignoredID int) error {
+	_, err := db.Exec(context.Background(), `
+		INSERT INTO user_ignores (user_id, ignored_id)
+		VALUES ($1, $2)
+	`, userID, ignoredID)
+	return err
+}
+
+func (*UserIgnores) Delete(userID int, ignoredID int) error {
+	_, err := db.Exec(context.Background(), `
+		DELETE FROM user_ignores
+		WHERE user_id = $1
+			AND ignored_id = $2
+	`, userID, ignoredID)
+	return err
+}
+
+func (*UserIgnores) GetAllUsernames(userID int) ([]string, error) {
+	ignored := make([]string, 0)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT users.username
+		FROM user_ignores
+			JOIN users ON user_ignores.ignored_id = users.id
+		WHERE user_ignores.user_id = $1
+	`, userID); err != nil {
+		return ignored, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return ignored, err
+		}
+		ignored = append(ignored, username)
+	}
+	ignored = sortStringsCaseInsensitive(ignored)
+
+	if err := rows.Err(); err != nil {
+		return ignored, err
+	}
+	rows.Close()
+
+	return ignored, nil
+}
+
+// GetMap composes a map that represents all of the users that this user is ignoring
+func (*UserIgnores) GetMap(userID int) (map[int]struct{}, error) {
+	ignoredMap := make(map[int]struct{})
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT ignored_id
+		FROM user_ignores
+		WHERE user_id = $1
+	`, userID); err != nil {
+		return ignoredMap, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var ignoredID int
+		if err := rows.Scan(&ignoredID); err != nil {
+			return ignoredMap, err
+		}
+		ignoredMap[ignoredID] = struct{}{}
+	}
+
+	if err := rows.Err(); err != nil {
+		return ignoredMap, err
+	}
+	rows.Close()
+
+	return ignoredMap, nil
+}
@@ -13,6 +13,8 @@ type Settings struct {
 	DesktopNotification              bool    `json:"desktopNotification"`
 	SoundMove                        bool    `json:"soundMove"`
 	SoundTimer                       bool    `json:"soundTimer"`
+	SoundMention                     bool    `json:"soundMention"`
+	SoundPM                          bool    `json:"soundPM"`
 	KeldonMode                       bool    `json:"keldonMode"`
 	ColorblindMode                   bool    `json:"colorblindMode"`
 	RealLifeMode                     bool    `json:"realLifeMode"`
@@ -23,6 +25,13 @@ type Settings struct {
 	SpeedrunPreplay                  bool    `json:"speedrunPreplay"`
 	SpeedrunMode                     bool    `json:"speedrunMode"`
 	HyphenatedConventions            bool    `json:"hyphenatedConventions"`
+	HideLastSeen                     bool    `json:"hideLastSeen"`
+	DisableReadReceipts              bool    `json:"disableReadReceipts"`
+	DisableOfflineMentions           bool    `json:"disableOfflineMentions"`
+	AppearOffline                    bool    `json:"appearOffline"`
+	HideStats                        bool    `json:"hideStats"`
+	ChatHighlightWords               string  `json:"chatHighlightWords"`
+	Timezone                         string  `json:"timezone"`
 	CreateTableVariant               string  `json:"createTableVariant"`
 	CreateTableTimed                 bool    `json:"createTableTimed"`
 	CreateTableTimeBaseMinutes       float64 `json:"createTableTimeBaseMinutes"`
@@ -44,6 +53,8 @@ var (
 	defaultSettings = Settings{ // nolint: exhaustivestruct
 		SoundMove:                     true,
 		SoundTimer:                    true,
+		SoundMention:                  true,
+		SoundPM:                       true,
 		Volume:                        50,
 		CreateTableVariant:            DefaultVariantName,
 		CreateTableTimeBaseMinutes:    2,
@@ -59,6 +70,8 @@ func (*UserSettings) Get(userID int) (Settings, error) {
 			desktop_notification,
 			sound_move,
 			sound_timer,
+			sound_mention,
+			sound_pm,
 			keldon_mode,
 			colorblind_mode,
 			real_life_mode,
@@ -69,6 +82,13 @@ func (*UserSettings) Get(userID int) (Settings, error) {
 			speedrun_preplay,
 			speedrun_mode,
 			hyphenated_conventions,
+			hide_last_seen,
+			disable_read_receipts,
+			disable_offline_mentions,
+			appear_offline,
+			hide_stats,
+			chat_highlight_words,
+			timezone,
 			create_table_variant,
 			create_table_timed,
 			create_table_time_base_minutes,
@@ -88,6 +108,8 @@ func (*UserSettings) Get(userID int) (Settings, error) {
 		&settings.DesktopNotification,
 		&settings.SoundMove,
 		&settings.SoundTimer,
+		&settings.SoundMention,
+		&settings.SoundPM,
 		&settings.KeldonMode,
 		&settings.ColorblindMode,
 		&settings.RealLifeMode,
@@ -98,6 +120,13 @@ func (*UserSettings) Get(userID int) (Settings, error) {
 		&settings.SpeedrunPreplay,
 		&settings.SpeedrunMode,
 		&settings.HyphenatedConventions,
+		&settings.HideLastSeen,
+		&settings.DisableReadReceipts,
+		&settings.DisableOfflineMentions,
+		&settings.AppearOffline,
+		&settings.HideStats,
+		&settings.ChatHighlightWords,
+		&settings.Timezone,
 		&settings.CreateTableVariant,
 		&settings.CreateTableTimed,
 		&settings.CreateTableTimeBaseMinutes,
@@ -168,3 +197,96 @@ func (*UserSettings) IsHyphenated(userID int) (bool, error) {
 
 	return hyphenated, nil
 }
+
+func (*UserSettings) IsLastSeenHidden(userID int) (bool, error) {
+	var hideLastSeen bool
+	if err := db.QueryRow(context.Background(), `
+		SELECT hide_last_seen
+		FROM user_settings
+		WHERE user_id = $1
+	`, userID).Scan(&hideLastSeen); errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return hideLastSeen, nil
+}
+
+func (*UserSettings) IsStatsHidden(userID int) (bool, error) {
+	var hideStats bool
+	if err := db.QueryRow(context.Background(), `
+		SELECT hide_stats
+		FROM user_settings
+		WHERE user_id = $1
+	`, userID).Scan(&hideStats); errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return hideStats, nil
+}
+
+func (*UserSettings) IsReadReceiptsDisabled(userID int) (bool, error) {
+	var disableReadReceipts bool
+	if err := db.QueryRow(context.Background(), `
+		SELECT disable_read_receipts
+		FROM user_settings
+		WHERE user_id = $1
+	`, userID).Scan(&disableReadReceipts); errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return disableReadReceipts, nil
+}
+
+func (*UserSettings) IsOfflineMentionsDisabled(userID int) (bool, error) {
+	var disableOfflineMentions bool
+	if err := db.QueryRow(context.Background(), `
+		SELECT disable_offline_mentions
+		FROM user_settings
+		WHERE user_id = $1
+	`, userID).Scan(&disableOfflineMentions); errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return disableOfflineMentions, nil
+}
+
+// GetAppearOffline gets the users (out of the provided users) who are hidden from the "/who"
+// command
+func (*UserSettings) GetAppearOffline(userIDs []int) (map[int]struct{}, error) {
+	appearOfflineMap := make(map[int]struct{})
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT user_id
+		FROM user_settings
+		WHERE user_id = ANY($1)
+			AND appear_offline
+	`, userIDs); err != nil {
+		return appearOfflineMap, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			return appearOfflineMap, err
+		}
+		appearOfflineMap[userID] = struct{}{}
+	}
+
+	if err := rows.Err(); err != nil {
+		return appearOfflineMap, err
+	}
+	rows.Close()
+
+	return appearOfflineMap, nil
+}
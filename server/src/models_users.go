@@ -120,6 +120,75 @@ func (*Users) GetDatetimeCreated(userID int) (time.Time, error) {
 	return datetimeCreated, err
 }
 
+// GetLastSeen returns the most recent of the time that the user last logged in and the time that
+// they last sent a chat message
+func (*Users) GetLastSeen(userID int) (time.Time, error) {
+	var lastSeen time.Time
+	err := db.QueryRow(context.Background(), `
+		SELECT GREATEST(
+			users.datetime_last_login,
+			(SELECT MAX(datetime_sent) FROM chat_log WHERE chat_log.user_id = users.id)
+		)
+		FROM users
+		WHERE id = $1
+	`, userID).Scan(&lastSeen)
+	return lastSeen, err
+}
+
+func (*Users) IsModerator(userID int) (bool, error) {
+	var moderator bool
+	err := db.QueryRow(context.Background(), `
+		SELECT moderator
+		FROM users
+		WHERE id = $1
+	`, userID).Scan(&moderator)
+	return moderator, err
+}
+
+// GetModeratorUsernames is used to protect the names of the moderators from impersonation
+// (in "chat_lookalike.go")
+func (*Users) GetModeratorUsernames() ([]string, error) {
+	usernames := make([]string, 0)
+
+	var rows pgx.Rows
+	if v, err := db.Query(context.Background(), `
+		SELECT username
+		FROM users
+		WHERE moderator
+	`); err != nil {
+		return usernames, err
+	} else {
+		rows = v
+	}
+
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return usernames, err
+		}
+		usernames = append(usernames, username)
+	}
+
+	if err := rows.Err(); err != nil {
+		return usernames, err
+	}
+	rows.Close()
+
+	return usernames, nil
+}
+
+// SetWelcomed returns true if the user had not been sent the welcome message yet
+// (this is done in a single query so that a user with two connections cannot be welcomed twice)
+func (*Users) SetWelcomed(userID int) (bool, error) {
+	commandTag, err := db.Exec(context.Background(), `
+		UPDATE users
+		SET welcomed = TRUE
+		WHERE id = $1
+			AND NOT welcomed
+	`, userID)
+	return commandTag.RowsAffected() == 1, err
+}
+
 func (*Users) NormalizedUsernameExists(normalizedUsername string) (bool, string, error) {
 	var similarUsername string
 	if err := db.QueryRow(context.Background(), `
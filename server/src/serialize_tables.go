@@ -117,9 +117,16 @@ func restoreTable(ctx context.Context, f os.FileInfo) bool {
 	}
 	t.Spectators = make([]*Spectator, 0)
 	t.KickedPlayers = make(map[int]struct{})
+	t.Invited = make(map[int]struct{})
+	t.ChatLastSent = make(map[int]time.Time)
+	t.MutedSpectators = make(map[int]struct{})
 	if t.ChatRead == nil {
 		t.ChatRead = make(map[int]int)
 	}
+	if t.SpectatorChat == nil {
+		t.SpectatorChat = make([]*TableChatMessage, 0)
+	}
+	t.ReconcileChatRead()
 	t.mutex = &deadlock.Mutex{}
 
 	// Restore the circular references that could not be represented in JSON
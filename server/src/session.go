@@ -21,7 +21,9 @@ type Session struct {
 	UserID    int
 	Username  string
 	Muted     bool // Users are forcefully disconnected upon being muted, so this is static
+	Moderator bool
 	FakeUser  bool
+	Language  string // Used for private messages from the server (in "chat_localization.go")
 
 	// Dynamic data fields
 	// (they are updated as the user performs activities, so we need to use a mutex)
@@ -34,11 +36,16 @@ type SessionData struct {
 	TableID            uint64
 	Friends            map[int]struct{}
 	ReverseFriends     map[int]struct{}
+	Ignored            map[int]struct{}
 	Hyphenated         bool
 	Inactive           bool
 	RateLimitAllowance float64
 	RateLimitLastCheck time.Time
 	Banned             bool
+	AFK                bool
+	AFKReason          string
+	ChatNotifications  ChatNotificationSettings
+	Timezone           *time.Location
 }
 
 var (
@@ -55,18 +62,25 @@ func NewSession() *Session {
 		UserID:    0,
 		Username:  "[unknown]",
 		Muted:     false,
+		Moderator: false,
 		FakeUser:  false,
+		Language:  DefaultLanguage,
 
 		Data: &SessionData{
 			Status:             StatusLobby, // By default, new users are in the lobby
 			TableID:            uint64(0),   // 0 is used as a null value
 			Friends:            make(map[int]struct{}),
 			ReverseFriends:     make(map[int]struct{}),
+			Ignored:            make(map[int]struct{}),
 			Hyphenated:         false,
 			Inactive:           false,
 			RateLimitAllowance: RateLimitRate,
 			RateLimitLastCheck: time.Now(),
 			Banned:             false,
+			AFK:                false,
+			AFKReason:          "",
+			ChatNotifications:  NewChatNotificationSettings(defaultSettings),
+			Timezone:           time.UTC,
 		},
 		DataMutex: &deadlock.RWMutex{},
 	}
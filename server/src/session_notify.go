@@ -78,6 +78,8 @@ type TableMessage struct {
 	Players           []string `json:"players"`
 	Spectators        []string `json:"spectators"`
 	MaxPlayers        int      `json:"maxPlayers"`
+	Unread            int      `json:"unread"`
+	ChatPreview       string   `json:"chatPreview"`
 }
 
 func makeTableMessage(s *Session, t *Table) *TableMessage {
@@ -93,6 +95,14 @@ func makeTableMessage(s *Session, t *Table) *TableMessage {
 		spectators = append(spectators, sp.Name)
 	}
 
+	// Only the players of an ongoing game can have the table chat in the background
+	unread := 0
+	chatPreview := ""
+	if playerIndex != -1 && !t.Replay {
+		unread = t.GetChatUnread(s.UserID)
+		chatPreview = t.GetChatPreview(s.UserID)
+	}
+
 	return &TableMessage{
 		ID:                t.ID,
 		Name:              t.Name,
@@ -110,6 +120,8 @@ func makeTableMessage(s *Session, t *Table) *TableMessage {
 		Players:           players,
 		Spectators:        spectators,
 		MaxPlayers:        t.MaxPlayers,
+		Unread:            unread,
+		ChatPreview:       chatPreview,
 	}
 }
 
@@ -133,6 +145,19 @@ func (s *Session) NotifyTableProgress(t *Table) {
 	})
 }
 
+func (s *Session) NotifyChatUnread(t *Table) {
+	type ChatUnreadMessage struct {
+		TableID     uint64 `json:"tableID"`
+		Unread      int    `json:"unread"`
+		ChatPreview string `json:"chatPreview"`
+	}
+	s.Emit("chatUnread", &ChatUnreadMessage{
+		TableID:     t.ID,
+		Unread:      t.GetChatUnread(s.UserID),
+		ChatPreview: t.GetChatPreview(s.UserID),
+	})
+}
+
 // NotifyTableGone will notify someone about a game that ended
 func (s *Session) NotifyTableGone(t *Table) {
 	type TableGoneMessage struct {
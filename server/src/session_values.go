@@ -75,6 +75,17 @@ func (s *Session) ReverseFriends() map[int]struct{} {
 	return s.Data.ReverseFriends
 }
 
+func (s *Session) Ignored() map[int]struct{} {
+	if s == nil {
+		logger.Error("The \"Ignored\" method was called for a nil session.")
+		return make(map[int]struct{})
+	}
+
+	s.DataMutex.RLock()
+	defer s.DataMutex.RUnlock()
+	return s.Data.Ignored
+}
+
 func (s *Session) Hyphenated() bool {
 	if s == nil {
 		logger.Error("The \"Hyphenated\" method was called for a nil session.")
@@ -97,6 +108,50 @@ func (s *Session) SetHyphenated(hyphenated bool) {
 	s.DataMutex.Unlock()
 }
 
+func (s *Session) ChatNotifications() ChatNotificationSettings {
+	if s == nil {
+		logger.Error("The \"ChatNotifications\" method was called for a nil session.")
+		return NewChatNotificationSettings(defaultSettings)
+	}
+
+	s.DataMutex.RLock()
+	defer s.DataMutex.RUnlock()
+	return s.Data.ChatNotifications
+}
+
+func (s *Session) SetChatNotifications(chatNotifications ChatNotificationSettings) {
+	if s == nil {
+		logger.Error("The \"SetChatNotifications\" method was called for a nil session.")
+		return
+	}
+
+	s.DataMutex.Lock()
+	s.Data.ChatNotifications = chatNotifications
+	s.DataMutex.Unlock()
+}
+
+func (s *Session) Timezone() *time.Location {
+	if s == nil {
+		logger.Error("The \"Timezone\" method was called for a nil session.")
+		return time.UTC
+	}
+
+	s.DataMutex.RLock()
+	defer s.DataMutex.RUnlock()
+	return s.Data.Timezone
+}
+
+func (s *Session) SetTimezone(timezone *time.Location) {
+	if s == nil {
+		logger.Error("The \"SetTimezone\" method was called for a nil session.")
+		return
+	}
+
+	s.DataMutex.Lock()
+	s.Data.Timezone = timezone
+	s.DataMutex.Unlock()
+}
+
 func (s *Session) Inactive() bool {
 	if s == nil {
 		logger.Error("The \"Inactive\" method was called for a nil session.")
@@ -173,3 +228,37 @@ func (s *Session) Banned() bool {
 	defer s.DataMutex.RUnlock()
 	return s.Data.Banned
 }
+
+func (s *Session) AFK() bool {
+	if s == nil {
+		logger.Error("The \"AFK\" method was called for a nil session.")
+		return false
+	}
+
+	s.DataMutex.RLock()
+	defer s.DataMutex.RUnlock()
+	return s.Data.AFK
+}
+
+func (s *Session) AFKReason() string {
+	if s == nil {
+		logger.Error("The \"AFKReason\" method was called for a nil session.")
+		return ""
+	}
+
+	s.DataMutex.RLock()
+	defer s.DataMutex.RUnlock()
+	return s.Data.AFKReason
+}
+
+func (s *Session) SetAFK(afk bool, reason string) {
+	if s == nil {
+		logger.Error("The \"SetAFK\" method was called for a nil session.")
+		return
+	}
+
+	s.DataMutex.Lock()
+	s.Data.AFK = afk
+	s.Data.AFKReason = reason
+	s.DataMutex.Unlock()
+}
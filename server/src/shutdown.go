@@ -199,10 +199,24 @@ func shutdownImmediate(ctx context.Context) {
 func cancel(ctx context.Context) {
 	shuttingDown.UnSet()
 	notifyAllShutdown()
+
+	// We must acquire the tables lock before entering the "chatServerSendAll()" function
+	tables.Lock(ctx)
+	defer tables.Unlock(ctx)
+
 	chatServerSendAll(ctx, "Server shutdown has been canceled.")
 }
 
 func checkImminentShutdown(s *Session) bool {
+	// New games cannot be started while the server is counting down to a shutdown
+	// (in "chat_shutdown_countdown.go")
+	if timeLeft, ok := chatServerShutdownCountdownTimeLeft(); ok && shuttingDown.IsNotSet() {
+		s.Warning("The server will begin shutting down " +
+			chatServerShutdownCountdownString(timeLeft) + ". " +
+			"You cannot start any new games for the time being.")
+		return true
+	}
+
 	if shuttingDown.IsNotSet() {
 		return false
 	}
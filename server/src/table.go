@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"html"
 	"strconv"
 	"sync/atomic"
 	"time"
@@ -24,6 +25,8 @@ type Table struct {
 	// We keep track of players who have been kicked from the game
 	// so that we can prevent them from rejoining
 	KickedPlayers map[int]struct{} `json:"-"`
+	// Users who were invited with the "/invite" command can join without the password
+	Invited map[int]struct{} `json:"-"`
 
 	// This is the user ID of the person who started the table
 	// or the current leader of the shared replay
@@ -43,6 +46,9 @@ type Table struct {
 	// This is updated any time a player interacts with the game / replay
 	// (used to determine when a game is idle)
 	DatetimeLastAction time.Time
+	// The last time that the chat was cleared with the "/clear" command
+	// (so that the cleared messages are not restored from the database)
+	DatetimeChatCleared time.Time
 
 	// All of the game state is contained within the "Game" object
 	Game *Game
@@ -53,18 +59,56 @@ type Table struct {
 
 	Chat     []*TableChatMessage // All of the in-game chat history
 	ChatRead map[int]int         // A map of which users have read which messages
-	Deleted  bool                `json:"-"` // Used to prevent race conditions
+	// The side channel that only the spectators can see while the game is in progress
+	// It is merged into the normal chat when the game ends (in "chat_spectators.go")
+	SpectatorChat []*TableChatMessage
+	// The ID of the message that is pinned to the top of the chat, if any
+	PinnedMessageID string
+	// The number of seconds that each user has to wait between chat messages (0 if disabled)
+	SlowMode int
+	// A blurb that is set by the table owner with the "/topic" command (e.g. the house rules)
+	// It is HTML-escaped, but the mentions and formatting are not filled in yet
+	Topic string
+	// The poll from the "/poll" command that is in progress, if any (in "chat_poll.go")
+	Poll *ChatPoll `json:"-"`
+	// Whether clues, strikes, and the end of the game are announced in the chat
+	ChatGameEvents bool
+	// Whether the players are told when spectators come and go (in "chat_spectator_notices.go")
+	ChatSpectatorNotices bool
+	// The changes that have not been announced yet (nil if there are none)
+	SpectatorNoticesQueue *ChatSpectatorNoticesQueue `json:"-"`
+	// The last time that each user sent a message while slow mode was enabled
+	// (indexed by user ID)
+	ChatLastSent map[int]time.Time `json:"-"`
+	// The spectators who are not allowed to chat at this table (indexed by user ID)
+	// Spectators are unmuted when they stop spectating
+	MutedSpectators map[int]struct{} `json:"-"`
+	// Used so that we only check the database for the chat history once after a restart
+	ChatRestored bool `json:"-"`
+	// Used so that we only check the database for the review comments of a replay once
+	// (in "chat_review.go")
+	ReviewChatRestored bool `json:"-"`
+	Deleted            bool `json:"-"` // Used to prevent race conditions
 
 	// Each table has its own mutex to ensure that only one action can occur at the same time
 	mutex *deadlock.Mutex
 }
 
 type TableChatMessage struct {
+	ID       string
 	UserID   int
 	Username string
 	Msg      string
 	Datetime time.Time
 	Server   bool
+	Discord  bool
+	// Indexed by emoji shortcode, the values are the IDs of the users who reacted with that emoji
+	Reactions map[string][]int
+	// The ID of the message that this is a reply to (blank if it is not a reply)
+	ReplyTo string
+	Role    string // See the "chatGetRole()" function
+	// Whether this is a review comment that was sent in a replay (in "chat_review.go")
+	Review bool
 }
 
 var (
@@ -84,6 +128,7 @@ func NewTable(name string, ownerID int) *Table {
 		MaxPlayers:    5,
 		Spectators:    make([]*Spectator, 0),
 		KickedPlayers: make(map[int]struct{}),
+		Invited:       make(map[int]struct{}),
 
 		OwnerID:        ownerID,
 		Visible:        true, // Tables are visible by default
@@ -97,15 +142,26 @@ func NewTable(name string, ownerID int) *Table {
 		DatetimeLastJoined:   time.Time{},
 		DatetimePlannedStart: time.Time{},
 		DatetimeLastAction:   time.Time{},
+		DatetimeChatCleared:  time.Time{},
 
 		Game: nil,
 
 		Options:      NewOptions(),
 		ExtraOptions: &ExtraOptions{},
 
-		Chat:     make([]*TableChatMessage, 0),
-		ChatRead: make(map[int]int),
-		Deleted:  false,
+		Chat:                  make([]*TableChatMessage, 0),
+		ChatRead:              make(map[int]int),
+		SpectatorChat:         make([]*TableChatMessage, 0),
+		PinnedMessageID:       "",
+		SlowMode:              0,
+		ChatGameEvents:        false,
+		ChatSpectatorNotices:  false,
+		SpectatorNoticesQueue: nil,
+		ChatLastSent:          make(map[int]time.Time),
+		MutedSpectators:       make(map[int]struct{}),
+		ChatRestored:          false,
+		ReviewChatRestored:    false,
+		Deleted:               false,
 
 		mutex: &deadlock.Mutex{},
 	}
@@ -251,6 +307,126 @@ func (t *Table) GetRoomName() string {
 	return "table" + strconv.FormatUint(t.ID, 10)
 }
 
+// GetReviewRoomName returns the room that the review comments of a replay are stored in
+// (in "chat_review.go")
+// Unlike the room of the table, it is based on the database ID of the game,
+// so that the comments are found again the next time that the replay is opened
+// It returns an empty string if the table is not a replay of a game from the database
+func (t *Table) GetReviewRoomName() string {
+	if !t.Replay || t.ExtraOptions.DatabaseID <= 0 {
+		return ""
+	}
+	return ReviewRoomPrefix + strconv.Itoa(t.ExtraOptions.DatabaseID)
+}
+
+// GetChatQuote returns a quote of the chat message with the given ID
+// (or nil if there is no such message at this table)
+func (t *Table) GetChatQuote(messageID string) *ChatQuote {
+	if messageID == "" {
+		return nil
+	}
+
+	// Replies are usually to recent messages, so search backwards
+	for i := len(t.Chat) - 1; i >= 0; i-- {
+		chatMsg := t.Chat[i]
+		if chatMsg.ID == messageID {
+			// Quote the message as it was shown (in the same way as the lobby)
+			return newChatQuote(chatMsg.Username, chatMsg.GetFilledMsg())
+		}
+	}
+
+	return nil
+}
+
+// GetChatPreview returns a plain-text snippet of the last chat message for the players who have
+// unread messages at this table, so that they can see it from the lobby table list
+// It returns an empty string if there is nothing unread
+func (t *Table) GetChatPreview(userID int) string {
+	if len(t.Chat) == 0 || t.GetChatUnread(userID) == 0 {
+		return ""
+	}
+
+	chatMsg := t.Chat[len(t.Chat)-1]
+	who := chatMsg.Username
+	if chatMsg.Server || who == "" {
+		who = WebsiteName
+	}
+	who = html.EscapeString(who)
+
+	msg, action := chatParseAction(chatMsg.GetFilledMsg())
+	msg = chatSnippet(msg, ChatTablePreviewLength)
+	if action {
+		return "* " + who + " " + msg
+	}
+	return who + ": " + msg
+}
+
+// GetChatSessions returns the sessions of everyone who receives the chat messages at this table
+// (except for the people who have ignored the sender)
+func (t *Table) GetChatSessions(sender *Session) []*Session {
+	chatSessions := make([]*Session, 0)
+
+	if !t.Replay {
+		for _, p := range t.Players {
+			if p.Present && !chatIsIgnored(p.Session, sender) {
+				chatSessions = append(chatSessions, p.Session)
+			}
+		}
+	}
+
+	for _, sp := range t.Spectators {
+		if !chatIsIgnored(sp.Session, sender) {
+			chatSessions = append(chatSessions, sp.Session)
+		}
+	}
+
+	return chatSessions
+}
+
+// GetChatUnread returns the number of chat messages at this table that the user has not read yet
+// (this is never negative, even if the chat has fewer messages than the user has read)
+func (t *Table) GetChatUnread(userID int) int {
+	unread := len(t.Chat) - t.ChatRead[userID]
+	if unread < 0 {
+		return 0
+	}
+	return unread
+}
+
+// GetChatUnreadSent is the same as "GetChatUnread()", but only the last "numSent" messages can be
+// unread, since the messages that were trimmed from the history will never be sent to the client
+// (the messages before them are marked as read so that the count stays in sync)
+func (t *Table) GetChatUnreadSent(userID int, numSent int) int {
+	unread := t.GetChatUnread(userID)
+	if unread > numSent {
+		unread = numSent
+		t.ChatRead[userID] = len(t.Chat) - numSent
+	}
+	return unread
+}
+
+// InitChatRead marks all of the existing chat messages as read for someone who has never been at
+// this table before (e.g. a new spectator), so that they do not start with a huge unread count
+// Someone who has been here before keeps their count
+func (t *Table) InitChatRead(userID int) {
+	if _, ok := t.ChatRead[userID]; !ok {
+		t.ChatRead[userID] = len(t.Chat)
+	}
+}
+
+// ReconcileChatRead makes sure that no one has read more messages than the chat has
+// This must be called whenever messages are removed from the chat without updating the
+// "ChatRead" map (e.g. when the chat is restored from the database with fewer messages)
+func (t *Table) ReconcileChatRead() {
+	for userID, numRead := range t.ChatRead {
+		if numRead > len(t.Chat) {
+			t.ChatRead[userID] = len(t.Chat)
+		} else if numRead < 0 {
+			t.ChatRead[userID] = 0
+		}
+	}
+}
+
 func (t *Table) GetPlayerIndexFromID(userID int) int {
 	for i, p := range t.Players {
 		if p.UserID == userID {
@@ -20,6 +20,166 @@ func (t *Table) NotifyChat(chatMessage *ChatMessage) {
 	}
 }
 
+// NotifyChatFrom is the same as "NotifyChat()",
+// but it will skip the players and spectators who have ignored the sender
+func (t *Table) NotifyChatFrom(sender *Session, chatMessage *ChatMessage) {
+	if !t.Replay {
+		for _, p := range t.Players {
+			if p.Present && !chatIsIgnored(p.Session, sender) {
+				p.Session.Emit("chat", chatMessage)
+			}
+		}
+	}
+
+	// Spectators can see the messages from the "/everyone" command, but they are not pinged
+	spectatorMessage := chatMessage
+	if chatMessage.HighlightAll {
+		chatMessageCopy := *chatMessage
+		chatMessageCopy.HighlightAll = false
+		spectatorMessage = &chatMessageCopy
+	}
+	for _, sp := range t.Spectators {
+		if !chatIsIgnored(sp.Session, sender) {
+			sp.Session.Emit("chat", spectatorMessage)
+		}
+	}
+}
+
+// NotifyChatUnread sends an updated unread count to the players who have this table in the
+// background (e.g. because they went back to the lobby or are spectating another table),
+// since they do not receive the chat messages themselves
+func (t *Table) NotifyChatUnread(sender *Session) {
+	if t.Replay {
+		return
+	}
+
+	for _, p := range t.Players {
+		if p.Present {
+			continue
+		}
+
+		// The session stored on the player might be orphaned,
+		// so we only notify players who are currently online
+		s, ok := sessions.Get(p.UserID)
+		if !ok || s == sender || chatIsIgnored(s, sender) {
+			continue
+		}
+		s.NotifyChatUnread(t)
+	}
+}
+
+func (t *Table) NotifyChatEdit(chatEditMessage *ChatEditMessage) {
+	if !t.Replay {
+		for _, p := range t.Players {
+			if p.Present {
+				p.Session.Emit("chatEdit", chatEditMessage)
+			}
+		}
+	}
+
+	for _, sp := range t.Spectators {
+		sp.Session.Emit("chatEdit", chatEditMessage)
+	}
+}
+
+func (t *Table) NotifyChatDelete(chatDeleteMessage *ChatDeleteMessage) {
+	if !t.Replay {
+		for _, p := range t.Players {
+			if p.Present {
+				p.Session.Emit("chatDelete", chatDeleteMessage)
+			}
+		}
+	}
+
+	for _, sp := range t.Spectators {
+		sp.Session.Emit("chatDelete", chatDeleteMessage)
+	}
+}
+
+func (t *Table) NotifyChatReactions(chatReactionsMessage *ChatReactionsMessage) {
+	if !t.Replay {
+		for _, p := range t.Players {
+			if p.Present {
+				p.Session.Emit("chatReactions", chatReactionsMessage)
+			}
+		}
+	}
+
+	for _, sp := range t.Spectators {
+		sp.Session.Emit("chatReactions", chatReactionsMessage)
+	}
+}
+
+func (t *Table) NotifyChatClear(chatClearMessage *ChatClearMessage) {
+	if !t.Replay {
+		for _, p := range t.Players {
+			if p.Present {
+				p.Session.Emit("chatClear", chatClearMessage)
+			}
+		}
+	}
+
+	for _, sp := range t.Spectators {
+		sp.Session.Emit("chatClear", chatClearMessage)
+	}
+}
+
+func (t *Table) NotifyChatPin(chatPinMessage *ChatPinMessage) {
+	if !t.Replay {
+		for _, p := range t.Players {
+			if p.Present {
+				p.Session.Emit("chatPin", chatPinMessage)
+			}
+		}
+	}
+
+	for _, sp := range t.Spectators {
+		sp.Session.Emit("chatPin", chatPinMessage)
+	}
+}
+
+func (t *Table) NotifyChatTopic(chatTopicMessage *ChatTopicMessage) {
+	if !t.Replay {
+		for _, p := range t.Players {
+			if p.Present {
+				p.Session.Emit("chatTopic", chatTopicMessage)
+			}
+		}
+	}
+
+	for _, sp := range t.Spectators {
+		sp.Session.Emit("chatTopic", chatTopicMessage)
+	}
+}
+
+func (t *Table) NotifyChatPoll(chatPollMessage *ChatPollMessage) {
+	if !t.Replay {
+		for _, p := range t.Players {
+			if p.Present {
+				p.Session.Emit("chatPoll", chatPollMessage)
+			}
+		}
+	}
+
+	for _, sp := range t.Spectators {
+		sp.Session.Emit("chatPoll", chatPollMessage)
+	}
+}
+
+func (t *Table) NotifyAFKStatus(afkStatusMessage *AFKStatusMessage) {
+	if !t.Replay {
+		for _, p := range t.Players {
+			if p.Present {
+				p.Session.Emit("afkStatus", afkStatusMessage)
+			}
+		}
+	}
+
+	for _, sp := range t.Spectators {
+		sp.Session.Emit("afkStatus", afkStatusMessage)
+	}
+}
+
 func (t *Table) NotifyChatTyping(name string, typing bool) {
 	if !t.Replay {
 		for _, p := range t.Players {
@@ -165,6 +325,9 @@ func (t *Table) NotifyGameAction() {
 	for _, sp := range t.Spectators {
 		sp.Session.NotifyGameAction(t, a)
 	}
+
+	// Echo the important actions to the chat, if enabled (in "chat_game_events.go")
+	chatGameEventSend(t, a)
 }
 
 // NotifyStatus appends a new "status" action and alerts everyone
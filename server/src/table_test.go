@@ -0,0 +1,215 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestInitChatRead(t *testing.T) {
+	tests := []struct {
+		name           string
+		numMessages    int
+		chatRead       map[int]int
+		userID         int
+		expectedUnread int
+	}{
+		{
+			name:           "fresh spectator at a table with existing chat",
+			numMessages:    50,
+			chatRead:       map[int]int{1: 50},
+			userID:         2,
+			expectedUnread: 0,
+		},
+		{
+			name:           "fresh spectator at a table without chat",
+			numMessages:    0,
+			chatRead:       map[int]int{},
+			userID:         2,
+			expectedUnread: 0,
+		},
+		{
+			name:           "returning spectator keeps their unread count",
+			numMessages:    50,
+			chatRead:       map[int]int{1: 50, 2: 40},
+			userID:         2,
+			expectedUnread: 10,
+		},
+		{
+			name:           "returning spectator who has not read anything",
+			numMessages:    50,
+			chatRead:       map[int]int{1: 50, 2: 0},
+			userID:         2,
+			expectedUnread: 50,
+		},
+	}
+
+	for _, test := range tests {
+		table := NewTable("test", 1)
+		for i := 0; i < test.numMessages; i++ {
+			table.Chat = append(table.Chat, &TableChatMessage{ // nolint: exhaustivestruct
+				ID:  newChatMessageID(),
+				Msg: "hello",
+			})
+		}
+		table.ChatRead = test.chatRead
+
+		table.InitChatRead(test.userID)
+		if unread := table.GetChatUnread(test.userID); unread != test.expectedUnread {
+			t.Errorf("%v: GetChatUnread() = %v, expected %v", test.name, unread,
+				test.expectedUnread)
+		}
+
+		// Other people are not affected
+		if unread := table.GetChatUnread(1); unread != 0 {
+			t.Errorf("%v: GetChatUnread() for the owner = %v, expected 0", test.name, unread)
+		}
+
+		// New messages after they joined are unread
+		table.Chat = append(table.Chat, &TableChatMessage{ // nolint: exhaustivestruct
+			ID:  newChatMessageID(),
+			Msg: "new",
+		})
+		if unread := table.GetChatUnread(test.userID); unread != test.expectedUnread+1 {
+			t.Errorf("%v: GetChatUnread() after a new message = %v, expected %v", test.name,
+				unread, test.expectedUnread+1)
+		}
+	}
+}
+
+func TestGetChatUnreadSent(t *testing.T) {
+	tests := []struct {
+		name             string
+		numMessages      int
+		numRead          int
+		numSent          int
+		expectedUnread   int
+		expectedChatRead int
+	}{
+		{
+			name:             "unread messages that were all sent",
+			numMessages:      250,
+			numRead:          240,
+			numSent:          200,
+			expectedUnread:   10,
+			expectedChatRead: 240,
+		},
+		{
+			name:             "exactly as many unread messages as were sent",
+			numMessages:      250,
+			numRead:          50,
+			numSent:          200,
+			expectedUnread:   200,
+			expectedChatRead: 50,
+		},
+		{
+			name:             "one unread message was trimmed",
+			numMessages:      250,
+			numRead:          49,
+			numSent:          200,
+			expectedUnread:   200,
+			expectedChatRead: 50,
+		},
+		{
+			name:             "nothing was read",
+			numMessages:      250,
+			numRead:          0,
+			numSent:          200,
+			expectedUnread:   200,
+			expectedChatRead: 50,
+		},
+		{
+			name:             "more messages were read than the chat has",
+			numMessages:      10,
+			numRead:          30,
+			numSent:          10,
+			expectedUnread:   0,
+			expectedChatRead: 30,
+		},
+	}
+
+	for _, test := range tests {
+		table := NewTable("test", 1)
+		for i := 0; i < test.numMessages; i++ {
+			table.Chat = append(table.Chat, &TableChatMessage{ // nolint: exhaustivestruct
+				ID:  newChatMessageID(),
+				Msg: "hello",
+			})
+		}
+		table.ChatRead[2] = test.numRead
+
+		if unread := table.GetChatUnreadSent(2, test.numSent); unread != test.expectedUnread {
+			t.Errorf("%v: GetChatUnreadSent() = %v, expected %v", test.name, unread,
+				test.expectedUnread)
+		}
+		if numRead := table.ChatRead[2]; numRead != test.expectedChatRead {
+			t.Errorf("%v: ChatRead = %v, expected %v", test.name, numRead,
+				test.expectedChatRead)
+		}
+
+		// The count stays in sync for the next time that the history is sent
+		if unread := table.GetChatUnread(2); unread != test.expectedUnread {
+			t.Errorf("%v: GetChatUnread() afterwards = %v, expected %v", test.name, unread,
+				test.expectedUnread)
+		}
+	}
+}
+
+func TestReconcileChatRead(t *testing.T) {
+	tests := []struct {
+		name             string
+		numMessages      int
+		numRead          int
+		expectedChatRead int
+	}{
+		{
+			name:             "fewer messages were read",
+			numMessages:      20,
+			numRead:          15,
+			expectedChatRead: 15,
+		},
+		{
+			name:             "every message was read",
+			numMessages:      20,
+			numRead:          20,
+			expectedChatRead: 20,
+		},
+		{
+			name:             "more messages were read than the chat has",
+			numMessages:      20,
+			numRead:          21,
+			expectedChatRead: 20,
+		},
+		{
+			name:             "the chat is empty",
+			numMessages:      0,
+			numRead:          5,
+			expectedChatRead: 0,
+		},
+		{
+			name:             "a negative count",
+			numMessages:      20,
+			numRead:          -3,
+			expectedChatRead: 0,
+		},
+	}
+
+	for _, test := range tests {
+		table := NewTable("test", 1)
+		for i := 0; i < test.numMessages; i++ {
+			table.Chat = append(table.Chat, &TableChatMessage{ // nolint: exhaustivestruct
+				ID:  newChatMessageID(),
+				Msg: "hello",
+			})
+		}
+		table.ChatRead[2] = test.numRead
+
+		table.ReconcileChatRead()
+		if numRead := table.ChatRead[2]; numRead != test.expectedChatRead {
+			t.Errorf("%v: ChatRead = %v, expected %v", test.name, numRead,
+				test.expectedChatRead)
+		}
+		if unread := table.GetChatUnread(2); unread < 0 {
+			t.Errorf("%v: GetChatUnread() = %v, expected it to not be negative", test.name,
+				unread)
+		}
+	}
+}
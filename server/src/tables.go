@@ -342,4 +342,5 @@ func deleteTable(t *Table) {
 	tables.Delete(t.ID) // It is assumed that tables.mutex is locked at this point
 	t.Deleted = true    // It is assumed that t.Mutex is locked at this point
 	notifyAllTableGone(t)
+	chatMetrics.DeleteRoom(t.GetRoomName())
 }
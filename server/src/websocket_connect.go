@@ -17,8 +17,10 @@ import (
 type WebsocketConnectData struct {
 	// Data that will be attached to the session
 	Muted          bool
+	Moderator      bool
 	Friends        map[int]struct{}
 	ReverseFriends map[int]struct{}
+	Ignored        map[int]struct{}
 	Hyphenated     bool
 
 	// Other stats
@@ -42,12 +44,23 @@ func websocketConnect(ms *melody.Session) {
 
 	logger.Info("Entered the \"websocketConnect()\" function for user: " + username)
 
+	// Get the time of the last lobby chat message that the client saw, if any
+	lastSeen := time.Time{}
+	if v, exists := ms.Get("lastSeen"); exists {
+		lastSeen = v.(time.Time)
+	}
+	language := DefaultLanguage
+	if v, exists := ms.Get("language"); exists {
+		language = v.(string)
+	}
+
 	// Create the new session object
 	s := NewSession()
 	s.ms = ms
 	s.SessionID = sessionID
 	s.UserID = userID
 	s.Username = username
+	s.Language = language
 	// (we attach other data later)
 
 	ctx := NewSessionContext(s)
@@ -58,9 +71,16 @@ func websocketConnect(ms *melody.Session) {
 
 	// Attach the new data to the session object
 	s.Muted = data.Muted
+	s.Moderator = data.Moderator
 	s.Data.Friends = data.Friends
 	s.Data.ReverseFriends = data.ReverseFriends
+	s.Data.Ignored = data.Ignored
 	s.Data.Hyphenated = data.Hyphenated
+	s.Data.ChatNotifications = NewChatNotificationSettings(data.Settings)
+	s.Data.Timezone = chatGetTimezone(data.Settings.Timezone)
+	if s.Moderator {
+		chatLookalikeProtect(s.Username)
+	}
 
 	// We only want one computer to connect to one user at a time
 	// Use a dedicated mutex to prevent race conditions
@@ -95,7 +115,7 @@ func websocketConnect(ms *melody.Session) {
 	websocketConnectWelcomeMessage(s, data)
 	websocketConnectUserList(s)
 	websocketConnectTableList(ctx, s)
-	websocketConnectChat(s)
+	websocketConnectChat(s, lastSeen)
 	websocketConnectHistory(s)
 	if len(data.Friends) > 0 {
 		websocketConnectHistoryFriends(s)
@@ -111,6 +131,7 @@ func websocketConnectGetData(ctx context.Context, ms *melody.Session, userID int
 	data := &WebsocketConnectData{ // nolint: exhaustivestruct
 		Friends:        make(map[int]struct{}),
 		ReverseFriends: make(map[int]struct{}),
+		Ignored:        make(map[int]struct{}),
 	}
 
 	// -----------------------------------------
@@ -135,6 +156,15 @@ func websocketConnectGetData(ctx context.Context, ms *melody.Session, userID int
 		data.Muted = v
 	}
 
+	// Check to see if they are a moderator
+	if v, err := models.Users.IsModerator(userID); err != nil {
+		logger.Error("Failed to check to see if user \"" + username + "\" is a moderator: " +
+			err.Error())
+		return data
+	} else {
+		data.Moderator = v
+	}
+
 	// Get their friends
 	if v, err := models.UserFriends.GetMap(userID); err != nil {
 		logger.Error("Failed to get the friends map for user \"" + username + "\": " + err.Error())
@@ -152,6 +182,15 @@ func websocketConnectGetData(ctx context.Context, ms *melody.Session, userID int
 		data.ReverseFriends = v
 	}
 
+	// Get the users that they are ignoring
+	if v, err := models.UserIgnores.GetMap(userID); err != nil {
+		logger.Error("Failed to get the ignored users map for user \"" + username + "\": " +
+			err.Error())
+		return data
+	} else {
+		data.Ignored = v
+	}
+
 	// Get whether or not they are a member of the Hyphenated group
 	if v, err := models.UserSettings.IsHyphenated(userID); err != nil {
 		logger.Error("Failed to get the Hyphenated setting for user \"" + username + "\": " +
@@ -227,6 +266,9 @@ func websocketConnectWelcomeMessage(s *Session, data *WebsocketConnectData) {
 		FirstTimeUser bool     `json:"firstTimeUser"`
 		Settings      Settings `json:"settings"`
 		Friends       []string `json:"friends"`
+		ChatMacros    []string `json:"chatMacros"`
+		// Indexed by the alias (in "chat_alias.go")
+		ChatAliases map[string]string `json:"chatAliases"`
 
 		PlayingAtTables       []uint64 `json:"playingAtTables"`
 		DisconSpectatingTable uint64   `json:"disconSpectatingTable"`
@@ -257,6 +299,12 @@ func websocketConnectWelcomeMessage(s *Session, data *WebsocketConnectData) {
 		Settings: data.Settings,
 		Friends:  data.FriendsList,
 
+		// Some commands are text macros (e.g. "/shrug") that are defined by the server
+		ChatMacros: chatMacros.Names(),
+
+		// Some commands also have extra names that are configured by the server
+		ChatAliases: chatCommandAliases,
+
 		// Inform the user that they were previously playing or spectating a game
 		// (so that they can choose to rejoin it)
 		PlayingAtTables:       data.PlayingAtTables,
@@ -300,17 +348,21 @@ func websocketConnectTableList(ctx context.Context, s *Session) {
 	s.Emit("tableList", tableMessageList)
 }
 
-func websocketConnectChat(s *Session) {
-	// Send the past 50 chat messages from the lobby
-	if !chatSendPastFromDatabase(s, "lobby", 50) {
+func websocketConnectChat(s *Session, lastSeen time.Time) {
+	// Send the chat messages from the lobby that they have not seen yet
+	if !chatSendPastSince(s, "lobby", lastSeen) {
 		return
 	}
 
+	// Send them the private messages that they received while they were offline, if any
+	chatPMDeliverQueued(s)
+
 	// Send them a message about the Discord server
 	msg := "Find teammates and discuss strategy in the " +
 		"<a href=\"https://discord.gg/FADvkJp\" target=\"_blank\" rel=\"noopener noreferrer\">" +
 		"Discord chat</a>."
 	s.Emit("chat", &ChatMessage{
+		ID:        newChatMessageID(),
 		Msg:       msg,
 		Who:       "",
 		Discord:   false,
@@ -318,6 +370,10 @@ func websocketConnectChat(s *Session) {
 		Datetime:  time.Now(),
 		Room:      "lobby",
 		Recipient: "",
+		Reactions: make(map[string]int),
+		ReplyTo:   "",
+		Quote:     nil,
+		Action:    false,
 	})
 
 	// Send them the message of the day, if any
@@ -338,6 +394,7 @@ func websocketConnectChat(s *Session) {
 			if len(motd) > 0 {
 				msg := "[Server Notice] " + motd
 				s.Emit("chat", &ChatMessage{
+					ID:        newChatMessageID(),
 					Msg:       msg,
 					Who:       "",
 					Discord:   false,
@@ -345,10 +402,17 @@ func websocketConnectChat(s *Session) {
 					Datetime:  time.Now(),
 					Room:      "lobby",
 					Recipient: "",
+					Reactions: make(map[string]int),
+					ReplyTo:   "",
+					Quote:     nil,
+					Action:    false,
 				})
 			}
 		}
 	}
+
+	// Send them the welcome message if this is the first time that they have logged in
+	chatWelcomeSend(s)
 }
 
 // websocketConnectHistory sends the user's game history